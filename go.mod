@@ -13,8 +13,10 @@ require (
 	github.com/nickwells/testhelper.mod/v2 v2.6.1
 	github.com/nickwells/twrap.mod v1.5.14
 	github.com/nickwells/xdg.mod v1.0.12
+	github.com/pelletier/go-toml/v2 v2.3.1
 	golang.org/x/exp v0.0.0-20260410095643-746e56fc9e2f
 	golang.org/x/sys v0.43.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
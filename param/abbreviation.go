@@ -0,0 +1,89 @@
+package param
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// AbbreviationsAllowed returns true if parameter names given on the command
+// line can be abbreviated.
+func (ps *PSet) AbbreviationsAllowed() bool { return ps.abbreviationsAllowed }
+
+// SetAbbreviationsAllowed sets the flag allowing parameter names given on
+// the command line to be abbreviated. See also [SetAbbreviationsAllowed] (an
+// option function that can be passed to [NewSet]).
+//
+// Once this is set, if a parameter name given on the command line is not
+// found, any prefix which uniquely identifies a parameter will be taken as
+// that parameter. So, for instance, "-help-fo" would be taken as
+// "-help-format" provided that no other parameter name starts with
+// "help-fo". If more than one parameter could be meant then an error is
+// recorded listing the possible names. Where a parameter is set through an
+// abbreviation the full name is recorded in the location where it was set.
+//
+// Abbreviations are only allowed on the command line; parameters set in
+// configuration files or through environment variables must be given in
+// full.
+//
+// This must be called before the parameters are parsed; this will panic
+// otherwise.
+func (ps *PSet) SetAbbreviationsAllowed() {
+	ps.panicIfAlreadyParsed("can't allow abbreviated parameter names")
+
+	ps.abbreviationsAllowed = true
+}
+
+// SetAbbreviationsAllowed is a PSetOptFunc that sets the flag allowing
+// parameter names given on the command line to be abbreviated. See also the
+// [PSet.SetAbbreviationsAllowed] method.
+func SetAbbreviationsAllowed(ps *PSet) error {
+	ps.abbreviationsAllowed = true
+
+	return nil
+}
+
+// matchAbbreviation finds the parameter whose name starts with the given
+// abbreviation. The parameter's own name and any alternative or negated
+// names are considered, except for any deprecated names, and the parameters
+// of any parent PSet are also checked. It returns the matching name if only
+// one parameter matches (the parameter's own name is preferred if that
+// matches). If no parameter matches it returns the empty string and a nil
+// slice. If more than one parameter matches it returns the empty string and
+// the possible names. An empty abbreviation matches no parameter.
+func (ps *PSet) matchAbbreviation(abbrev string) (string, []string) {
+	if abbrev == "" {
+		return "", nil
+	}
+
+	matches := map[string]*ByName{}
+	params := map[*ByName]bool{}
+
+	for s := ps; s != nil; s = s.parent {
+		for name, p := range s.nameToParam {
+			if s.isDeprecatedName(name) {
+				continue
+			}
+
+			if strings.HasPrefix(name, abbrev) {
+				matches[name] = p
+				params[p] = true
+			}
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(matches))
+
+	switch len(params) {
+	case 0:
+		return "", nil
+	case 1:
+		if p := matches[names[0]]; matches[p.name] == p {
+			return p.name, nil
+		}
+
+		return names[0], nil
+	}
+
+	return "", names
+}
@@ -0,0 +1,111 @@
+package param_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestAbbreviation(t *testing.T) {
+	const cmdLineLoc = "[command line]: Supplied Parameter:"
+
+	testCases := []struct {
+		testhelper.ID
+		params      []string
+		noAbbrevs   bool
+		expErrs     map[string][]string
+		expFormat   string
+		expColour   bool
+		expWhereSet []string
+	}{
+		{
+			ID:          testhelper.MkID("full name"),
+			params:      []string{"-format", "json"},
+			expFormat:   "json",
+			expWhereSet: []string{cmdLineLoc + `2: "-format" "json"`},
+		},
+		{
+			ID:        testhelper.MkID("unique abbreviation"),
+			params:    []string{"-form", "json"},
+			expFormat: "json",
+			expWhereSet: []string{
+				cmdLineLoc + `2: "-form" "json"` +
+					` (an abbreviation of "format")`,
+			},
+		},
+		{
+			ID:        testhelper.MkID("unique abbreviation with value"),
+			params:    []string{"--fo=json"},
+			expFormat: "json",
+			expWhereSet: []string{
+				cmdLineLoc + `1: "--fo=json" (an abbreviation of "format")`,
+			},
+		},
+		{
+			ID:        testhelper.MkID("abbreviation of alt names"),
+			params:    []string{"-col"},
+			expColour: true,
+		},
+		{
+			ID:     testhelper.MkID("ambiguous abbreviation"),
+			params: []string{"-f", "json"},
+			expErrs: map[string][]string{
+				"f": {
+					`the abbreviation "f" is ambiguous,` +
+						` it could be any of: "format" or "full"`,
+				},
+				"json": {"does not start with"},
+			},
+		},
+		{
+			ID:     testhelper.MkID("empty name"),
+			params: []string{"-"},
+			expErrs: map[string][]string{
+				"": {"this is not a parameter of this program"},
+			},
+		},
+		{
+			ID:        testhelper.MkID("abbreviations not allowed"),
+			params:    []string{"-form", "json"},
+			noAbbrevs: true,
+			expErrs: map[string][]string{
+				"form": {"this is not a parameter of this program"},
+				"json": {"does not start with"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			format string
+			full   bool
+			colour bool
+		)
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetAbbreviationsAllowed)
+		if tc.noAbbrevs {
+			ps = paramset.NewNoHelpNoExitNoErrRpt()
+		}
+
+		p := ps.Add("format", psetter.String[string]{Value: &format},
+			"the output format")
+		ps.Add("full", psetter.Bool{Value: &full}, "show everything")
+		ps.Add("colour", psetter.Bool{Value: &colour}, "use colour",
+			param.AltNames("color"))
+
+		ps.Parse(tc.params)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+		testhelper.DiffString(t, tc.IDStr(), "format", format, tc.expFormat)
+		testhelper.DiffBool(t, tc.IDStr(), "colour", colour, tc.expColour)
+
+		if tc.expWhereSet != nil {
+			testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+				p.WhereSet(), tc.expWhereSet)
+		}
+	}
+}
@@ -44,9 +44,20 @@ import (
 type ByName struct {
 	BaseParam
 	altNames        []string
+	shortName       rune
+	negatable       bool
+	negatedNames    []string
 	groupName       string
 	whereIsParamSet []string
 	attributes      Attributes
+	envVars         []string
+
+	initialVal     valueSnapshot
+	cmdLineEntries []reloadEntry
+	candidate      *reloadCandidate
+
+	deprecated      *deprecation
+	deprecatedNames map[string]string
 }
 
 // AltNames returns a copy of the alternative names of the ByName parameter
@@ -58,6 +69,16 @@ func (p ByName) AltNames() []string {
 	return an
 }
 
+// EnvVars returns a copy of the names of the environment variables bound to
+// the ByName parameter. See the EnvVar function.
+func (p ByName) EnvVars() []string {
+	ev := make([]string, len(p.envVars))
+
+	copy(ev, p.envVars)
+
+	return ev
+}
+
 // HasBeenSet will return true if the parameter has been set.
 func (p ByName) HasBeenSet() bool {
 	return len(p.whereIsParamSet) > 0
@@ -110,6 +131,13 @@ const (
 	// subsequent processing by the application. Setting it will also set the
 	// CommandLineOnly attribute.
 	IsTerminalParam
+	// Reloadable means that the parameter may be given a new value while the
+	// program is running by re-reading the configuration files and the
+	// environment. See the PSet.Reload and PSet.Watch methods. The setter
+	// must be a struct with a Value pointer field (as all the setters in
+	// the psetter package are) so that new values can be tried on a copy
+	// of it and the CommandLineOnly attribute must not be set.
+	Reloadable
 )
 
 // AttrIsSet will return true if the supplied attribute is set on the
@@ -175,6 +203,31 @@ func (ps *PSet) Add(
 		}
 	}
 
+	if p.deprecated != nil {
+		p.attributes |= DontShowInStdUsage
+	}
+
+	if err := ps.addDeprecatedNames(p); err != nil {
+		panic(fmt.Errorf("%s: %w", panicPrefix, err))
+	}
+
+	if err := ps.addNegatedNames(p); err != nil {
+		panic(fmt.Errorf("%s: %w", panicPrefix, err))
+	}
+
+	if len(p.envVars) > 0 && p.AttrIsSet(CommandLineOnly) {
+		panic(fmt.Errorf(
+			"%s: it may only be set on the command line"+
+				" but it is bound to the environment variables: %q",
+			panicPrefix, p.envVars))
+	}
+
+	if p.AttrIsSet(Reloadable) {
+		if err := p.checkReloadable(); err != nil {
+			panic(fmt.Errorf("%s: %w", panicPrefix, err))
+		}
+	}
+
 	ps.addByNameToGroup(p)
 
 	return p
@@ -281,13 +334,27 @@ func GroupName(name string) ByNameOptFunc {
 }
 
 // processParam will call the parameter's setter processor and then record
-// any errors, record where it was set and call any associated post actions
+// any errors, record where it was set and call any associated post
+// actions. If the parameter is deprecated a warning is given and the
+// parameter may be passed on to its replacement.
 func (p *ByName) processParam(loc *location.L, paramParts []string) {
+	p.warnDeprecated(loc, paramParts[0])
+
+	if p.forwardDeprecated(loc, paramParts) {
+		return
+	}
+
+	if p.ps.reloading {
+		p.recordReloadEntry(loc, paramParts)
+		return
+	}
+
 	if p.AttrIsSet(SetOnlyOnce) && p.HasBeenSet() {
 		p.ps.AddErr(p.name,
-			loc.Error(fmt.Sprintf(
-				"This may only be set once but has already been set at %s",
-				p.whereIsParamSet[0])))
+			&SetOnlyOnceError{
+				ParamErrDetails: mkParamErrDetails(p.name, loc),
+				PrevLoc:         p.whereIsParamSet[0],
+			})
 
 		return
 	}
@@ -296,8 +363,42 @@ func (p *ByName) processParam(loc *location.L, paramParts []string) {
 		p.ps.terminalParamSeen = true
 	}
 
-	var err error
+	err := p.setFromParts(paramParts)
+	if err != nil {
+		p.ps.AddErr(p.name, p.setErr(loc, paramParts, err))
+		return
+	}
+
+	p.whereIsParamSet = append(p.whereIsParamSet, loc.String())
+
+	if p.AttrIsSet(Reloadable) &&
+		(loc.Note() == SrcCommandLine || loc.Note() == SrcSuppliedConfigFile) {
+		p.recordCmdLineEntry(loc, paramParts)
+	}
+
+	for _, action := range p.postAction {
+		err = action(*loc, (&p.BaseParam), paramParts)
+		if err != nil {
+			p.ps.AddErr(p.name,
+				&PostActionError{
+					ParamErrDetails: mkParamErrDetails(p.name, loc),
+					Err:             err,
+				})
+		}
+	}
+}
+
+// setFromParts calls the appropriate method of the parameter's setter
+// according to the parameter parts; the name and, possibly, the value. It
+// returns any error from the setter.
+func (p *ByName) setFromParts(paramParts []string) error {
+	return p.setWith(p.setter, paramParts)
+}
 
+// setWith calls the appropriate method of the given setter according to the
+// parameter parts, as for setFromParts. It returns any error from the
+// setter.
+func (p *ByName) setWith(s Setter, paramParts []string) error {
 	const (
 		nameOnly = 1
 		hasValue = 2
@@ -305,24 +406,21 @@ func (p *ByName) processParam(loc *location.L, paramParts []string) {
 
 	switch len(paramParts) {
 	case nameOnly:
-		err = p.setter.Set(paramParts[0])
+		if n, ok := s.(ptypes.Negator); ok &&
+			p.isNegatedName(paramParts[0]) {
+			return n.SetNegated(paramParts[0])
+		}
+
+		return s.Set(paramParts[0])
 	case hasValue:
-		err = p.setter.SetWithVal(paramParts[0], paramParts[1])
-	default:
-		err = fmt.Errorf("bad parameter: %q", paramParts)
-	}
+		if p.isNegatedName(paramParts[0]) {
+			return fmt.Errorf(
+				"a value must not follow the negated parameter: %q",
+				paramParts[0])
+		}
 
-	if err != nil {
-		p.ps.AddErr(p.name, loc.Error(err.Error()))
-		return
+		return s.SetWithVal(paramParts[0], paramParts[1])
 	}
 
-	p.whereIsParamSet = append(p.whereIsParamSet, loc.String())
-
-	for _, action := range p.postAction {
-		err = action(*loc, (&p.BaseParam), paramParts)
-		if err != nil {
-			p.ps.AddErr(p.name, loc.Error(err.Error()))
-		}
-	}
+	return fmt.Errorf("bad parameter: %q", paramParts)
 }
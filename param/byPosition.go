@@ -152,13 +152,22 @@ func (bp *ByPos) processParam(loc *location.L, val string) {
 		name := fmt.Sprintf("Positional parameter: %d (%s)",
 			loc.Idx(), bp.name)
 		bp.ps.AddErr(name,
-			loc.Errorf("%s", err.Error()))
+			&BadValueError{
+				ParamErrDetails: mkParamErrDetails(bp.name, loc),
+				Value:           val,
+				HasValue:        true,
+				Err:             err,
+			})
 	}
 
 	for _, action := range bp.postAction {
 		err = action(*loc, (&bp.BaseParam), []string{val})
 		if err != nil {
-			bp.ps.AddErr(bp.name, loc.Error(err.Error()))
+			bp.ps.AddErr(bp.name,
+				&PostActionError{
+					ParamErrDetails: mkParamErrDetails(bp.name, loc),
+					Err:             err,
+				})
 		}
 	}
 }
@@ -0,0 +1,236 @@
+package param
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// valueCandidates returns the values that the setter could take which start
+// with the prefix. If the setter is a ValueCompleter the values are those it
+// returns. Otherwise, if it is a Completer, the values are taken from its
+// CompletionHints and if it is neither they are any allowed values or
+// aliases.
+func valueCandidates(s Setter, prefix string) []string {
+	if vc, ok := s.(ptypes.ValueCompleter); ok {
+		return vc.CompleteValue(prefix)
+	}
+
+	if c, ok := s.(ptypes.Completer); ok {
+		return c.CompletionHints().Candidates(prefix)
+	}
+
+	var vals []string
+
+	if avm, ok := s.(ptypes.AllowedValuesMapper); ok {
+		keys, _ := avm.AllowedValuesMap().Keys()
+		vals = append(vals, keys...)
+	}
+
+	if aam, ok := s.(ptypes.AllowedValuesAliasMapper); ok {
+		keys, _ := aam.AllowedValuesAliasMap().Keys()
+		vals = append(vals, keys...)
+	}
+
+	return filterCandidates(vals, prefix)
+}
+
+// filterCandidates returns the sorted values which start with the prefix
+func filterCandidates(vals []string, prefix string) []string {
+	vals = slices.DeleteFunc(slices.Clone(vals),
+		func(v string) bool { return !strings.HasPrefix(v, prefix) })
+	slices.Sort(vals)
+
+	return vals
+}
+
+// matchingPrefix returns the longest of the parameter prefixes which the
+// word starts with and true or the empty string and false if it starts with
+// none of them.
+func (ps *PSet) matchingPrefix(word string) (string, bool) {
+	pfx, found := "", false
+
+	for _, p := range ps.paramPrefixes {
+		if strings.HasPrefix(word, p) && len(p) >= len(pfx) {
+			pfx, found = p, true
+		}
+	}
+
+	return pfx, found
+}
+
+// completionParam returns the named parameter that the word refers to and
+// true if the parameter takes a value. The word may have a trailing
+// value following an '='. The parameter may be in this PSet or in any
+// parent PSet and the word may be a bundle of short names. If the word does
+// not refer to a parameter a nil pointer is returned.
+func (ps *PSet) completionParam(word string) (*ByName, bool) {
+	if _, found := ps.matchingPrefix(word); !found {
+		return nil, false
+	}
+
+	if ps.isShortNameBundle(word) {
+		return ps.shortNameCompletionParam(word)
+	}
+
+	name, _, _ := strings.Cut(word, "=")
+	name = ps.TrimPrefixesFromParam(name)
+
+	p, ok := ps.findParam(name)
+	if !ok {
+		return nil, false
+	}
+
+	return p, p.setter.ValueReq() != None && !p.isNegatedName(name)
+}
+
+// shortNameCompletionParam returns the parameter that the bundle of short
+// names refers to and true if the parameter takes a value. As for
+// handleShortNames, the first short name that must have a value takes the
+// rest of the bundle as its value and so it is only expecting a value if
+// it is the last in the bundle. If any of the short names is not
+// recognised a nil pointer is returned.
+func (ps *PSet) shortNameCompletionParam(word string) (*ByName, bool) {
+	flags := []rune(strings.TrimPrefix(word, ShortNamePrefix))
+
+	var p *ByName
+
+	for j, r := range flags {
+		var ok bool
+
+		p, ok = ps.findShortName(r)
+		if !ok {
+			return nil, false
+		}
+
+		if p.setter.ValueReq() == Mandatory {
+			return p, j == len(flags)-1
+		}
+	}
+
+	return p, false
+}
+
+// nameCandidates returns the parameter names or sub-command names which
+// start with the word. The parameter names include those of any parent
+// PSet and any short names. If the word is a parameter name followed by an '='
+// then the candidates are the parameter name followed by each of the values
+// it could take.
+func (ps *PSet) nameCandidates(word string) []string {
+	var cands []string
+
+	if name, val, found := strings.Cut(word, "="); found {
+		p, takesVal := ps.completionParam(name)
+		if p == nil || !takesVal {
+			return nil
+		}
+
+		for _, v := range valueCandidates(p.setter, val) {
+			cands = append(cands, name+"="+v)
+		}
+
+		return cands
+	}
+
+	pfx, found := ps.matchingPrefix(word)
+	if !found {
+		for _, scName := range slices.Sorted(maps.Keys(ps.subCmds)) {
+			if strings.HasPrefix(scName, word) {
+				cands = append(cands, scName)
+			}
+		}
+
+		if word != "" {
+			return cands
+		}
+
+		pfx = ps.shortestPrefix
+	}
+
+	names := map[string]bool{}
+
+	for s := ps; s != nil; s = s.parent {
+		for name := range s.nameToParam {
+			if !s.isDeprecatedName(name) &&
+				strings.HasPrefix(pfx+name, word) {
+				names[pfx+name] = true
+			}
+		}
+
+		for r := range s.shortNameToParam {
+			name := ShortNamePrefix + string(r)
+			if strings.HasPrefix(name, word) {
+				names[name] = true
+			}
+		}
+	}
+
+	return append(cands, slices.Sorted(maps.Keys(names))...)
+}
+
+// CompletionCandidates returns the values which could be given in place of
+// the last of the words, which is taken to be a partially typed (possibly
+// empty) parameter. The words should be the parameters, excluding the
+// program name, as they would be given on the command line. The candidates
+// can be parameter names, sub-command names or the values of the positional
+// parameters or of the parameter preceding the last word. Values are found
+// from any allowed values of the parameter's Setter or, if the Setter is a
+// ptypes.ValueCompleter, by calling its CompleteValue method.
+//
+// This is intended to be used to provide command line completion and can be
+// called before or without parsing the parameters.
+func (ps *PSet) CompletionCandidates(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	word := words[len(words)-1]
+	prior := words[:len(words)-1]
+
+	if len(prior) < len(ps.byPos) {
+		return valueCandidates(ps.byPos[len(prior)].setter, word)
+	}
+
+	for _, bp := range ps.byPos {
+		if bp.isTerminal {
+			return nil
+		}
+	}
+
+	prior = prior[len(ps.byPos):]
+
+	for i := 0; i < len(prior); i++ {
+		w := prior[i]
+
+		if w == ps.terminalParam {
+			return nil
+		}
+
+		if sc, ok := ps.subCmds[w]; ok {
+			return sc.ps.CompletionCandidates(
+				slices.Concat(prior[i+1:], []string{word}))
+		}
+
+		p, takesVal := ps.completionParam(w)
+		if p == nil {
+			continue
+		}
+
+		if p.AttrIsSet(IsTerminalParam) {
+			return nil
+		}
+
+		if takesVal && !strings.Contains(w, "=") &&
+			p.setter.ValueReq() == Mandatory {
+			if i == len(prior)-1 {
+				return valueCandidates(p.setter, word)
+			}
+
+			i++
+		}
+	}
+
+	return ps.nameCandidates(word)
+}
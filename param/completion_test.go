@@ -0,0 +1,224 @@
+package param_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// clusterSetter is a Setter which offers runtime completions
+type clusterSetter struct {
+	psetter.String[string]
+}
+
+// CompleteValue returns the cluster names which start with the prefix
+func (s clusterSetter) CompleteValue(prefix string) []string {
+	var vals []string
+
+	for _, v := range []string{"prod-a", "prod-b", "test"} {
+		if strings.HasPrefix(v, prefix) {
+			vals = append(vals, v)
+		}
+	}
+
+	return vals
+}
+
+func TestCompletionCandidates(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		words     []string
+		expCands  []string
+		posParams bool
+	}{
+		{
+			ID: testhelper.MkID("no words"),
+			expCands: []string{
+				"build", "-cluster", "-colour", "-count", "-palette",
+			},
+		},
+		{
+			ID:       testhelper.MkID("partial name"),
+			words:    []string{"-co"},
+			expCands: []string{"-colour", "-count"},
+		},
+		{
+			ID:       testhelper.MkID("partial name, double dash"),
+			words:    []string{"--col"},
+			expCands: []string{"--colour"},
+		},
+		{
+			ID:       testhelper.MkID("partial sub-command"),
+			words:    []string{"bu"},
+			expCands: []string{"build"},
+		},
+		{
+			ID:       testhelper.MkID("allowed values"),
+			words:    []string{"-colour", "r"},
+			expCands: []string{"red"},
+		},
+		{
+			ID:       testhelper.MkID("allowed values after '='"),
+			words:    []string{"-colour="},
+			expCands: []string{"-colour=green", "-colour=red"},
+		},
+		{
+			ID:       testhelper.MkID("completer, list of values"),
+			words:    []string{"-palette", "red,g"},
+			expCands: []string{"red,green"},
+		},
+		{
+			ID:       testhelper.MkID("value completer"),
+			words:    []string{"-count", "3", "-cluster", "prod"},
+			expCands: []string{"prod-a", "prod-b"},
+		},
+		{
+			ID:    testhelper.MkID("sub-command params"),
+			words: []string{"-colour", "red", "build", "-"},
+			expCands: []string{
+				"-cluster", "-colour", "-count", "-fast", "-palette",
+			},
+		},
+		{
+			ID:       testhelper.MkID("sub-command, partial name"),
+			words:    []string{"build", "-f"},
+			expCands: []string{"-fast"},
+		},
+		{
+			ID:       testhelper.MkID("sub-command, parent param value"),
+			words:    []string{"build", "-colour", "g"},
+			expCands: []string{"green"},
+		},
+		{
+			ID:       testhelper.MkID("sub-command, parent param after '='"),
+			words:    []string{"build", "-fast", "-colour=r"},
+			expCands: []string{"-colour=red"},
+		},
+		{
+			ID:       testhelper.MkID("after terminal param"),
+			words:    []string{"--", "-"},
+			expCands: nil,
+		},
+		{
+			ID:        testhelper.MkID("positional param"),
+			words:     []string{"g"},
+			posParams: true,
+			expCands:  []string{"green"},
+		},
+		{
+			ID:        testhelper.MkID("after positional param"),
+			words:     []string{"green", "-cl"},
+			posParams: true,
+			expCands:  []string{"-cluster"},
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		var (
+			cluster, colour, posColour string
+			count                      int64
+			fast                       bool
+			palette                    []string
+		)
+
+		colourVals := ptypes.AllowedVals[string]{
+			"red":   "red",
+			"green": "green",
+		}
+
+		colour = "red"
+		posColour = "red"
+
+		if tc.posParams {
+			ps.AddByPos("pos-colour",
+				psetter.Enum[string]{
+					Value:       &posColour,
+					AllowedVals: colourVals,
+				},
+				"a positional colour")
+		} else {
+			ps.AddSubCommand("build", "build the thing", func(ps *param.PSet) {
+				ps.Add("fast", psetter.Bool{Value: &fast}, "go fast")
+			})
+		}
+
+		ps.Add("cluster",
+			clusterSetter{psetter.String[string]{Value: &cluster}},
+			"the cluster")
+		ps.Add("colour",
+			psetter.Enum[string]{Value: &colour, AllowedVals: colourVals},
+			"the colour")
+		ps.Add("count", psetter.Int[int64]{Value: &count}, "how many")
+		ps.Add("palette",
+			psetter.EnumList[string]{Value: &palette, AllowedVals: colourVals},
+			"the colours")
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "candidates",
+			ps.CompletionCandidates(tc.words), tc.expCands)
+	}
+}
+
+func TestCompletionShortNames(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		words    []string
+		expCands []string
+	}{
+		{
+			ID:       testhelper.MkID("short names offered"),
+			words:    []string{"-"},
+			expCands: []string{"-c", "-colour", "-v", "-verbose"},
+		},
+		{
+			ID:       testhelper.MkID("value after short name"),
+			words:    []string{"-c", "g"},
+			expCands: []string{"green"},
+		},
+		{
+			ID:       testhelper.MkID("value after bundle"),
+			words:    []string{"-vc", "r"},
+			expCands: []string{"red"},
+		},
+		{
+			ID:       testhelper.MkID("value in bundle"),
+			words:    []string{"-cred", "-v"},
+			expCands: []string{"-v", "-verbose"},
+		},
+		{
+			ID:       testhelper.MkID("short name after '='"),
+			words:    []string{"-c="},
+			expCands: []string{"-c=green", "-c=red"},
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetShortNamesAllowed)
+
+		var (
+			colour  = "red"
+			verbose bool
+		)
+
+		ps.Add("colour",
+			psetter.Enum[string]{
+				Value: &colour,
+				AllowedVals: ptypes.AllowedVals[string]{
+					"red":   "red",
+					"green": "green",
+				},
+			},
+			"the colour", param.ShortName('c'))
+		ps.Add("verbose", psetter.Bool{Value: &verbose}, "say more",
+			param.ShortName('v'))
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "candidates",
+			ps.CompletionCandidates(tc.words), tc.expCands)
+	}
+}
@@ -0,0 +1,174 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/fileparse.mod/fileparse"
+)
+
+// ConfigDirDetails records the details of a configuration directory.
+// Specifically its name, the pattern that files in the directory must match
+// and details about whether or not it must exist
+type ConfigDirDetails struct {
+	Name         string
+	Pattern      string
+	CfConstraint filecheck.Exists
+	eRule        existenceRule
+}
+
+// ParamsMustExist will return true if the existence rule for the files in
+// the directory is set to paramMustExist, that is, if the files should only
+// contain valid parameters
+func (cdd ConfigDirDetails) ParamsMustExist() bool {
+	return cdd.eRule == paramMustExist
+}
+
+// String returns a string describing the ConfigDirDetails
+func (cdd ConfigDirDetails) String() string {
+	s := filepath.Join(cdd.Name, cdd.Pattern)
+	if cdd.CfConstraint == filecheck.MustExist {
+		s += " (must exist)"
+	}
+
+	return s
+}
+
+// Files returns the names of the files in the directory which match the
+// pattern, in lexical order. Any sub-directories are ignored. A leading ~/
+// in the directory name is replaced by the user's home directory. An error
+// is returned if the directory name cannot be expanded or the directory
+// cannot be read.
+func (cdd ConfigDirDetails) Files() ([]string, error) {
+	dir, err := fileparse.FixFileName(cdd.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't expand: %q : %w", cdd.Name, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		if ok, _ := filepath.Match(cdd.Pattern, e.Name()); ok {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// checkConfigDir will panic if the existence constraint is MustNotExist or
+// the pattern is malformed
+func checkConfigDir(dir, pattern string, c filecheck.Exists) {
+	if c == filecheck.MustNotExist {
+		panic(fmt.Sprintf("config dir %q: bad existence constraint.", dir))
+	}
+
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		panic(fmt.Sprintf("config dir %q: bad pattern %q: %s",
+			dir, pattern, err))
+	}
+}
+
+// AddConfigDir adds a directory of config files (such as a conf.d
+// directory). Every file in the directory whose name matches the pattern is
+// read as a config file, in lexical order of the file names. The pattern
+// has the syntax used by filepath.Match; an empty pattern is taken to match
+// every file. The config directories are read after the config files, in
+// the order they are added.
+//
+// The format of each file is taken from its name (see
+// ConfigFileFormatFromName) so the directory can hold JSON, YAML or TOML
+// files as well as files in the line format. As with the files added with
+// AddConfigFile, parameters in the files which are not recognised are
+// ignored. The existence constraint applies to the directory; if it is
+// Optional and the directory doesn't exist it is ignored.
+//
+// The directory name may start with ~/ to refer to the home directory of
+// the user.
+//
+// The config directory must be added before the parameters are parsed; this
+// will panic otherwise. It will also panic if the pattern is malformed.
+func (ps *PSet) AddConfigDir(dir, pattern string, c filecheck.Exists) {
+	ps.addConfigDir(dir, pattern, c, paramNeedNotExist)
+}
+
+// AddConfigDirStrict behaves as for AddConfigDir except that parameters
+// given in the files must exist for the given program.
+//
+// The config directory must be added before the parameters are parsed; this
+// will panic otherwise. It will also panic if the pattern is malformed.
+func (ps *PSet) AddConfigDirStrict(dir, pattern string, c filecheck.Exists) {
+	ps.addConfigDir(dir, pattern, c, paramMustExist)
+}
+
+// addConfigDir adds the config directory with the given existence rule
+func (ps *PSet) addConfigDir(
+	dir, pattern string, c filecheck.Exists, eRule existenceRule,
+) {
+	ps.panicIfAlreadyParsed(
+		fmt.Sprintf("can't add the config dir %q", dir))
+
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	checkConfigDir(dir, pattern, c)
+
+	ps.configDirs = append(ps.configDirs,
+		ConfigDirDetails{
+			Name:         dir,
+			Pattern:      pattern,
+			CfConstraint: c,
+			eRule:        eRule,
+		})
+}
+
+// ConfigDirs returns a copy of the current config directory details.
+func (ps *PSet) ConfigDirs() []ConfigDirDetails {
+	return slices.Clone(ps.configDirs)
+}
+
+// getParamsFromConfigDirs will parse the files in each of the config
+// directories in turn
+func (ps *PSet) getParamsFromConfigDirs() {
+	for _, cdd := range ps.configDirs {
+		files, err := cdd.Files()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) &&
+				cdd.CfConstraint == filecheck.Optional {
+				continue
+			}
+
+			ps.AddErr(SrcConfigFilePfx+" dir: "+cdd.Name, err)
+
+			continue
+		}
+
+		cfs := make([]ConfigFileDetails, 0, len(files))
+		for _, f := range files {
+			cfs = append(cfs, ConfigFileDetails{
+				Name:         f,
+				CfConstraint: filecheck.MustExist,
+				Format:       ConfigFileFormatFromName(f),
+				eRule:        cdd.eRule,
+			})
+		}
+
+		ps.parseConfigFiles(cfs, SrcConfigFilePfx)
+	}
+}
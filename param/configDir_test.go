@@ -0,0 +1,202 @@
+package param_test
+
+import (
+	"errors"
+	"io/fs"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestConfigDir(t *testing.T) {
+	const (
+		cfgDir   = "testdata/conf.d"
+		noSuch   = "testdata/no-such-dir"
+		cfgLocA  = "[config file]: " + cfgDir + "/10-a.conf:1: name=first"
+		cfgLocB  = "[config file]: " + cfgDir + "/20-b.conf:1: name=second"
+		cfgLocC  = "[config file]: " + cfgDir + "/30-c.txt:1: name=ignored"
+		unknownB = cfgDir + "/20-b.conf:2: unknown=x"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		dir        string
+		pattern    string
+		strict     bool
+		mustExist  bool
+		expErrs    map[string][]string
+		expName    string
+		expColour  string
+		expNameSet []string
+	}{
+		{
+			ID:         testhelper.MkID("conf files"),
+			dir:        cfgDir,
+			pattern:    "*.conf",
+			expName:    "second",
+			expColour:  "red",
+			expNameSet: []string{cfgLocA, cfgLocB},
+		},
+		{
+			ID:         testhelper.MkID("all files"),
+			dir:        cfgDir,
+			expName:    "ignored",
+			expColour:  "red",
+			expNameSet: []string{cfgLocA, cfgLocB, cfgLocC},
+		},
+		{
+			ID:      testhelper.MkID("strict"),
+			dir:     cfgDir,
+			pattern: "*.conf",
+			strict:  true,
+			expErrs: map[string][]string{
+				"unknown": {
+					"this is not a parameter of this program",
+					unknownB,
+				},
+			},
+			expName:    "second",
+			expColour:  "red",
+			expNameSet: []string{cfgLocA, cfgLocB},
+		},
+		{
+			ID:  testhelper.MkID("missing optional dir"),
+			dir: noSuch,
+		},
+		{
+			ID:        testhelper.MkID("missing mandatory dir"),
+			dir:       noSuch,
+			mustExist: true,
+			expErrs: map[string][]string{
+				"config file dir: " + noSuch: {"no such file or directory"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+			exists := filecheck.Optional
+			if tc.mustExist {
+				exists = filecheck.MustExist
+			}
+
+			if tc.strict {
+				ps.AddConfigDirStrict(tc.dir, tc.pattern, exists)
+			} else {
+				ps.AddConfigDir(tc.dir, tc.pattern, exists)
+			}
+
+			var name, colour string
+
+			nameParam := ps.Add("name",
+				psetter.String[string]{Value: &name}, "desc")
+			ps.Add("colour", psetter.String[string]{Value: &colour}, "desc")
+
+			ps.Parse([]string{})
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+			testhelper.DiffString(t, tc.IDStr(), "name", name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "colour",
+				colour, tc.expColour)
+			testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+				nameParam.WhereSet(), tc.expNameSet)
+		})
+	}
+}
+
+func TestConfigDirFormats(t *testing.T) {
+	const (
+		cfgDir = "testdata/conf-fmt.d"
+		id     = "config dir formats"
+	)
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigDir(cfgDir, "", filecheck.MustExist)
+
+	var name, colour string
+
+	nameParam := ps.Add("name", psetter.String[string]{Value: &name}, "desc")
+	ps.Add("colour", psetter.String[string]{Value: &colour}, "desc")
+
+	ps.Parse([]string{})
+
+	errMapCheck(t, id, ps.Errors(), nil)
+	testhelper.DiffString(t, id, "name", name, "second")
+	testhelper.DiffString(t, id, "colour", colour, "blue")
+	testhelper.DiffStringSlice(t, id, "where set", nameParam.WhereSet(),
+		[]string{
+			"[config file]: " + cfgDir + "/10-a.conf:1: name=first",
+			"[config file]: " + cfgDir + "/20-b.yaml:1: name = second",
+		})
+}
+
+func TestConfigDirHome(t *testing.T) {
+	const (
+		dir = "param-test-no-such-config-dir"
+		id  = "config dir in home dir"
+	)
+
+	u, err := user.Current()
+	if err != nil {
+		t.Skip("cannot find the current user:", err)
+	}
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigDir("~/"+dir, "", filecheck.MustExist)
+
+	_, err = ps.ConfigDirs()[0].Files()
+	testhelper.DiffBool(t, id, "not exist", errors.Is(err, fs.ErrNotExist),
+		true)
+
+	var pErr *fs.PathError
+	if errors.As(err, &pErr) {
+		testhelper.DiffString(t, id, "path",
+			pErr.Path, filepath.Join(u.HomeDir, dir))
+	} else {
+		t.Error(id, ": the error is not a *fs.PathError:", err)
+	}
+}
+
+func TestAddConfigDirPanics(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		pattern string
+		exists  filecheck.Exists
+	}{
+		{
+			ID:      testhelper.MkID("good"),
+			pattern: "*.conf",
+		},
+		{
+			ID:      testhelper.MkID("bad pattern"),
+			pattern: "[",
+			ExpPanic: testhelper.MkExpPanic(
+				`config dir "dir": bad pattern "[": syntax error in pattern`),
+		},
+		{
+			ID:      testhelper.MkID("bad existence constraint"),
+			pattern: "*.conf",
+			exists:  filecheck.MustNotExist,
+			ExpPanic: testhelper.MkExpPanic(
+				`config dir "dir": bad existence constraint.`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.AddConfigDir("dir", tc.pattern, tc.exists)
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
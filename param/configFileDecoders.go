@@ -0,0 +1,471 @@
+package param
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// errCfDocTooDeep is returned when a structured config file has values
+// nested more deeply than a single group level
+var errCfDocTooDeep = errors.New(
+	"values may only be nested one level deep (for a parameter group)")
+
+// lineAt returns the line number at the given offset into the data
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:offset], []byte{'\n'}) + 1
+}
+
+// jsonCfDecoder holds the state needed while decoding a JSON config file
+type jsonCfDecoder struct {
+	data    []byte
+	dec     *json.Decoder
+	entries []cfDocEntry
+}
+
+// lineErr returns a cfDocLineErr for the current position in the data
+func (jd *jsonCfDecoder) lineErr(err error) error {
+	return cfDocLineErr{line: lineAt(jd.data, jd.dec.InputOffset()), err: err}
+}
+
+// token returns the next token from the decoder, converting any syntax
+// error into a cfDocLineErr
+func (jd *jsonCfDecoder) token() (json.Token, error) {
+	tok, err := jd.dec.Token()
+	if err != nil {
+		if se, ok := errors.AsType[*json.SyntaxError](err); ok {
+			return nil, cfDocLineErr{line: lineAt(jd.data, se.Offset), err: se}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil, jd.lineErr(io.ErrUnexpectedEOF)
+		}
+
+		return nil, err
+	}
+
+	return tok, nil
+}
+
+// scalar sets the entry value from the token. It returns an error if the
+// token is not a scalar value.
+func (jd *jsonCfDecoder) scalar(e *cfDocEntry, tok json.Token) error {
+	switch v := tok.(type) {
+	case string:
+		e.vals = append(e.vals, v)
+	case json.Number:
+		e.vals = append(e.vals, v.String())
+	case bool:
+		e.vals = append(e.vals, strconv.FormatBool(v))
+		e.isBool = true
+	default:
+		return jd.lineErr(fmt.Errorf("unexpected value: %v", tok))
+	}
+
+	return nil
+}
+
+// array reads the values of an array into the entry
+func (jd *jsonCfDecoder) array(e *cfDocEntry) error {
+	e.isList = true
+
+	for jd.dec.More() {
+		tok, err := jd.token()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := tok.(json.Delim); ok || tok == nil {
+			return jd.lineErr(
+				errors.New("array values must be strings, numbers or booleans"))
+		}
+
+		if err := jd.scalar(e, tok); err != nil {
+			return err
+		}
+	}
+
+	e.isBool = false
+	_, err := jd.token() // the closing ']'
+
+	return err
+}
+
+// object reads the members of a JSON object and records an entry for each
+func (jd *jsonCfDecoder) object(group string) error {
+	for jd.dec.More() {
+		tok, err := jd.token()
+		if err != nil {
+			return err
+		}
+
+		e := cfDocEntry{
+			line:  lineAt(jd.data, jd.dec.InputOffset()),
+			group: group,
+			name:  tok.(string), //nolint:forcetypeassert
+		}
+
+		tok, err = jd.token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			if group != "" {
+				return jd.lineErr(errCfDocTooDeep)
+			}
+
+			if err := jd.object(e.name); err != nil {
+				return err
+			}
+
+			continue
+		case json.Delim('['):
+			err = jd.array(&e)
+		case nil:
+			e.noVal = true
+		default:
+			err = jd.scalar(&e, tok)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		jd.entries = append(jd.entries, e)
+	}
+
+	_, err := jd.token() // the closing '}'
+
+	return err
+}
+
+// decodeJSONConfig decodes the JSON data into a list of entries. The data
+// must hold a single JSON object.
+func decodeJSONConfig(data []byte) ([]cfDocEntry, error) {
+	jd := &jsonCfDecoder{
+		data: data,
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+	}
+	jd.dec.UseNumber()
+
+	tok, err := jd.token()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok != json.Delim('{') {
+		return nil, jd.lineErr(errors.New("the file must hold a JSON object"))
+	}
+
+	if err := jd.object(""); err != nil {
+		return nil, err
+	}
+
+	if _, err := jd.dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, jd.lineErr(
+			errors.New("unexpected content after the JSON object"))
+	}
+
+	return jd.entries, nil
+}
+
+// yamlScalar sets the entry value from the node. It returns an error if the
+// node is not a scalar value.
+func yamlScalar(e *cfDocEntry, n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	if n.Kind != yaml.ScalarNode {
+		return cfDocLineErr{
+			line: n.Line,
+			err:  errors.New("a list value must be a simple value"),
+		}
+	}
+
+	switch n.Tag {
+	case "!!null":
+		e.noVal = true
+	case "!!bool":
+		e.vals = append(e.vals, strings.ToLower(n.Value))
+		e.isBool = true
+	default:
+		e.vals = append(e.vals, n.Value)
+	}
+
+	return nil
+}
+
+// yamlMapping reads the members of a YAML mapping and returns an entry for
+// each
+func yamlMapping(n *yaml.Node, group string) ([]cfDocEntry, error) {
+	var entries []cfDocEntry
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if v.Kind == yaml.AliasNode {
+			v = v.Alias
+		}
+
+		e := cfDocEntry{line: k.Line, group: group, name: k.Value}
+
+		switch v.Kind {
+		case yaml.MappingNode:
+			if group != "" {
+				return nil, cfDocLineErr{line: k.Line, err: errCfDocTooDeep}
+			}
+
+			groupEntries, err := yamlMapping(v, k.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, groupEntries...)
+
+			continue
+		case yaml.SequenceNode:
+			e.isList = true
+
+			for _, elt := range v.Content {
+				if err := yamlScalar(&e, elt); err != nil {
+					return nil, err
+				}
+			}
+
+			e.isBool = false
+			e.noVal = false
+		default:
+			if err := yamlScalar(&e, v); err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// decodeYAMLConfig decodes the YAML data into a list of entries. The data
+// must hold a single YAML mapping.
+func decodeYAMLConfig(data []byte) ([]cfDocEntry, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	top := doc.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return nil, cfDocLineErr{
+			line: top.Line,
+			err:  errors.New("the file must hold a YAML mapping"),
+		}
+	}
+
+	return yamlMapping(top, "")
+}
+
+// tomlCfDecoder holds the state needed while decoding a TOML config file
+type tomlCfDecoder struct {
+	p       unstable.Parser
+	group   string
+	entries []cfDocEntry
+}
+
+// line returns the line number of the node
+func (td *tomlCfDecoder) line(n *unstable.Node) int {
+	return td.p.Shape(n.Raw).Start.Line
+}
+
+// keys returns the parts of the node's key and the line number of the first
+// part
+func (td *tomlCfDecoder) keys(n *unstable.Node) ([]string, int) {
+	var (
+		keys []string
+		line int
+	)
+
+	for it := n.Key(); it.Next(); {
+		k := it.Node()
+		if len(keys) == 0 {
+			line = td.line(k)
+		}
+
+		keys = append(keys, string(k.Data))
+	}
+
+	return keys, line
+}
+
+// scalar sets the entry value from the node. It returns an error if the
+// node is not a scalar value.
+func (td *tomlCfDecoder) scalar(e *cfDocEntry, n *unstable.Node) error {
+	switch n.Kind {
+	case unstable.Array, unstable.InlineTable:
+		return cfDocLineErr{
+			line: e.line,
+			err:  errors.New("array values must be simple values"),
+		}
+	case unstable.Bool:
+		e.isBool = true
+	}
+
+	val, err := tomlScalarVal(n)
+	if err != nil {
+		return cfDocLineErr{line: e.line, err: err}
+	}
+
+	e.vals = append(e.vals, val)
+
+	return nil
+}
+
+// tomlScalarVal returns the value of the scalar node in the form expected
+// by the setters. TOML allows forms which the setters would not accept:
+// integers can have underscores between the digits and can be given in
+// hexadecimal, octal or binary; floats can have underscores between the
+// digits and dates and times can be separated by a space or a lowercase
+// 't' and can have a lowercase 'z'. These are converted to decimal integers,
+// floats without underscores and RFC 3339 dates and times.
+func tomlScalarVal(n *unstable.Node) (string, error) {
+	val := string(n.Data)
+
+	switch n.Kind {
+	case unstable.Integer:
+		i, err := strconv.ParseInt(val, 0, 64)
+		if err != nil {
+			return "", fmt.Errorf("bad integer value %q: %w", val, err)
+		}
+
+		return strconv.FormatInt(i, 10), nil
+	case unstable.Float:
+		return strings.ReplaceAll(val, "_", ""), nil
+	case unstable.DateTime, unstable.LocalDateTime:
+		const dateLen = len("2006-01-02")
+		if len(val) > dateLen {
+			val = val[:dateLen] + "T" + val[dateLen+1:]
+		}
+
+		return strings.ToUpper(val), nil
+	}
+
+	return val, nil
+}
+
+// keyValue records an entry for the key/value node
+func (td *tomlCfDecoder) keyValue(n *unstable.Node, group string) error {
+	keys, line := td.keys(n)
+	if len(keys) > 1 {
+		if group != "" || len(keys) > 2 {
+			return cfDocLineErr{line: line, err: errCfDocTooDeep}
+		}
+
+		group, keys = keys[0], keys[1:]
+	}
+
+	e := cfDocEntry{line: line, group: group, name: keys[0]}
+	v := n.Value()
+
+	switch v.Kind {
+	case unstable.InlineTable:
+		if group != "" {
+			return cfDocLineErr{line: line, err: errCfDocTooDeep}
+		}
+
+		for it := v.Children(); it.Next(); {
+			if err := td.keyValue(it.Node(), e.name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case unstable.Array:
+		e.isList = true
+
+		for it := v.Children(); it.Next(); {
+			if err := td.scalar(&e, it.Node()); err != nil {
+				return err
+			}
+		}
+
+		e.isBool = false
+	default:
+		if err := td.scalar(&e, v); err != nil {
+			return err
+		}
+	}
+
+	td.entries = append(td.entries, e)
+
+	return nil
+}
+
+// expression processes a single top-level TOML expression
+func (td *tomlCfDecoder) expression(n *unstable.Node) error {
+	switch n.Kind {
+	case unstable.Table:
+		keys, line := td.keys(n)
+		if len(keys) > 1 {
+			return cfDocLineErr{line: line, err: errCfDocTooDeep}
+		}
+
+		td.group = keys[0]
+	case unstable.ArrayTable:
+		_, line := td.keys(n)
+
+		return cfDocLineErr{
+			line: line,
+			err:  errors.New("arrays of tables are not allowed"),
+		}
+	case unstable.KeyValue:
+		return td.keyValue(n, td.group)
+	}
+
+	return nil
+}
+
+// decodeTOMLConfig decodes the TOML data into a list of entries. Tables are
+// taken as parameter groups.
+func decodeTOMLConfig(data []byte) ([]cfDocEntry, error) {
+	td := &tomlCfDecoder{}
+	td.p.Reset(data)
+
+	for td.p.NextExpression() {
+		if err := td.expression(td.p.Expression()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := td.p.Error(); err != nil {
+		pe, ok := errors.AsType[*unstable.ParserError](err)
+		if ok && len(pe.Highlight) > 0 {
+			return nil, cfDocLineErr{
+				line: td.p.Shape(td.p.Range(pe.Highlight)).Start.Line,
+				err:  err,
+			}
+		}
+
+		// the error is at the end of the document so report it against the
+		// last line with any content
+		return nil, cfDocLineErr{
+			line: lineAt(data, int64(len(bytes.TrimRight(data, " \t\r\n")))),
+			err:  err,
+		}
+	}
+
+	return td.entries, nil
+}
@@ -0,0 +1,336 @@
+package param
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/location.mod/location"
+)
+
+// ConfigFileFormat describes the format of a configuration file
+type ConfigFileFormat int
+
+const (
+	// ConfigFmtLine is the default format for configuration files. Each
+	// line holds a single parameter, optionally preceded by a list of
+	// program names, and followed by an optional value. See
+	// [PSet.SetConfigFile] for details.
+	ConfigFmtLine ConfigFileFormat = iota
+	// ConfigFmtJSON means that the configuration file holds a JSON object
+	ConfigFmtJSON
+	// ConfigFmtYAML means that the configuration file holds a YAML mapping
+	ConfigFmtYAML
+	// ConfigFmtTOML means that the configuration file is a TOML document
+	ConfigFmtTOML
+)
+
+// String returns a string describing the ConfigFileFormat
+func (f ConfigFileFormat) String() string {
+	switch f {
+	case ConfigFmtLine:
+		return "line"
+	case ConfigFmtJSON:
+		return "JSON"
+	case ConfigFmtYAML:
+		return "YAML"
+	case ConfigFmtTOML:
+		return "TOML"
+	}
+
+	return fmt.Sprintf("unknown config file format: %d", f)
+}
+
+// ConfigFileFormatFromName returns the ConfigFileFormat implied by the
+// extension of the file name. A file ending in ".json" is taken to be JSON,
+// one ending in ".yaml" or ".yml" is taken to be YAML and one ending in
+// ".toml" is taken to be TOML. Any other file is taken to be in the line
+// format.
+func ConfigFileFormatFromName(fName string) ConfigFileFormat {
+	switch strings.ToLower(filepath.Ext(fName)) {
+	case ".json":
+		return ConfigFmtJSON
+	case ".yaml", ".yml":
+		return ConfigFmtYAML
+	case ".toml":
+		return ConfigFmtTOML
+	}
+
+	return ConfigFmtLine
+}
+
+// checkConfigFileFormat will panic if the format is not a known value
+func checkConfigFileFormat(fName string, f ConfigFileFormat) {
+	if f < ConfigFmtLine || f > ConfigFmtTOML {
+		panic(fmt.Sprintf("config file %q: bad format: %d", fName, f))
+	}
+}
+
+// AddConfigFileFmt behaves as for AddConfigFile except that the format of
+// the file is given. The file can be in the standard line format or it can
+// be a JSON, YAML or TOML document.
+//
+// A JSON, YAML or TOML document should hold a mapping (an object or a table)
+// from parameter names to values. As for the line format, the parameter
+// name can be preceded by a comma-separated list of program names and a
+// slash in which case the parameter will only be applied when the file is
+// being parsed by one of the listed programs.
+//
+// A nested mapping is taken to be a set of parameters all of which must be
+// members of the parameter group with the same name as the key of the
+// nested mapping; the parameters must exist. Only one level of nesting is
+// allowed.
+//
+// The values may be strings, numbers or booleans. A null value (JSON and
+// YAML only) means that the parameter is given without a value. A boolean
+// value given for a parameter which does not take a value will cause the
+// parameter to be set if it is true. If it is false then the parameter will
+// be negated if it is negatable (see [Negatable]) and otherwise ignored, so
+// a false value can override an earlier true one.
+//
+// An array (or sequence) of values is taken as a list. If the parameter
+// takes a list of values (its Setter has a GetSeparator method) then the
+// values will be joined together with the separator and the parameter will
+// be set just once. Otherwise the parameter is set once for each value in
+// the array, in order.
+//
+// The line number of the parameter in the file is recorded as the location
+// where the parameter was set and is reported with any errors.
+//
+// The config file must be added before the parameters are parsed; this will
+// panic otherwise.
+func (ps *PSet) AddConfigFileFmt(
+	fName string, f ConfigFileFormat, c filecheck.Exists,
+) {
+	ps.panicIfAlreadyParsed(
+		fmt.Sprintf("can't add the config file %q", fName))
+
+	checkExistenceConstraint(fName, c)
+	checkConfigFileFormat(fName, f)
+
+	ps.configFiles = append(ps.configFiles,
+		ConfigFileDetails{
+			Name:         fName,
+			CfConstraint: c,
+			Format:       f,
+			eRule:        paramNeedNotExist,
+		})
+}
+
+// AddConfigFileStrictFmt behaves as for AddConfigFileFmt except that
+// parameters given in the file must exist for the given program. See
+// [PSet.AddConfigFileStrict].
+//
+// The config file must be added before the parameters are parsed; this will
+// panic otherwise.
+func (ps *PSet) AddConfigFileStrictFmt(
+	fName string, f ConfigFileFormat, c filecheck.Exists,
+) {
+	ps.panicIfAlreadyParsed(
+		fmt.Sprintf("can't add the config file %q", fName))
+
+	checkExistenceConstraint(fName, c)
+	checkConfigFileFormat(fName, f)
+
+	ps.configFiles = append(ps.configFiles,
+		ConfigFileDetails{
+			Name:         fName,
+			CfConstraint: c,
+			Format:       f,
+			eRule:        paramMustExist,
+		})
+}
+
+// cfDocEntry records a parameter setting found in a JSON, YAML or TOML
+// configuration file
+type cfDocEntry struct {
+	line   int
+	group  string
+	name   string
+	vals   []string
+	noVal  bool
+	isBool bool
+	isList bool
+}
+
+// content returns a string representing the entry suitable for setting as
+// the content of a location
+func (e cfDocEntry) content() string {
+	s := e.name
+	if e.group != "" {
+		s = e.group + "." + s
+	}
+
+	switch {
+	case e.noVal:
+		return s
+	case e.isList:
+		return s + " = [" + strings.Join(e.vals, ", ") + "]"
+	}
+
+	return s + " = " + strings.Join(e.vals, "")
+}
+
+// cfDocDecoder is the type of a function which decodes the contents of a
+// structured configuration file into a slice of entries
+type cfDocDecoder func(data []byte) ([]cfDocEntry, error)
+
+// cfDocDecoders maps the structured config file formats to the functions
+// which decode them
+var cfDocDecoders = map[ConfigFileFormat]cfDocDecoder{
+	ConfigFmtJSON: decodeJSONConfig,
+	ConfigFmtYAML: decodeYAMLConfig,
+	ConfigFmtTOML: decodeTOMLConfig,
+}
+
+// cfDocLoc returns a location for the given line of the named file
+func cfDocLoc(fName, desc string, line int) *location.L {
+	loc := location.New(fName)
+	loc.SetNote(desc)
+
+	for range line {
+		loc.Incr()
+	}
+
+	return loc
+}
+
+// cfDocLineErr is the type of an error which has an associated line
+// number. The decoders return errors of this type where the line is known.
+type cfDocLineErr struct {
+	line int
+	err  error
+}
+
+// Error returns the error message
+func (e cfDocLineErr) Error() string { return e.err.Error() }
+
+// parseStructuredConfigFile reads the named file and decodes it according
+// to its format. It then sets the parameters from the entries found.
+func (ps *PSet) parseStructuredConfigFile(
+	cf ConfigFileDetails, desc string,
+) []error {
+	fName, err := fileparse.FixFileName(cf.Name)
+	if err != nil {
+		return []error{
+			fmt.Errorf("%s: Couldn't expand: %q : %w", desc, cf.Name, err),
+		}
+	}
+
+	data, err := os.ReadFile(fName) //nolint:gosec
+	if err != nil {
+		return []error{err}
+	}
+
+	entries, err := cfDocDecoders[cf.Format](data)
+	if err != nil {
+		if lineErr, ok := err.(cfDocLineErr); ok {
+			err = cfDocLoc(fName, desc, lineErr.line).Error(
+				"bad " + cf.Format.String() + " file: " + lineErr.Error())
+		} else {
+			err = fmt.Errorf("%s: %s: bad %s file: %w",
+				desc, fName, cf.Format, err)
+		}
+
+		return []error{err}
+	}
+
+	for _, e := range entries {
+		loc := cfDocLoc(fName, desc, e.line)
+		loc.SetContent(e.content())
+
+		ps.setFromCfDocEntry(e, loc, cf.eRule)
+	}
+
+	return nil
+}
+
+// findParamToSet returns the parameter to be set for the given name. The
+// name may have a sub-command name prefix in which case the parameter is
+// looked for in the sub-command's parameter set.
+func (ps *PSet) findParamToSet(name string) (*ByName, bool) {
+	if scName, pName, ok := strings.Cut(name, subCmdSep); ok {
+		sc, ok := ps.subCmds[scName]
+		if !ok {
+			return nil, false
+		}
+
+		return sc.ps.findParamToSet(pName)
+	}
+
+	return ps.findParam(name)
+}
+
+// oppositeName returns the name of the negatable parameter which has the
+// opposite effect to the given name. This is the negated form of the name
+// or, if the name is already negated, the name without the NegationPrefix.
+// Any sub-command part of the name is kept.
+func (p ByName) oppositeName(name string) string {
+	scPart := ""
+	if scName, pName, ok := strings.Cut(name, subCmdSep); ok {
+		scPart, name = scName+subCmdSep, pName
+	}
+
+	if p.isNegatedName(name) {
+		return scPart + strings.TrimPrefix(name, NegationPrefix)
+	}
+
+	return scPart + NegationPrefix + name
+}
+
+// listSeparator is the interface satisfied by a Setter which takes a list
+// of values
+type listSeparator interface {
+	GetSeparator() string
+}
+
+// setFromCfDocEntry sets the parameter from the entry. If the entry is for
+// a parameter group then the parameter must exist and be in that group.
+func (ps *PSet) setFromCfDocEntry(
+	e cfDocEntry, loc *location.L, eRule existenceRule,
+) {
+	programs, pName := splitParamSpec(e.name)
+	cfe := configFileEntry{programs: programs, paramName: pName}
+
+	if cfe.ignoreForThisProgram(ps.progBaseName) {
+		return
+	}
+
+	if cfe.hasSpecificPrograms() || e.group != "" {
+		eRule = paramMustExist
+	}
+
+	gName := e.group
+
+	p, exists := ps.findParamToSet(pName)
+
+	switch {
+	case e.noVal:
+		ps.setValue([]string{pName}, loc, eRule, gName)
+	case e.isBool && exists && p.setter.ValueReq() == None:
+		switch {
+		case e.vals[0] == "true":
+			ps.setValue([]string{pName}, loc, eRule, gName)
+		case p.negatable:
+			ps.setValue([]string{p.oppositeName(pName)}, loc, eRule, gName)
+		}
+	case e.isList && exists:
+		if ls, ok := p.setter.(listSeparator); ok {
+			ps.setValue(
+				[]string{pName, strings.Join(e.vals, ls.GetSeparator())},
+				loc, eRule, gName)
+
+			break
+		}
+
+		for _, v := range e.vals {
+			ps.setValue([]string{pName, v}, loc, eRule, gName)
+		}
+	default:
+		ps.setValue([]string{pName, strings.Join(e.vals, "")},
+			loc, eRule, gName)
+	}
+}
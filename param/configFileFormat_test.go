@@ -0,0 +1,416 @@
+package param_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestConfigFileFormat(t *testing.T) {
+	const (
+		cfLoc      = "[config file]: "
+		jsonFile   = "testdata/config.json"
+		yamlFile   = "testdata/config.yaml"
+		tomlFile   = "testdata/config.toml"
+		badJSON    = "testdata/config-bad.json"
+		deepYAML   = "testdata/config-deep.yaml"
+		badTOML    = "testdata/config-bad.toml"
+		wrongGroup = "testdata/config-wrong-group.toml"
+	)
+
+	goodVals := expVals{pi1Val: 42, pb1Val: true}
+
+	testCases := []struct {
+		testhelper.ID
+		fileName    string
+		format      param.ConfigFileFormat
+		strict      bool
+		expErrs     map[string][]string
+		expVals     expVals
+		expEx1      bool
+		expEx2      int64
+		expList     []string
+		expApp      []string
+		expWhereSet []string
+	}{
+		{
+			ID:       testhelper.MkID("JSON"),
+			fileName: jsonFile,
+			format:   param.ConfigFmtJSON,
+			expVals:  goodVals,
+			expEx1:   true,
+			expEx2:   5,
+			expList:  []string{"a", "b"},
+			expApp:   []string{"x", "y"},
+			expWhereSet: []string{
+				cfLoc + jsonFile + ":3: example2 = 5",
+			},
+		},
+		{
+			ID:       testhelper.MkID("YAML"),
+			fileName: yamlFile,
+			format:   param.ConfigFmtYAML,
+			expVals:  goodVals,
+			expEx1:   true,
+			expEx2:   5,
+			expList:  []string{"a", "b"},
+			expApp:   []string{"x", "y"},
+			expWhereSet: []string{
+				cfLoc + yamlFile + ":3: example2 = 5",
+			},
+		},
+		{
+			ID:       testhelper.MkID("TOML"),
+			fileName: tomlFile,
+			format:   param.ConfigFmtTOML,
+			expVals:  goodVals,
+			expEx1:   true,
+			expEx2:   5,
+			expList:  []string{"a", "b"},
+			expApp:   []string{"x", "y"},
+			expWhereSet: []string{
+				cfLoc + tomlFile + ":3: example2 = 5",
+			},
+		},
+		{
+			ID:       testhelper.MkID("TOML - strict"),
+			fileName: tomlFile,
+			format:   param.ConfigFmtTOML,
+			strict:   true,
+			expErrs: map[string][]string{
+				"nonesuch": {
+					"this is not a parameter of this program",
+					cfLoc + tomlFile + `:7: nonesuch = x`,
+				},
+			},
+			expVals: goodVals,
+			expEx1:  true,
+			expEx2:  5,
+			expList: []string{"a", "b"},
+			expApp:  []string{"x", "y"},
+			expWhereSet: []string{
+				cfLoc + tomlFile + ":3: example2 = 5",
+			},
+		},
+		{
+			ID:       testhelper.MkID("JSON - syntax error"),
+			fileName: badJSON,
+			format:   param.ConfigFmtJSON,
+			expErrs: map[string][]string{
+				"config file: " + badJSON: {
+					"bad JSON file: invalid character ','",
+					"At: " + cfLoc + badJSON + ":3",
+				},
+			},
+		},
+		{
+			ID:       testhelper.MkID("YAML - nested too deep"),
+			fileName: deepYAML,
+			format:   param.ConfigFmtYAML,
+			expErrs: map[string][]string{
+				"config file: " + deepYAML: {
+					"bad YAML file:" +
+						" values may only be nested one level deep",
+					"At: " + cfLoc + deepYAML + ":4",
+				},
+			},
+		},
+		{
+			ID:       testhelper.MkID("TOML - syntax error"),
+			fileName: badTOML,
+			format:   param.ConfigFmtTOML,
+			expErrs: map[string][]string{
+				"config file: " + badTOML: {
+					"bad TOML file: expected character ]",
+					"At: " + cfLoc + badTOML + ":3",
+				},
+			},
+		},
+		{
+			ID:       testhelper.MkID("TOML - wrong group"),
+			fileName: wrongGroup,
+			format:   param.ConfigFmtTOML,
+			expErrs: map[string][]string{
+				"pi1": {
+					"this parameter is not a member of group: " +
+						groupCFName2,
+					cfLoc + wrongGroup + ":4: grp2.pi1 = 42",
+				},
+			},
+			expEx2: 5,
+			expWhereSet: []string{
+				cfLoc + wrongGroup + ":1: example2 = 5",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			ex1  bool
+			ex2  int64
+			list []string
+			app  []string
+		)
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		addParamsForGroupCF(ps)
+		p := ps.Add("example2", psetter.Int[int64]{Value: &ex2}, "desc")
+		ps.Add("example1", psetter.Bool{Value: &ex1}, "desc")
+		ps.Add("list", psetter.StrList[string]{Value: &list}, "desc")
+		ps.Add("app", psetter.StrListAppender[string]{Value: &app}, "desc")
+
+		if tc.strict {
+			ps.AddConfigFileStrictFmt(tc.fileName, tc.format,
+				filecheck.MustExist)
+		} else {
+			ps.AddConfigFileFmt(tc.fileName, tc.format, filecheck.MustExist)
+		}
+
+		resetParamVals()
+
+		ps.Parse([]string{})
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+		valsCheck(t, tc.IDStr(), tc.expVals)
+		testhelper.DiffBool(t, tc.IDStr(), "example1", ex1, tc.expEx1)
+		testhelper.DiffInt(t, tc.IDStr(), "example2", ex2, tc.expEx2)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "list", list, tc.expList)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "app", app, tc.expApp)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+			p.WhereSet(), tc.expWhereSet)
+	}
+}
+
+func TestConfigFileFormatFromName(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		fName  string
+		expFmt param.ConfigFileFormat
+	}{
+		{
+			ID:     testhelper.MkID("json"),
+			fName:  "a.json",
+			expFmt: param.ConfigFmtJSON,
+		},
+		{
+			ID:     testhelper.MkID("yaml"),
+			fName:  "a.yaml",
+			expFmt: param.ConfigFmtYAML,
+		},
+		{
+			ID:     testhelper.MkID("yml"),
+			fName:  "a.YML",
+			expFmt: param.ConfigFmtYAML,
+		},
+		{
+			ID:     testhelper.MkID("toml"),
+			fName:  "a.toml",
+			expFmt: param.ConfigFmtTOML,
+		},
+		{
+			ID:     testhelper.MkID("other"),
+			fName:  "a.cfg",
+			expFmt: param.ConfigFmtLine,
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "format",
+			param.ConfigFileFormatFromName(tc.fName).String(),
+			tc.expFmt.String())
+	}
+}
+
+// flagSetter is a negatable Setter which takes no value
+type flagSetter struct {
+	psetter.ValueReqNone
+
+	Value *bool
+}
+
+// Set sets the value to true
+func (s flagSetter) Set(_ string) error {
+	*s.Value = true
+	return nil
+}
+
+// SetNegated sets the value to false
+func (s flagSetter) SetNegated(_ string) error {
+	*s.Value = false
+	return nil
+}
+
+// AllowedValues returns a description of the allowed values
+func (s flagSetter) AllowedValues() string { return "none" }
+
+// CurrentValue returns the current value
+func (s flagSetter) CurrentValue() string { return fmt.Sprint(*s.Value) }
+
+// CheckSetter does nothing
+func (s flagSetter) CheckSetter(_ string) {}
+
+func TestConfigFileFormatNegatable(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		negatable bool
+		content   string
+		expFlag   bool
+	}{
+		{
+			ID:        testhelper.MkID("false overrides earlier true"),
+			negatable: true,
+			content:   "flag: false\n",
+			expFlag:   false,
+		},
+		{
+			ID:        testhelper.MkID("false with the negated name"),
+			negatable: true,
+			content:   "no-flag: false\n",
+			expFlag:   true,
+		},
+		{
+			ID:      testhelper.MkID("false ignored when not negatable"),
+			content: "flag: false\n",
+			expFlag: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		dir := t.TempDir()
+		firstFName := filepath.Join(dir, "first.yaml")
+		fName := filepath.Join(dir, "negatable.yaml")
+
+		err := os.WriteFile(firstFName, []byte("flag: true\n"), 0o600)
+		if err != nil {
+			t.Fatal("cannot write the config file:", err)
+		}
+
+		err = os.WriteFile(fName, []byte(tc.content), 0o600)
+		if err != nil {
+			t.Fatal("cannot write the config file:", err)
+		}
+
+		var flag bool
+
+		opts := []param.ByNameOptFunc{}
+		if tc.negatable {
+			opts = append(opts, param.Negatable)
+		}
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("flag", flagSetter{Value: &flag}, "desc", opts...)
+		ps.AddConfigFileFmt(firstFName, param.ConfigFmtYAML,
+			filecheck.MustExist)
+		ps.AddConfigFileFmt(fName, param.ConfigFmtYAML, filecheck.MustExist)
+
+		ps.Parse([]string{})
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+		testhelper.DiffBool(t, tc.IDStr(), "flag", flag, tc.expFlag)
+	}
+}
+
+func TestConfigFileFormatTOMLScalars(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		content string
+		expInt  int64
+		expFlt  float64
+		expStr  string
+		expTime time.Time
+	}{
+		{
+			ID:      testhelper.MkID("decimal with underscores"),
+			content: "int = 1_000\n",
+			expInt:  1000,
+		},
+		{
+			ID:      testhelper.MkID("hexadecimal"),
+			content: "int = 0xff\n",
+			expInt:  255,
+		},
+		{
+			ID:      testhelper.MkID("octal"),
+			content: "int = 0o17\n",
+			expInt:  15,
+		},
+		{
+			ID:      testhelper.MkID("binary"),
+			content: "int = 0b101\n",
+			expInt:  5,
+		},
+		{
+			ID:      testhelper.MkID("float with underscores"),
+			content: "flt = 1_000.5\n",
+			expFlt:  1000.5,
+		},
+		{
+			ID:      testhelper.MkID("datetime with a space and a 'z'"),
+			content: "str = 1979-05-27 07:32:00z\n",
+			expStr:  "1979-05-27T07:32:00Z",
+		},
+		{
+			ID:      testhelper.MkID("local datetime with a 't'"),
+			content: "str = 1979-05-27t07:32:00.5\n",
+			expStr:  "1979-05-27T07:32:00.5",
+		},
+		{
+			ID:      testhelper.MkID("datetime with an offset"),
+			content: "tm = 1979-05-27 07:32:00-07:00\n",
+			expTime: time.Date(1979, time.May, 27, 7, 32, 0, 0,
+				time.FixedZone("", -7*60*60)),
+		},
+		{
+			ID:      testhelper.MkID("local date"),
+			content: "str = 1979-05-27\n",
+			expStr:  "1979-05-27",
+		},
+	}
+
+	for _, tc := range testCases {
+		fName := filepath.Join(t.TempDir(), "scalars.toml")
+
+		err := os.WriteFile(fName, []byte(tc.content), 0o600)
+		if err != nil {
+			t.Fatal("cannot write the config file:", err)
+		}
+
+		var (
+			i   int64
+			f   float64
+			str string
+			tm  time.Time
+		)
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("int", psetter.Int[int64]{Value: &i}, "desc")
+		ps.Add("flt", psetter.Float[float64]{Value: &f}, "desc")
+		ps.Add("str", psetter.String[string]{Value: &str}, "desc")
+		ps.Add("tm", psetter.Time{Value: &tm, Format: time.RFC3339}, "desc")
+		ps.AddConfigFileFmt(fName, param.ConfigFmtTOML, filecheck.MustExist)
+
+		ps.Parse([]string{})
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+		testhelper.DiffInt(t, tc.IDStr(), "int", i, tc.expInt)
+		testhelper.DiffFloat(t, tc.IDStr(), "flt", f, tc.expFlt, 0)
+		testhelper.DiffString(t, tc.IDStr(), "str", str, tc.expStr)
+
+		if !tm.Equal(tc.expTime) {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected time: %s", tc.expTime)
+			t.Logf("\t:   actual time: %s", tm)
+			t.Error("\t: bad time")
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/english.mod/english"
+)
+
+// ConstraintKind identifies the type of relationship between parameters
+// that a Constraint enforces
+type ConstraintKind int
+
+const (
+	// ConstraintMutuallyExclusive means that at most one of the parameters
+	// may be set
+	ConstraintMutuallyExclusive ConstraintKind = iota
+	// ConstraintAtLeastOne means that at least one of the parameters must be
+	// set
+	ConstraintAtLeastOne
+	// ConstraintExactlyOne means that one and only one of the parameters
+	// must be set
+	ConstraintExactlyOne
+	// ConstraintRequires means that if the first parameter is set then all
+	// the other parameters must also be set
+	ConstraintRequires
+	// ConstraintImplies means that if the first parameter is set then the
+	// second parameter must have a particular value
+	ConstraintImplies
+	// ConstraintConflictsWith means that if the first parameter is set then
+	// none of the other parameters may be set
+	ConstraintConflictsWith
+)
+
+// Constraint records a relationship between parameters which is checked
+// after all the parameters have been parsed. Any violation of the
+// constraint is recorded in the error map of the PSet. Constraints should be
+// created through the MutuallyExclusive, AtLeastOne, ExactlyOne, Requires,
+// Implies and ConflictsWith functions and added to the PSet through the
+// AddConstraint method.
+type Constraint struct {
+	kind       ConstraintKind
+	names      []string
+	value      string
+	whereAdded string
+}
+
+// Kind returns the kind of the constraint
+func (c Constraint) Kind() ConstraintKind { return c.kind }
+
+// Names returns a copy of the names of the parameters that the constraint
+// applies to. For the Requires, Implies and ConflictsWith constraints the
+// first name is that of the parameter which, if set, triggers the check.
+func (c Constraint) Names() []string {
+	names := make([]string, len(c.names))
+
+	copy(names, c.names)
+
+	return names
+}
+
+// Value returns the value that the constraint requires. This is only set
+// for an Implies constraint.
+func (c Constraint) Value() string { return c.value }
+
+// String returns a description of the constraint suitable for use in a help
+// message
+func (c Constraint) String() string {
+	switch c.kind {
+	case ConstraintMutuallyExclusive:
+		return "at most one of " + c.joinNames(c.names, " or ") +
+			" may be given"
+	case ConstraintAtLeastOne:
+		return "at least one of " + c.joinNames(c.names, " or ") +
+			" must be given"
+	case ConstraintExactlyOne:
+		return "exactly one of " + c.joinNames(c.names, " or ") +
+			" must be given"
+	case ConstraintRequires:
+		return fmt.Sprintf("if %q is given then %s must also be given",
+			c.names[0], c.joinNames(c.names[1:], " and "))
+	case ConstraintImplies:
+		return fmt.Sprintf("if %q is given then %q must have the value %q",
+			c.names[0], c.names[1], c.value)
+	case ConstraintConflictsWith:
+		return fmt.Sprintf("if %q is given then %s must not be given",
+			c.names[0], c.joinNames(c.names[1:], " or "))
+	}
+
+	return fmt.Sprintf("unknown constraint kind: %d", c.kind)
+}
+
+// joinNames returns the names quoted and joined with the last separator
+// given
+func (c Constraint) joinNames(names []string, lastSep string) string {
+	return english.JoinQuoted(names, ", ", lastSep)
+}
+
+// AppliesTo returns true if the named parameter is one of the parameters
+// that the constraint applies to
+func (c Constraint) AppliesTo(name string) bool {
+	return slices.Contains(c.names, name)
+}
+
+// MutuallyExclusive returns a Constraint which checks that at most one of
+// the named parameters has been set
+func MutuallyExclusive(names ...string) Constraint {
+	return Constraint{
+		kind:       ConstraintMutuallyExclusive,
+		names:      slices.Clone(names),
+		whereAdded: caller(),
+	}
+}
+
+// AtLeastOne returns a Constraint which checks that at least one of the
+// named parameters has been set
+func AtLeastOne(names ...string) Constraint {
+	return Constraint{
+		kind:       ConstraintAtLeastOne,
+		names:      slices.Clone(names),
+		whereAdded: caller(),
+	}
+}
+
+// ExactlyOne returns a Constraint which checks that one and only one of the
+// named parameters has been set
+func ExactlyOne(names ...string) Constraint {
+	return Constraint{
+		kind:       ConstraintExactlyOne,
+		names:      slices.Clone(names),
+		whereAdded: caller(),
+	}
+}
+
+// Requires returns a Constraint which checks that if the named parameter
+// has been set then all of the required parameters have also been set
+func Requires(name string, required ...string) Constraint {
+	return Constraint{
+		kind:       ConstraintRequires,
+		names:      append([]string{name}, required...),
+		whereAdded: caller(),
+	}
+}
+
+// Implies returns a Constraint which checks that if the named parameter has
+// been set then the target parameter has the given value. The value is
+// compared with the value reported by the CurrentValue method of the
+// target parameter's Setter so it should be given in the same form. Note
+// that the target parameter need not have been set itself; it is enough for
+// it to have the value, so an initial value will satisfy the constraint.
+func Implies(name, target, val string) Constraint {
+	return Constraint{
+		kind:       ConstraintImplies,
+		names:      []string{name, target},
+		value:      val,
+		whereAdded: caller(),
+	}
+}
+
+// ConflictsWith returns a Constraint which checks that if the named
+// parameter has been set then none of the conflicting parameters have been
+// set
+func ConflictsWith(name string, conflicts ...string) Constraint {
+	return Constraint{
+		kind:       ConstraintConflictsWith,
+		names:      append([]string{name}, conflicts...),
+		whereAdded: caller(),
+	}
+}
+
+// AddConstraint adds the constraints to the PSet. The constraints are
+// checked after all the parameters have been processed and any violations
+// are reported as errors. The names in each constraint are checked when
+// Parse is called and the program will panic if any name is not that of a
+// named parameter or if there are too few names for the constraint to make
+// sense.
+//
+// This will panic if called after the parameters have been parsed.
+func (ps *PSet) AddConstraint(constraints ...Constraint) {
+	ps.panicIfAlreadyParsed("can't add a parameter constraint")
+
+	ps.constraints = append(ps.constraints, constraints...)
+}
+
+// Constraints returns a copy of the constraints that have been added to the
+// PSet
+func (ps *PSet) Constraints() []Constraint {
+	constraints := make([]Constraint, len(ps.constraints))
+
+	copy(constraints, ps.constraints)
+
+	return constraints
+}
+
+// ConstraintsOnParam returns the constraints that apply to the named
+// parameter. The name should be the parameter's own name, not any of its
+// alternative names. The constraints of any parent PSet are also checked.
+func (ps *PSet) ConstraintsOnParam(name string) []Constraint {
+	var constraints []Constraint
+
+	for s := ps; s != nil; s = s.parent {
+		for _, c := range s.constraints {
+			if c.AppliesTo(name) {
+				constraints = append(constraints, c)
+			}
+		}
+	}
+
+	return constraints
+}
+
+// checkConstraints will make sure that every constraint refers to valid
+// parameter names and has enough names and will panic if not. The names
+// are replaced with the names of the parameters so that any alternative
+// names are replaced with the parameter's own name. The names are copied
+// first so that the caller's Constraint is not changed.
+func (ps *PSet) checkConstraints() {
+	for i, c := range ps.constraints {
+		minNames := 2
+		if c.kind == ConstraintAtLeastOne || c.kind == ConstraintExactlyOne {
+			minNames = 1
+		}
+
+		if len(c.names) < minNames {
+			panic(fmt.Errorf("bad constraint (%s):"+
+				" it has too few parameter names (%d)."+
+				"\nThe constraint was added at: %s",
+				c, len(c.names), c.whereAdded))
+		}
+
+		names := slices.Clone(c.names)
+
+		for j, name := range c.names {
+			p, exists := ps.findParam(name)
+			if !exists {
+				panic(fmt.Errorf("bad constraint (%s):"+
+					" there is no parameter named %q."+
+					"\nThe constraint was added at: %s",
+					c, name, c.whereAdded))
+			}
+
+			names[j] = p.name
+		}
+
+		ps.constraints[i].names = names
+	}
+}
+
+// whereSetDesc returns a description of where the parameters were set.
+func (ps *PSet) whereSetDesc(params []*ByName) string {
+	var desc strings.Builder
+
+	for _, p := range params {
+		for _, ws := range p.whereSetToCheck() {
+			fmt.Fprintf(&desc, "\n%q was given at: %s", p.name, ws)
+		}
+	}
+
+	return desc.String()
+}
+
+// paramsSet returns the subset of the named parameters which have been set
+func (ps *PSet) paramsSet(names []string) []*ByName {
+	var set []*ByName
+
+	for _, name := range names {
+		if p, ok := ps.findParam(name); ok && len(p.whereSetToCheck()) > 0 {
+			set = append(set, p)
+		}
+	}
+
+	return set
+}
+
+// countErr returns the error to be reported when the number of parameters
+// set is wrong
+func (ps *PSet) countErr(c Constraint, set []*ByName) error {
+	if len(set) == 0 {
+		return errors.New(c.String() + " but none were given")
+	}
+
+	return fmt.Errorf("%s but %d were given%s",
+		c, len(set), ps.whereSetDesc(set))
+}
+
+// checkConstraint checks the constraint and records an error if it is
+// violated. The error is recorded against the first parameter named in the
+// constraint.
+func (ps *PSet) checkConstraint(c Constraint) {
+	var err error
+
+	switch c.kind {
+	case ConstraintMutuallyExclusive:
+		if set := ps.paramsSet(c.names); len(set) > 1 {
+			err = ps.countErr(c, set)
+		}
+	case ConstraintAtLeastOne:
+		if set := ps.paramsSet(c.names); len(set) == 0 {
+			err = ps.countErr(c, set)
+		}
+	case ConstraintExactlyOne:
+		if set := ps.paramsSet(c.names); len(set) != 1 {
+			err = ps.countErr(c, set)
+		}
+	case ConstraintRequires:
+		err = ps.checkRequires(c)
+	case ConstraintImplies:
+		err = ps.checkImplies(c)
+	case ConstraintConflictsWith:
+		err = ps.checkConflictsWith(c)
+	}
+
+	ps.AddErr(c.names[0], err)
+}
+
+// checkRequires returns an error if the first parameter in the constraint
+// has been set but not all of the others have been set
+func (ps *PSet) checkRequires(c Constraint) error {
+	set := ps.paramsSet(c.names[:1])
+	if len(set) == 0 {
+		return nil
+	}
+
+	var missing []string
+
+	for _, name := range c.names[1:] {
+		if len(ps.paramsSet([]string{name})) == 0 {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s but %s %s not given%s",
+		c, c.joinNames(missing, " and "),
+		wasOrWere(len(missing)),
+		ps.whereSetDesc(set))
+}
+
+// checkImplies returns an error if the first parameter in the constraint
+// has been set but the second does not have the required value
+func (ps *PSet) checkImplies(c Constraint) error {
+	set := ps.paramsSet(c.names[:1])
+	if len(set) == 0 {
+		return nil
+	}
+
+	target, _ := ps.findParam(c.names[1])
+
+	val := target.valueToCheck()
+	if val == c.value {
+		return nil
+	}
+
+	return fmt.Errorf("%s but its value is %q%s",
+		c, val, ps.whereSetDesc(append(set, target)))
+}
+
+// checkConflictsWith returns an error if the first parameter in the
+// constraint has been set and any of the others have also been set
+func (ps *PSet) checkConflictsWith(c Constraint) error {
+	set := ps.paramsSet(c.names[:1])
+	if len(set) == 0 {
+		return nil
+	}
+
+	conflicts := ps.paramsSet(c.names[1:])
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s but %d %s given%s",
+		c, len(conflicts), wasOrWere(len(conflicts)),
+		ps.whereSetDesc(append(set, conflicts...)))
+}
+
+// wasOrWere returns "was" if the count is one and "were" otherwise
+func wasOrWere(count int) string {
+	if count == 1 {
+		return "was"
+	}
+
+	return "were"
+}
+
+// checkConstraintsAreMet checks all the constraints and records errors for
+// any that are violated
+func (ps *PSet) checkConstraintsAreMet() {
+	for _, c := range ps.constraints {
+		ps.checkConstraint(c)
+	}
+}
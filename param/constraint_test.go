@@ -0,0 +1,242 @@
+package param_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestConstraints(t *testing.T) {
+	const cmdLineLoc = "[command line]: Supplied Parameter:"
+
+	testCases := []struct {
+		testhelper.ID
+		constraints []param.Constraint
+		params      []string
+		expErrs     map[string][]string
+	}{
+		{
+			ID: testhelper.MkID("MutuallyExclusive - ok"),
+			constraints: []param.Constraint{
+				param.MutuallyExclusive("a", "b", "c"),
+			},
+			params: []string{"-a"},
+		},
+		{
+			ID: testhelper.MkID("MutuallyExclusive - bad"),
+			constraints: []param.Constraint{
+				param.MutuallyExclusive("a", "b", "c"),
+			},
+			params: []string{"-a", "-c"},
+			expErrs: map[string][]string{
+				"a": {
+					`at most one of "a", "b" or "c" may be given` +
+						" but 2 were given",
+					`"a" was given at: ` + cmdLineLoc + `1: "-a"`,
+					`"c" was given at: ` + cmdLineLoc + `2: "-c"`,
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("AtLeastOne - ok"),
+			constraints: []param.Constraint{
+				param.AtLeastOne("a", "b"),
+			},
+			params: []string{"-a", "-b"},
+		},
+		{
+			ID: testhelper.MkID("AtLeastOne - bad"),
+			constraints: []param.Constraint{
+				param.AtLeastOne("a", "b"),
+			},
+			params: []string{"-c"},
+			expErrs: map[string][]string{
+				"a": {
+					`at least one of "a" or "b" must be given` +
+						" but none were given",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("ExactlyOne - ok"),
+			constraints: []param.Constraint{
+				param.ExactlyOne("a", "b"),
+			},
+			params: []string{"-b"},
+		},
+		{
+			ID: testhelper.MkID("ExactlyOne - bad - too many"),
+			constraints: []param.Constraint{
+				param.ExactlyOne("a", "b"),
+			},
+			params: []string{"-b", "-a"},
+			expErrs: map[string][]string{
+				"a": {
+					`exactly one of "a" or "b" must be given` +
+						" but 2 were given",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("Requires - ok"),
+			constraints: []param.Constraint{
+				param.Requires("a", "b", "c"),
+			},
+			params: []string{"-a", "-b", "-c"},
+		},
+		{
+			ID: testhelper.MkID("Requires - ok - not set"),
+			constraints: []param.Constraint{
+				param.Requires("a", "b", "c"),
+			},
+			params: []string{"-b"},
+		},
+		{
+			ID: testhelper.MkID("Requires - bad"),
+			constraints: []param.Constraint{
+				param.Requires("a", "b", "c"),
+			},
+			params: []string{"-a", "-b"},
+			expErrs: map[string][]string{
+				"a": {
+					`if "a" is given then "b" and "c" must also be given` +
+						` but "c" was not given`,
+					`"a" was given at: ` + cmdLineLoc + `1: "-a"`,
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("Implies - ok"),
+			constraints: []param.Constraint{
+				param.Implies("a", "name", "x"),
+			},
+			params: []string{"-a", "-name", "x"},
+		},
+		{
+			ID: testhelper.MkID("Implies - bad"),
+			constraints: []param.Constraint{
+				param.Implies("a", "name", "x"),
+			},
+			params: []string{"-a", "-name", "y"},
+			expErrs: map[string][]string{
+				"a": {
+					`if "a" is given then "name" must have the value "x"` +
+						` but its value is "y"`,
+					`"name" was given at: ` + cmdLineLoc + `3: "-name" "y"`,
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("ConflictsWith - ok"),
+			constraints: []param.Constraint{
+				param.ConflictsWith("a", "b", "c"),
+			},
+			params: []string{"-b", "-c"},
+		},
+		{
+			ID: testhelper.MkID("ConflictsWith - bad"),
+			constraints: []param.Constraint{
+				param.ConflictsWith("a", "b", "c"),
+			},
+			params: []string{"-a", "-c"},
+			expErrs: map[string][]string{
+				"a": {
+					`if "a" is given then "b" or "c" must not be given` +
+						" but 1 was given",
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("alt name"),
+			constraints: []param.Constraint{
+				param.MutuallyExclusive("a-alt", "b"),
+			},
+			params: []string{"-a", "-b"},
+			expErrs: map[string][]string{
+				"a": {`at most one of "a" or "b" may be given`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var a, b, c bool
+
+		var name string
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("a", psetter.Bool{Value: &a}, "desc", param.AltNames("a-alt"))
+		ps.Add("b", psetter.Bool{Value: &b}, "desc")
+		ps.Add("c", psetter.Bool{Value: &c}, "desc")
+		ps.Add("name", psetter.String[string]{Value: &name}, "desc")
+
+		ps.AddConstraint(tc.constraints...)
+		ps.Parse(tc.params)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+	}
+}
+
+func TestConstraintsBad(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		constraint param.Constraint
+	}{
+		{
+			ID:         testhelper.MkID("good"),
+			constraint: param.MutuallyExclusive("a", "b"),
+		},
+		{
+			ID:         testhelper.MkID("unknown name"),
+			constraint: param.MutuallyExclusive("a", "nonesuch"),
+			ExpPanic: testhelper.MkExpPanic(
+				`bad constraint (at most one of "a" or "nonesuch"`,
+				`there is no parameter named "nonesuch"`),
+		},
+		{
+			ID:         testhelper.MkID("too few names"),
+			constraint: param.Requires("a"),
+			ExpPanic: testhelper.MkExpPanic(
+				"bad constraint",
+				"it has too few parameter names (1)"),
+		},
+	}
+
+	for _, tc := range testCases {
+		var a, b bool
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("a", psetter.Bool{Value: &a}, "desc")
+		ps.Add("b", psetter.Bool{Value: &b}, "desc")
+
+		ps.AddConstraint(tc.constraint)
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Parse([]string{})
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
+
+func TestConstraintNamesUnchanged(t *testing.T) {
+	var a, b bool
+
+	c := param.MutuallyExclusive("a-alt", "b")
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.Add("a", psetter.Bool{Value: &a}, "desc", param.AltNames("a-alt"))
+	ps.Add("b", psetter.Bool{Value: &b}, "desc")
+
+	ps.AddConstraint(c)
+	ps.Parse([]string{})
+
+	const id = "constraint names unchanged"
+
+	testhelper.DiffStringSlice(t, id, "caller's names",
+		c.Names(), []string{"a-alt", "b"})
+	testhelper.DiffStringSlice(t, id, "PSet's names",
+		ps.Constraints()[0].Names(), []string{"a", "b"})
+}
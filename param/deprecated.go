@@ -0,0 +1,323 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+// deprecation records why a parameter has been deprecated and the name of
+// the parameter, if any, that should be used instead
+type deprecation struct {
+	msg         string
+	replacement string
+}
+
+// Deprecation describes a deprecated parameter name. The Msg explains why
+// the name has been deprecated and the Replacement is the name of the
+// parameter that should be used instead; it may be empty.
+type Deprecation struct {
+	Name        string
+	Msg         string
+	Replacement string
+}
+
+// Deprecated returns a ByNameOptFunc which will mark the parameter as
+// deprecated. The msg should explain why it has been deprecated. If the
+// replacement is not empty it must be the name of another parameter in the
+// same parameter set and any use of the deprecated parameter will be passed
+// on to the replacement; the deprecated parameter's own setter is then only
+// used to decide whether a value is expected and so it should take the same
+// values as the replacement.
+//
+// Whenever a deprecated parameter is used a warning is printed (see
+// SetWarningWriter) showing where it was used. The warning is only shown
+// once for each name. The parameter is not shown in the standard help
+// message, nor is it offered as a completion, and it may not have the
+// MustBeSet attribute.
+//
+// The replacement is checked when the parameters are parsed and the program
+// will panic if it is not a parameter, is the deprecated parameter, is
+// itself deprecated or, if the deprecated parameter is negatable, is not
+// negatable.
+func Deprecated(msg, replacement string) ByNameOptFunc {
+	return func(p *ByName) error {
+		p.deprecated = &deprecation{
+			msg:         msg,
+			replacement: strings.TrimSpace(replacement),
+		}
+
+		return nil
+	}
+}
+
+// DeprecatedAltNames returns a ByNameOptFunc which will attach alternative
+// names to the parameter which are deprecated. The msg should explain why
+// they have been deprecated. They can be used in the same way as the names
+// given through AltNames but a warning is printed (see SetWarningWriter)
+// when they are used. They are not shown in the standard help message, nor
+// offered as completions. If the parameter is negatable the deprecated names
+// can also be negated and a warning is printed when the negated form is
+// used. The names are registered once all the options have been applied and
+// the program will panic if any name has already been used.
+//
+// This allows a parameter to be renamed without breaking any configuration
+// files or scripts that use the old name.
+func DeprecatedAltNames(msg string, names ...string) ByNameOptFunc {
+	return func(p *ByName) error {
+		if p.deprecatedNames == nil {
+			p.deprecatedNames = map[string]string{}
+		}
+
+		for _, name := range names {
+			p.deprecatedNames[strings.TrimSpace(name)] = msg
+		}
+
+		return nil
+	}
+}
+
+// deprecatedNameList returns the deprecated alternative names of the
+// parameter in name order
+func (p ByName) deprecatedNameList() []string {
+	return slices.Sorted(maps.Keys(p.deprecatedNames))
+}
+
+// addDeprecatedNames registers the deprecated alternative names of the
+// parameter. It returns an error if any of the names has already been
+// used.
+func (ps *PSet) addDeprecatedNames(p *ByName) error {
+	for _, name := range p.deprecatedNameList() {
+		if err := ps.nameCheck(name, p.whereAdded); err != nil {
+			return err
+		}
+
+		ps.nameToParam[name] = p
+	}
+
+	return nil
+}
+
+// SetWarningWriter returns a PSetOptFunc which can be passed to [NewSet]. It
+// will set the writer to which warnings, such as those given when a
+// deprecated parameter is used, are written. By default they are written to
+// the standard error. It will return an error if the writer is nil.
+func SetWarningWriter(w io.Writer) PSetOptFunc {
+	return func(ps *PSet) error {
+		if w == nil {
+			return errors.New("the warning writer must not be nil")
+		}
+
+		ps.warnW = w
+
+		return nil
+	}
+}
+
+// IsDeprecated returns true if the parameter has been marked as deprecated.
+// See the Deprecated function.
+func (p ByName) IsDeprecated() bool {
+	return p.deprecated != nil
+}
+
+// Deprecations returns the deprecated names of the parameter in name
+// order. If the parameter is deprecated then this will include all its
+// names. Otherwise it will hold any deprecated alternative names, each of
+// which will have the parameter name as its replacement.
+func (p ByName) Deprecations() []Deprecation {
+	var deps []Deprecation
+
+	if p.deprecated != nil {
+		for _, name := range p.altNames {
+			deps = append(deps, Deprecation{
+				Name:        name,
+				Msg:         p.deprecated.msg,
+				Replacement: p.deprecated.replacement,
+			})
+		}
+	}
+
+	for name, msg := range p.deprecatedNames {
+		deps = append(deps, Deprecation{
+			Name:        name,
+			Msg:         msg,
+			Replacement: p.name,
+		})
+	}
+
+	slices.SortFunc(deps, func(a, b Deprecation) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return deps
+}
+
+// HasDeprecatedParams returns true if any of the parameters is deprecated
+// or has deprecated alternative names
+func (ps *PSet) HasDeprecatedParams() bool {
+	for _, p := range ps.byName {
+		if p.deprecated != nil || len(p.deprecatedNames) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDeprecatedName returns true if the name is a deprecated name of a
+// parameter or the negated form of one
+func (ps *PSet) isDeprecatedName(name string) bool {
+	p, ok := ps.nameToParam[name]
+	if !ok {
+		return false
+	}
+
+	if p.deprecated != nil {
+		return true
+	}
+
+	_, ok = p.deprecatedNames[p.baseName(name)]
+
+	return ok
+}
+
+// baseName returns the name with the negation prefix removed if it is one
+// of the negated names of the parameter, otherwise it returns the name
+// unchanged
+func (p ByName) baseName(name string) string {
+	if p.isNegatedName(name) {
+		return strings.TrimPrefix(name, NegationPrefix)
+	}
+
+	return name
+}
+
+// checkDeprecations panics if any deprecated parameter is MustBeSet or if
+// its replacement is not a parameter, is the deprecated parameter, is
+// itself deprecated or is not negatable when the deprecated parameter is
+func (ps *PSet) checkDeprecations() {
+	for _, p := range ps.byName {
+		if p.deprecated == nil {
+			continue
+		}
+
+		if p.AttrIsSet(MustBeSet) {
+			panic(fmt.Errorf(
+				"the deprecated parameter %q must not be MustBeSet"+
+					"\n  added at: %s",
+				p.name, p.whereAdded))
+		}
+
+		if p.deprecated.replacement == "" {
+			continue
+		}
+
+		r, ok := ps.nameToParam[p.deprecated.replacement]
+		if !ok {
+			panic(fmt.Errorf(
+				"the replacement for the deprecated parameter %q (%q)"+
+					" is not a parameter\n  added at: %s",
+				p.name, p.deprecated.replacement, p.whereAdded))
+		}
+
+		if r == p {
+			panic(fmt.Errorf(
+				"the replacement for the deprecated parameter %q (%q)"+
+					" is the parameter itself\n  added at: %s",
+				p.name, p.deprecated.replacement, p.whereAdded))
+		}
+
+		if r.deprecated != nil {
+			panic(fmt.Errorf(
+				"the replacement for the deprecated parameter %q (%q)"+
+					" is itself deprecated\n  added at: %s",
+				p.name, p.deprecated.replacement, p.whereAdded))
+		}
+
+		if p.negatable && !r.negatable {
+			panic(fmt.Errorf(
+				"the replacement for the deprecated parameter %q (%q)"+
+					" is not negatable but the parameter is\n  added at: %s",
+				p.name, p.deprecated.replacement, p.whereAdded))
+		}
+	}
+}
+
+// warnDeprecated prints a warning if the name is a deprecated name of the
+// parameter, or the negated form of one, and no warning has yet been given
+// for it. The warning is printed by the top-level PSet.
+func (p *ByName) warnDeprecated(loc *location.L, name string) {
+	msg, ok := p.deprecatedNames[p.baseName(name)]
+	replacement := p.name
+
+	if p.deprecated != nil {
+		ok = true
+		msg = p.deprecated.msg
+		replacement = p.deprecated.replacement
+	}
+
+	if !ok {
+		return
+	}
+
+	if replacement != "" && p.isNegatedName(name) {
+		replacement = NegationPrefix + replacement
+	}
+
+	ps := p.ps
+	for ps.parent != nil {
+		ps = ps.parent
+	}
+
+	if ps.deprecationsWarned[name] {
+		return
+	}
+
+	if ps.deprecationsWarned == nil {
+		ps.deprecationsWarned = map[string]bool{}
+	}
+
+	ps.deprecationsWarned[name] = true
+
+	text := fmt.Sprintf("Warning: the parameter %q is deprecated", name)
+	if msg != "" {
+		text += ": " + msg
+	}
+
+	if replacement != "" {
+		text += fmt.Sprintf(". Use %q instead", replacement)
+	}
+
+	fmt.Fprintf(ps.warnW, "%s: %s\n", ps.progBaseName, loc.Error(text))
+}
+
+// forwardDeprecated passes the parameter on to the replacement of a
+// deprecated parameter, if it has one, and returns true. It returns false
+// if the parameter is not deprecated or has no replacement.
+func (p *ByName) forwardDeprecated(loc *location.L, paramParts []string,
+) bool {
+	if p.deprecated == nil || p.deprecated.replacement == "" {
+		return false
+	}
+
+	r := p.ps.nameToParam[p.deprecated.replacement]
+
+	name := r.name
+	if p.isNegatedName(paramParts[0]) {
+		name = NegationPrefix + r.name
+	}
+
+	if r.AttrIsSet(CommandLineOnly) && loc.Note() != SrcCommandLine {
+		p.ps.recordCmdLineOnlyErr(name, loc)
+		return true
+	}
+
+	r.processParam(loc, append([]string{name}, paramParts[1:]...))
+
+	return true
+}
@@ -0,0 +1,307 @@
+package param_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// deprecatedVals holds the values of the parameters used in the deprecation
+// tests
+type deprecatedVals struct {
+	count int64
+	old   int64
+	gone  bool
+}
+
+// mkDeprecatedPSet creates a PSet having deprecated parameters, writing
+// any warnings to the buffer
+func mkDeprecatedPSet(w *bytes.Buffer, v *deprecatedVals) *param.PSet {
+	ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetWarningWriter(w))
+
+	ps.Add("count", psetter.Int[int64]{Value: &v.count}, "desc",
+		param.DeprecatedAltNames("renamed", "cnt", "counter"))
+	ps.Add("old", psetter.Int[int64]{Value: &v.old}, "desc",
+		param.Deprecated("replaced", "count"))
+	ps.Add("gone", psetter.Bool{Value: &v.gone}, "desc",
+		param.Deprecated("no longer used", ""))
+
+	return ps
+}
+
+func TestDeprecated(t *testing.T) {
+	const (
+		cfgFile = "testdata/deprecated.cfg"
+		// the program name is not known as the arguments are passed to Parse
+		warnPfx = "PROGRAM NAME UNKNOWN: "
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		args    []string
+		useCfg  bool
+		expWarn string
+		expVals deprecatedVals
+	}{
+		{
+			ID:      testhelper.MkID("not deprecated"),
+			args:    []string{"-count", "1"},
+			expVals: deprecatedVals{count: 1},
+		},
+		{
+			ID:   testhelper.MkID("deprecated alt name"),
+			args: []string{"-cnt", "2"},
+			expWarn: warnPfx +
+				"Warning: the parameter \"cnt\" is deprecated:" +
+				" renamed. Use \"count\" instead\n" +
+				"At: [command line]: Supplied Parameter:2: \"-cnt\" \"2\"\n",
+			expVals: deprecatedVals{count: 2},
+		},
+		{
+			ID:   testhelper.MkID("forwarded, warned once"),
+			args: []string{"-old", "2", "-old=3"},
+			expWarn: warnPfx +
+				"Warning: the parameter \"old\" is deprecated:" +
+				" replaced. Use \"count\" instead\n" +
+				"At: [command line]: Supplied Parameter:2: \"-old\" \"2\"\n",
+			expVals: deprecatedVals{count: 3},
+		},
+		{
+			ID:   testhelper.MkID("no replacement"),
+			args: []string{"-gone"},
+			expWarn: warnPfx +
+				"Warning: the parameter \"gone\" is deprecated:" +
+				" no longer used\n" +
+				"At: [command line]: Supplied Parameter:1: \"-gone\"\n",
+			expVals: deprecatedVals{gone: true},
+		},
+		{
+			ID:     testhelper.MkID("config file"),
+			args:   []string{},
+			useCfg: true,
+			expWarn: warnPfx +
+				"Warning: the parameter \"old\" is deprecated:" +
+				" replaced. Use \"count\" instead\n" +
+				"At: [config file]: " + cfgFile + ":1: old=3\n" +
+				warnPfx +
+				"Warning: the parameter \"counter\" is deprecated:" +
+				" renamed. Use \"count\" instead\n" +
+				"At: [config file]: " + cfgFile + ":2: counter=4\n",
+			expVals: deprecatedVals{count: 4},
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			w bytes.Buffer
+			v deprecatedVals
+		)
+
+		ps := mkDeprecatedPSet(&w, &v)
+		if tc.useCfg {
+			ps.AddConfigFile(cfgFile, filecheck.MustExist)
+		}
+
+		ps.Parse(tc.args)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+		testhelper.DiffString(t, tc.IDStr(), "warnings",
+			w.String(), tc.expWarn)
+		testhelper.DiffInt(t, tc.IDStr(), "count", v.count, tc.expVals.count)
+		testhelper.DiffInt(t, tc.IDStr(), "old", v.old, tc.expVals.old)
+		testhelper.DiffBool(t, tc.IDStr(), "gone", v.gone, tc.expVals.gone)
+	}
+}
+
+func TestDeprecatedNames(t *testing.T) {
+	var (
+		w bytes.Buffer
+		v deprecatedVals
+	)
+
+	ps := mkDeprecatedPSet(&w, &v)
+
+	const id = "deprecated names"
+
+	testhelper.DiffStringSlice(t, id, "completions",
+		ps.CompletionCandidates([]string{"-c"}), []string{"-count"})
+	testhelper.DiffStringSlice(t, id, "suggestions",
+		param.SuggestParams(ps, "cont"), []string{"count"})
+	testhelper.DiffBool(t, id, "HasDeprecatedParams",
+		ps.HasDeprecatedParams(), true)
+
+	count, err := ps.GetParamByName("count")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testhelper.DiffBool(t, id, "count: IsDeprecated", count.IsDeprecated(),
+		false)
+	testhelper.DiffStringSlice(t, id, "count: AltNames", count.AltNames(),
+		[]string{"count"})
+	testhelper.DiffSlice(t, id, "count: Deprecations", count.Deprecations(),
+		[]param.Deprecation{
+			{Name: "cnt", Msg: "renamed", Replacement: "count"},
+			{Name: "counter", Msg: "renamed", Replacement: "count"},
+		})
+
+	old, err := ps.GetParamByName("old")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testhelper.DiffBool(t, id, "old: IsDeprecated", old.IsDeprecated(), true)
+	testhelper.DiffBool(t, id, "old: DontShowInStdUsage",
+		old.AttrIsSet(param.DontShowInStdUsage), true)
+}
+
+func TestDeprecatedBadReplacement(t *testing.T) {
+	var i, j int64
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		opts []param.ByNameOptFunc
+	}{
+		{
+			ID: testhelper.MkID("good"),
+			opts: []param.ByNameOptFunc{
+				param.Deprecated("msg", "new"),
+			},
+		},
+		{
+			ID: testhelper.MkID("no such parameter"),
+			opts: []param.ByNameOptFunc{
+				param.Deprecated("msg", "nonesuch"),
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`the replacement for the deprecated parameter "old"`,
+				`("nonesuch") is not a parameter`),
+		},
+		{
+			ID: testhelper.MkID("replaced by itself"),
+			opts: []param.ByNameOptFunc{
+				param.AltNames("old-alt"),
+				param.Deprecated("msg", "old-alt"),
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`the replacement for the deprecated parameter "old"`,
+				`("old-alt") is the parameter itself`),
+		},
+		{
+			ID: testhelper.MkID("must be set"),
+			opts: []param.ByNameOptFunc{
+				param.Deprecated("msg", "new"),
+				param.Attrs(param.MustBeSet),
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`the deprecated parameter "old" must not be MustBeSet`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("new", psetter.Int[int64]{Value: &i}, "desc")
+		ps.Add("old", psetter.Int[int64]{Value: &j}, "desc", tc.opts...)
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Parse([]string{})
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
+
+func TestDeprecatedNegatable(t *testing.T) {
+	const warnPfx = "PROGRAM NAME UNKNOWN: "
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		args         []string
+		newNegatable bool
+		expWarn      string
+		expColour    bool
+		expNew       bool
+	}{
+		{
+			ID:           testhelper.MkID("negated deprecated alt name"),
+			args:         []string{"-colour", "-no-color"},
+			newNegatable: true,
+			expWarn: warnPfx +
+				"Warning: the parameter \"no-color\" is deprecated:" +
+				" renamed. Use \"no-colour\" instead\n" +
+				"At: [command line]: Supplied Parameter:2: \"-no-color\"\n",
+			expColour: false,
+		},
+		{
+			ID:           testhelper.MkID("negated deprecated param"),
+			args:         []string{"-new", "-no-old"},
+			newNegatable: true,
+			expWarn: warnPfx +
+				"Warning: the parameter \"no-old\" is deprecated:" +
+				" replaced. Use \"no-new\" instead\n" +
+				"At: [command line]: Supplied Parameter:2: \"-no-old\"\n",
+			expNew: false,
+		},
+		{
+			ID:   testhelper.MkID("replacement not negatable"),
+			args: []string{"-no-old"},
+			ExpPanic: testhelper.MkExpPanic(
+				`the replacement for the deprecated parameter "old"`,
+				`("new") is not negatable but the parameter is`),
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			w                 bytes.Buffer
+			colour, old, newB bool
+		)
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetWarningWriter(&w))
+		ps.Add("colour", psetter.Bool{Value: &colour}, "desc",
+			param.Negatable,
+			param.DeprecatedAltNames("renamed", "color"))
+
+		newOpts := []param.ByNameOptFunc{}
+		if tc.newNegatable {
+			newOpts = append(newOpts, param.Negatable)
+		}
+
+		ps.Add("new", psetter.Bool{Value: &newB}, "desc", newOpts...)
+		ps.Add("old", psetter.Bool{Value: &old}, "desc",
+			param.Negatable,
+			param.Deprecated("replaced", "new"))
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Parse(tc.args)
+		})
+		if testhelper.CheckExpPanicError(t, panicked, panicVal, tc) ||
+			panicked {
+			continue
+		}
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+		testhelper.DiffString(t, tc.IDStr(), "warnings",
+			w.String(), tc.expWarn)
+		testhelper.DiffBool(t, tc.IDStr(), "colour", colour, tc.expColour)
+		testhelper.DiffBool(t, tc.IDStr(), "new", newB, tc.expNew)
+		testhelper.DiffBool(t, tc.IDStr(), "old", old, false)
+
+		p, err := ps.GetParamByName("colour")
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "NegatedNames",
+			p.NegatedNames(), []string{"no-colour"})
+		testhelper.DiffStringSlice(t, tc.IDStr(), "completions",
+			ps.CompletionCandidates([]string{"-no-c"}),
+			[]string{"-no-colour"})
+	}
+}
@@ -0,0 +1,186 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+const (
+	interpParamPfx = "param:"
+	interpDfltSep  = ":-"
+)
+
+// SetConfigInterpolation is a PSetOptFunc which turns on the interpolation
+// of the values given in config files. By default the values are taken
+// literally. When it is set the following are replaced in the values:
+//
+//	${NAME}            the value of the environment variable NAME
+//	${NAME:-default}   as above but if NAME is unset or empty the default
+//	                   is used instead
+//	${param:name}      the value of the parameter called name which must
+//	                   have already been set
+//	~                  the user's home directory, only if it is the
+//	                   whole value or is followed by a '/' at the start
+//	                   of the value
+//	$$                 a single '$'
+//
+// Any other '$' is taken literally. It is an error to refer to an
+// environment variable which is not set (unless a default is given) or to a
+// parameter which does not exist or has not been set and the parameter
+// will not be set. The values of entries for unknown parameters are not
+// interpolated.
+//
+// Interpolation only applies to config files having the line format, not
+// the structured formats.
+func SetConfigInterpolation(ps *PSet) error {
+	ps.interpolateCfg = true
+	return nil
+}
+
+// ConfigInterpolation returns true if the values given in config files
+// will be interpolated. See the SetConfigInterpolation function.
+func (ps PSet) ConfigInterpolation() bool {
+	return ps.interpolateCfg
+}
+
+// interpolateHome replaces a leading '~' with the user's home directory.
+func interpolateHome(val string) (string, error) {
+	if val != "~" && !strings.HasPrefix(val, "~/") {
+		return val, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return val, fmt.Errorf("cannot find the home directory: %w", err)
+	}
+
+	return filepath.Join(home, val[1:]), nil
+}
+
+// interpolateRef returns the value that the reference refers to. The
+// reference is the text between the braces of a ${...} expression.
+func (ps *PSet) interpolateRef(ref string) (string, error) {
+	if pName, ok := strings.CutPrefix(ref, interpParamPfx); ok {
+		p, exists := ps.findParam(pName)
+		if !exists {
+			return "", fmt.Errorf("unknown parameter: %q", pName)
+		}
+
+		if !p.HasBeenSet() {
+			return "", fmt.Errorf("the parameter %q has not been set", pName)
+		}
+
+		return p.Setter().CurrentValue(), nil
+	}
+
+	name, dflt, hasDflt := strings.Cut(ref, interpDfltSep)
+	if name == "" {
+		return "", errors.New("the environment variable name is missing")
+	}
+
+	val, isSet := os.LookupEnv(name)
+	if hasDflt && val == "" {
+		return dflt, nil
+	}
+
+	if !isSet {
+		return "", fmt.Errorf("undefined environment variable: %q", name)
+	}
+
+	return val, nil
+}
+
+// interpolate returns the value with any environment variables, parameter
+// references and a leading '~' replaced. It returns a non-nil error if any
+// reference cannot be replaced.
+func (ps *PSet) interpolate(val string) (string, error) {
+	val, err := interpolateHome(val)
+	if err != nil {
+		return val, err
+	}
+
+	var sb strings.Builder
+
+	for {
+		before, after, found := strings.Cut(val, "$")
+		sb.WriteString(before)
+
+		if !found {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(after, "$"):
+			sb.WriteString("$")
+
+			val = after[1:]
+		case strings.HasPrefix(after, "{"):
+			ref, rest, closed := strings.Cut(after[1:], "}")
+			if !closed {
+				return "", errors.New(`missing closing '}' after "${"`)
+			}
+
+			refVal, err := ps.interpolateRef(ref)
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(refVal)
+
+			val = rest
+		default:
+			sb.WriteString("$")
+
+			val = after
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// isConfigParam returns true if the name, as given in a config file, is
+// that of a parameter. The name may refer to a parameter of a sub-command.
+// Only the values of known parameters are interpolated so that an entry for
+// an unknown parameter is reported, or marked as unused, rather than failing
+// to interpolate.
+func (ps *PSet) isConfigParam(name string) bool {
+	if scName, pName, ok := strings.Cut(name, subCmdSep); ok {
+		sc, ok := ps.subCmds[scName]
+		return ok && sc.ps.isConfigParam(pName)
+	}
+
+	_, ok := ps.findParam(name)
+
+	return ok
+}
+
+// interpolateEntry interpolates the value of the config file entry if
+// interpolation has been turned on and the entry has a value. It returns
+// false if the value cannot be interpolated, in which case the error will
+// have been recorded against the parameter.
+func (ps *PSet) interpolateEntry(cfe *configFileEntry, loc *location.L) bool {
+	if !ps.interpolateCfg || !cfe.hasParamVal {
+		return true
+	}
+
+	val, err := ps.interpolate(cfe.paramVal)
+	if err != nil {
+		ps.AddErr(cfe.paramName,
+			&BadValueError{
+				ParamErrDetails: mkParamErrDetails(cfe.paramName, loc),
+				Value:           cfe.paramVal,
+				HasValue:        true,
+				Err:             err,
+			})
+		return false
+	}
+
+	cfe.paramVal = val
+
+	return true
+}
@@ -0,0 +1,129 @@
+package param
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("PTEST_INTERP_SET", "set-val")
+	t.Setenv("PTEST_INTERP_EMPTY", "")
+	t.Setenv("PTEST_INTERP_UNSET", "")
+	_ = os.Unsetenv("PTEST_INTERP_UNSET")
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("cannot find the home directory: ", err)
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		val    string
+		expVal string
+	}{
+		{
+			ID:     testhelper.MkID("no interpolation"),
+			val:    "plain value",
+			expVal: "plain value",
+		},
+		{
+			ID:     testhelper.MkID("env var"),
+			val:    "a-${PTEST_INTERP_SET}-b",
+			expVal: "a-set-val-b",
+		},
+		{
+			ID:     testhelper.MkID("env var, default unused"),
+			val:    "${PTEST_INTERP_SET:-dflt}",
+			expVal: "set-val",
+		},
+		{
+			ID:     testhelper.MkID("env var, default for unset"),
+			val:    "${PTEST_INTERP_UNSET:-dflt}",
+			expVal: "dflt",
+		},
+		{
+			ID:     testhelper.MkID("env var, default for empty"),
+			val:    "${PTEST_INTERP_EMPTY:-dflt}",
+			expVal: "dflt",
+		},
+		{
+			ID:     testhelper.MkID("env var, empty"),
+			val:    "x${PTEST_INTERP_EMPTY}y",
+			expVal: "xy",
+		},
+		{
+			ID:     testhelper.MkID("param"),
+			val:    "/tmp/${param:num}/sub",
+			expVal: "/tmp/42/sub",
+		},
+		{
+			ID:     testhelper.MkID("home"),
+			val:    "~/dir",
+			expVal: filepath.Join(home, "dir"),
+		},
+		{
+			ID:     testhelper.MkID("home only"),
+			val:    "~",
+			expVal: home,
+		},
+		{
+			ID:     testhelper.MkID("tilde not leading"),
+			val:    "a~/dir",
+			expVal: "a~/dir",
+		},
+		{
+			ID:     testhelper.MkID("escaped and literal dollars"),
+			val:    "$$1 costs $2 $",
+			expVal: "$1 costs $2 $",
+		},
+		{
+			ID:  testhelper.MkID("unset env var"),
+			val: "${PTEST_INTERP_UNSET}",
+			ExpErr: testhelper.MkExpErr(
+				`undefined environment variable: "PTEST_INTERP_UNSET"`),
+		},
+		{
+			ID:  testhelper.MkID("missing name"),
+			val: "${:-dflt}",
+			ExpErr: testhelper.MkExpErr(
+				"the environment variable name is missing"),
+		},
+		{
+			ID:  testhelper.MkID("unclosed"),
+			val: "${PTEST_INTERP_SET",
+			ExpErr: testhelper.MkExpErr(
+				`missing closing '}' after "${"`),
+		},
+		{
+			ID:     testhelper.MkID("unknown param"),
+			val:    "${param:nonesuch}",
+			ExpErr: testhelper.MkExpErr(`unknown parameter: "nonesuch"`),
+		},
+		{
+			ID:  testhelper.MkID("param not set"),
+			val: "${param:unset}",
+			ExpErr: testhelper.MkExpErr(
+				`the parameter "unset" has not been set`),
+		},
+	}
+
+	ps := NewNoHelpNoExitNoErrRpt()
+
+	var num, unset int64
+
+	ps.Add("num", i64{Value: &num}, "desc")
+	ps.Add("unset", i64{Value: &unset}, "desc")
+	ps.getParamsFromStringSlice(location.New("test"), []string{"-num", "42"})
+
+	for _, tc := range testCases {
+		val, err := ps.interpolate(tc.val)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "value", val, tc.expVal)
+		}
+	}
+}
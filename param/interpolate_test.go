@@ -0,0 +1,90 @@
+package param_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestConfigInterpolation(t *testing.T) {
+	const cfgFile = "testdata/interpolate.cfg"
+
+	t.Setenv("PTEST_INTERP_BASE", "/base")
+	t.Setenv("PTEST_INTERP_NONESUCH", "")
+	_ = os.Unsetenv("PTEST_INTERP_NONESUCH")
+
+	testCases := []struct {
+		testhelper.ID
+		psofs     []param.PSetOptFunc
+		expErrs   map[string][]string
+		expOutdir string
+		expLogdir string
+		expCache  string
+		expUnused map[string][]string
+	}{
+		{
+			ID:        testhelper.MkID("no interpolation"),
+			expOutdir: "${PTEST_INTERP_BASE}/out",
+			expLogdir: "${param:outdir}/logs",
+			expCache:  "${PTEST_INTERP_NONESUCH}",
+			expUnused: map[string][]string{
+				"nonesuch": {
+					"[config file]: " + cfgFile +
+						":4: nonesuch = ${PTEST_INTERP_NONESUCH}",
+				},
+			},
+		},
+		{
+			ID:    testhelper.MkID("interpolation"),
+			psofs: []param.PSetOptFunc{param.SetConfigInterpolation},
+			expErrs: map[string][]string{
+				"cache": {
+					`undefined environment variable: "PTEST_INTERP_NONESUCH"`,
+					cfgFile + ":3: cache = ${PTEST_INTERP_NONESUCH}",
+				},
+			},
+			expOutdir: "/base/out",
+			expLogdir: "/base/out/logs",
+			expUnused: map[string][]string{
+				"nonesuch": {
+					"[config file]: " + cfgFile +
+						":4: nonesuch = ${PTEST_INTERP_NONESUCH}",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ps := paramset.NewNoHelpNoExitNoErrRpt(tc.psofs...)
+			ps.AddConfigFile(cfgFile, filecheck.MustExist)
+
+			var outdir, logdir, cache string
+
+			ps.Add("outdir", psetter.String[string]{Value: &outdir}, "desc")
+			ps.Add("logdir", psetter.String[string]{Value: &logdir}, "desc")
+			ps.Add("cache", psetter.String[string]{Value: &cache}, "desc")
+
+			ps.Parse([]string{})
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+			testhelper.DiffString(t, tc.IDStr(), "outdir",
+				outdir, tc.expOutdir)
+			testhelper.DiffString(t, tc.IDStr(), "logdir",
+				logdir, tc.expLogdir)
+			testhelper.DiffString(t, tc.IDStr(), "cache", cache, tc.expCache)
+
+			if err := testhelper.DiffVals(ps.UnusedParams(),
+				tc.expUnused); err != nil {
+				t.Log(tc.IDStr())
+				t.Errorf("\t: unused parameters: %s", err)
+			}
+		})
+	}
+}
@@ -5,4 +5,7 @@ const (
 	SrcCommandLine   = "command line"
 	SrcConfigFilePfx = "config file"
 	SrcEnvironment   = "environment"
+	SrcEnvFile       = "envfile"
+
+	SrcSuppliedConfigFile = "supplied config file"
 )
@@ -0,0 +1,95 @@
+package param
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// NegationPrefix is the prefix which is added to the names of a negatable
+// parameter to give the names by which it can be negated.
+const NegationPrefix = "no-"
+
+// Negatable is a ByNameOptFunc which will make the parameter negatable. A
+// negatable parameter can be given with the NegationPrefix ("no-") before
+// any of its names and the SetNegated method of the Setter will be called
+// rather than the Set method. So, for instance, a parameter called "colour"
+// can be turned off by giving "-no-colour". The negated names are
+// registered after all the other option functions have been applied, so
+// any alternative names will also have negated forms. A value cannot be
+// given with a negated name.
+//
+// It will return an error if the Setter does not implement the
+// ptypes.Negator interface (the psetter.Bool setter does), if it implements
+// the ptypes.NegationChecker interface and cannot be negated or if the
+// Setter must have a value.
+func Negatable(p *ByName) error {
+	if _, ok := p.setter.(ptypes.Negator); !ok {
+		return fmt.Errorf("the parameter cannot be negatable:"+
+			" the Setter (%T) does not implement the ptypes.Negator interface",
+			p.setter)
+	}
+
+	if nc, ok := p.setter.(ptypes.NegationChecker); ok && !nc.CanBeNegated() {
+		return fmt.Errorf("the parameter cannot be negatable:"+
+			" the Setter (%T) cannot be negated",
+			p.setter)
+	}
+
+	if p.setter.ValueReq() == Mandatory {
+		return fmt.Errorf("the parameter cannot be negatable:"+
+			" the Setter (%T) must have a value",
+			p.setter)
+	}
+
+	p.negatable = true
+
+	return nil
+}
+
+// IsNegatable returns true if the parameter can be negated
+func (p ByName) IsNegatable() bool { return p.negatable }
+
+// NegatedNames returns a copy of the names by which the parameter can be
+// negated. This will be empty unless the parameter is negatable. The
+// negated forms of any deprecated alternative names are not included.
+func (p ByName) NegatedNames() []string {
+	nn := make([]string, 0, len(p.negatedNames))
+
+	for _, name := range p.negatedNames {
+		if _, ok := p.deprecatedNames[p.baseName(name)]; !ok {
+			nn = append(nn, name)
+		}
+	}
+
+	return nn
+}
+
+// isNegatedName returns true if the name is one of the negated names of the
+// parameter
+func (p ByName) isNegatedName(name string) bool {
+	return slices.Contains(p.negatedNames, name)
+}
+
+// addNegatedNames registers the negated forms of all the names of a
+// negatable parameter. It returns an error if any of the negated names has
+// already been used.
+func (ps *PSet) addNegatedNames(p *ByName) error {
+	if !p.negatable {
+		return nil
+	}
+
+	for _, name := range append(p.AltNames(), p.deprecatedNameList()...) {
+		negName := NegationPrefix + name
+
+		if err := ps.nameCheck(negName, p.whereAdded); err != nil {
+			return err
+		}
+
+		ps.nameToParam[negName] = p
+		p.negatedNames = append(p.negatedNames, negName)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package param_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestNegatableParse(t *testing.T) {
+	const cmdLineLoc = "[command line]: Supplied Parameter:"
+
+	testCases := []struct {
+		testhelper.ID
+		params      []string
+		invert      bool
+		expErrs     map[string][]string
+		expVal      bool
+		expWhereSet []string
+	}{
+		{
+			ID:     testhelper.MkID("name"),
+			params: []string{"-colour"},
+			expVal: true,
+			expWhereSet: []string{
+				cmdLineLoc + `1: "-colour"`,
+			},
+		},
+		{
+			ID:     testhelper.MkID("negated name"),
+			params: []string{"-colour", "-no-colour"},
+			expVal: false,
+			expWhereSet: []string{
+				cmdLineLoc + `1: "-colour"`,
+				cmdLineLoc + `2: "-no-colour"`,
+			},
+		},
+		{
+			ID:     testhelper.MkID("negated alt name"),
+			params: []string{"-colour", "-no-color"},
+			expVal: false,
+			expWhereSet: []string{
+				cmdLineLoc + `1: "-colour"`,
+				cmdLineLoc + `2: "-no-color"`,
+			},
+		},
+		{
+			ID:     testhelper.MkID("negated name - inverted"),
+			params: []string{"-no-colour"},
+			invert: true,
+			expVal: true,
+			expWhereSet: []string{
+				cmdLineLoc + `1: "-no-colour"`,
+			},
+		},
+		{
+			ID:     testhelper.MkID("negated name with value"),
+			params: []string{"-no-colour=true"},
+			expErrs: map[string][]string{
+				"colour": {
+					"a value must not follow the negated parameter:" +
+						` "no-colour"`,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		var colour bool
+
+		p := ps.Add("colour", psetter.Bool{Value: &colour, Invert: tc.invert},
+			"use colour",
+			param.Negatable,
+			param.AltNames("color"))
+
+		ps.Parse(tc.params)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+		testhelper.DiffBool(t, tc.IDStr(), "colour", colour, tc.expVal)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+			p.WhereSet(), tc.expWhereSet)
+	}
+}
+
+func TestNegatableAdd(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		name   string
+		setter param.Setter
+		opts   []param.ByNameOptFunc
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			name:   "new",
+			setter: psetter.Bool{Value: new(bool)},
+			opts:   []param.ByNameOptFunc{param.Negatable},
+		},
+		{
+			ID:     testhelper.MkID("setter not a Negator"),
+			name:   "new",
+			setter: psetter.Nil{},
+			opts:   []param.ByNameOptFunc{param.Negatable},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"the Setter (psetter.Nil) does not implement"+
+					" the ptypes.Negator interface"),
+		},
+		{
+			ID:   testhelper.MkID("good - atomic"),
+			name: "new",
+			setter: psetter.Atomic[bool]{
+				Value: &psetter.AtomicVal[bool]{},
+				Setter: func(v *bool) param.Setter {
+					return psetter.Bool{Value: v}
+				},
+			},
+			opts: []param.ByNameOptFunc{param.Negatable},
+		},
+		{
+			ID:   testhelper.MkID("atomic setter not wrapping a Negator"),
+			name: "new",
+			setter: psetter.Atomic[int64]{
+				Value: &psetter.AtomicVal[int64]{},
+				Setter: func(v *int64) param.Setter {
+					return psetter.Int[int64]{Value: v}
+				},
+			},
+			opts: []param.ByNameOptFunc{param.Negatable},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"the Setter (psetter.Atomic[int64]) cannot be negated"),
+		},
+		{
+			ID:     testhelper.MkID("negated name already used"),
+			name:   "exists",
+			setter: psetter.Bool{Value: new(bool)},
+			opts:   []param.ByNameOptFunc{param.Negatable},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "exists"`,
+				`parameter name "no-exists" has already been used`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("no-exists", psetter.Nil{}, "desc")
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Add(tc.name, tc.setter, "desc", tc.opts...)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
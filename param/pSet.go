@@ -2,10 +2,13 @@ package param
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/nickwells/errutil.mod/errutil"
 	"github.com/nickwells/location.mod/location"
@@ -46,8 +49,18 @@ type PSet struct {
 	errMap         errutil.ErrMap
 	errorCount     int
 	finalChecks    []FinalCheckFunc
+	constraints    []Constraint
 	envPrefixes    []string
+	envVarToParam  map[string]*ByName
+	envFiles       []ConfigFileDetails
 	configFiles    []ConfigFileDetails
+	configDirs     []ConfigDirDetails
+	finalCfgFiles  []ConfigFileDetails
+	sourceOrder    []SourceType
+	profileParam   *ByName
+	profiles       []string
+	knownProfiles  map[string]bool
+	interpolateCfg bool
 	examples       []Example
 	references     []Reference
 	notes          map[string]*Note
@@ -55,12 +68,22 @@ type PSet struct {
 	paramPrefixes  []string
 	shortestPrefix string
 
+	shortNamesAllowed bool
+	shortNameToParam  map[rune]*ByName
+
+	abbreviationsAllowed bool
+
 	trailingParams         []string
 	terminalParam          string
 	terminalParamSeen      bool
 	trailingParamsExpected bool
 	trailingParamsName     string
 
+	parent       *PSet
+	subCmdName   string
+	subCmds      map[string]*SubCommand
+	subCmdChosen *SubCommand
+
 	helper Helper
 
 	helpRequired bool
@@ -68,6 +91,16 @@ type PSet struct {
 	exitStatus   int
 
 	parsed bool
+
+	reloadMu      *sync.Mutex
+	reloading     bool
+	reloadEntries map[*ByName][]reloadEntry
+	reloadChecks  []ReloadCheckFunc
+	reloadGen     uint64
+	watching      bool
+
+	warnW              io.Writer
+	deprecationsWarned map[string]bool
 }
 
 // PSetOptFunc is the type of a function that can be passed to
@@ -215,7 +248,24 @@ func (ps *PSet) ShortestPrefix() string {
 // there are any problems constructing the PSet then the function will panic
 // with the error.
 func NewSet(h Helper, psof ...PSetOptFunc) *PSet {
-	ps := &PSet{
+	ps := newSet(h)
+
+	h.AddParams(ps)
+
+	for _, f := range psof {
+		err := f(ps)
+		if err != nil {
+			panic(fmt.Errorf("while creating the PSet: %w", err))
+		}
+	}
+
+	return ps
+}
+
+// newSet creates a new PSet with the various internal maps and slices
+// initialised. No helper parameters are added.
+func newSet(h Helper) *PSet {
+	return &PSet{
 		parseCalledFrom: "Parse() not yet called",
 		progName:        dfltProgName,
 		progBaseName:    dfltProgName,
@@ -226,27 +276,25 @@ func NewSet(h Helper, psof ...PSetOptFunc) *PSet {
 		unusedParams:    make(map[string][]string),
 		errMap:          *(errutil.NewErrMap()),
 		finalChecks:     make([]FinalCheckFunc, 0),
+		subCmds:         make(map[string]*SubCommand),
 
-		envPrefixes: make([]string, 0, 1),
-		configFiles: make([]ConfigFileDetails, 0, 1),
+		shortNameToParam: make(map[rune]*ByName),
+
+		envPrefixes:   make([]string, 0, 1),
+		envVarToParam: make(map[string]*ByName),
+		configFiles:   make([]ConfigFileDetails, 0, 1),
+		sourceOrder:   slices.Clone(dfltSourceOrder),
 
 		terminalParam:  dfltTerminalParam,
 		paramPrefixes:  []string{"--", "-"},
 		shortestPrefix: "-",
 
 		helper: h,
-	}
 
-	h.AddParams(ps)
+		reloadMu: &sync.Mutex{},
 
-	for _, f := range psof {
-		err := f(ps)
-		if err != nil {
-			panic(fmt.Errorf("while creating the PSet: %w", err))
-		}
+		warnW: os.Stderr,
 	}
-
-	return ps
 }
 
 // TrailingParams returns any arguments that come after the terminal
@@ -254,12 +302,26 @@ func NewSet(h Helper, psof ...PSetOptFunc) *PSet {
 // [PSet.SetTrailingParamsExpected] methods.
 func (ps *PSet) TrailingParams() []string { return ps.trailingParams }
 
-// Errors returns the map of errors for the param set
-func (ps PSet) Errors() errutil.ErrMap { return ps.errMap }
+// Errors returns the map of errors for the param set. For a sub-command's
+// param set this is the error map of the top-level param set.
+func (ps PSet) Errors() errutil.ErrMap {
+	if ps.parent != nil {
+		return ps.parent.Errors()
+	}
+
+	return ps.errMap
+}
 
 // AddErr adds the errors to the named entry in the Error Map. Any nil errors
-// are filtered out of the slice and if the slice is empty no change is made
+// are filtered out of the slice and if the slice is empty no change is
+// made. For a sub-command's param set the errors are added to the Error Map
+// of the top-level param set.
 func (ps *PSet) AddErr(name string, errs ...error) {
+	if ps.parent != nil {
+		ps.parent.AddErr(name, errs...)
+		return
+	}
+
 	errs = slices.DeleteFunc(
 		errs, func(e error) bool { return e == nil })
 
@@ -324,8 +386,15 @@ func (ps *PSet) UnusedParams() map[string][]string {
 	return up
 }
 
-// markAsUnused will add the named parameter to the list of unused parameters
+// markAsUnused will add the named parameter to the list of unused
+// parameters. For a sub-command's param set the parameter is added to the
+// list of the top-level param set.
 func (ps *PSet) markAsUnused(name string, loc *location.L) {
+	if ps.parent != nil {
+		ps.parent.markAsUnused(ps.subCmdName+subCmdSep+name, loc)
+		return
+	}
+
 	ps.unusedParams[name] = append(ps.unusedParams[name], loc.String())
 }
 
@@ -333,7 +402,9 @@ func (ps *PSet) markAsUnused(name string, loc *location.L) {
 // only parameter from a non-command line source
 func (ps *PSet) recordCmdLineOnlyErr(paramName string, loc *location.L) {
 	ps.AddErr(paramName,
-		loc.Error("The parameter can only be set on the command line"))
+		&CommandLineOnlyError{
+			ParamErrDetails: mkParamErrDetails(paramName, loc),
+		})
 }
 
 // cleanParamParts removes unwanted parts of the paramParts
@@ -351,14 +422,11 @@ func cleanParamParts(p *ByName, paramParts []string) []string {
 // of this program and if a close match is found it will suggest that
 // alternative in the error message
 func (ps *PSet) recordUnexpectedParam(paramName string, loc *location.L) {
-	msg := "this is not a parameter of this program."
-
-	altNames := SuggestParams(ps, paramName)
-	if len(altNames) != 0 {
-		msg += "\n\nDid you mean:\n   " + strings.Join(altNames, "\n   ")
-	}
-
-	ps.AddErr(paramName, loc.Error(msg))
+	ps.AddErr(paramName,
+		&UnknownParamError{
+			ParamErrDetails: mkParamErrDetails(paramName, loc),
+			Suggestions:     SuggestParams(ps, paramName),
+		})
 }
 
 type existenceRule int
@@ -372,7 +440,13 @@ func (ps *PSet) setValue(
 	paramParts []string, loc *location.L, eRule existenceRule, gName string,
 ) {
 	paramName := paramParts[0]
-	p, exists := ps.nameToParam[paramName]
+
+	if scName, pName, ok := strings.Cut(paramName, subCmdSep); ok {
+		ps.setSubCmdValue(scName, pName, paramParts, loc, eRule, gName)
+		return
+	}
+
+	p, exists := ps.findParam(paramName)
 
 	if !exists {
 		if eRule == paramMustExist {
@@ -385,8 +459,11 @@ func (ps *PSet) setValue(
 	}
 
 	if gName != "" && p.groupName != gName {
-		ps.errMap[paramName] = append(ps.errMap[paramName],
-			loc.Error("this parameter is not a member of group: "+gName))
+		ps.AddErr(paramName,
+			&GroupMismatchError{
+				ParamErrDetails: mkParamErrDetails(paramName, loc),
+				Group:           gName,
+			})
 
 		return
 	}
@@ -541,20 +618,34 @@ func (ps PSet) GetGroupByName(name string) *Group {
 }
 
 // HasAltSources returns true if there are any alternative sources
-// (configuration files, either general or group-specific, or environment
-// variable prefixes) false otherwise
+// (configuration files, either general, group-specific or final, env files,
+// environment variable prefixes or environment variables bound to
+// parameters) false otherwise. Sources which have been disabled by
+// SetSourceOrder are not counted.
 func (ps PSet) HasAltSources() bool {
-	if ps.HasGlobalConfigFiles() {
+	if ps.SourceIsEnabled(ConfigFileSource) && ps.HasGlobalConfigFiles() {
 		return true
 	}
 
-	if ps.HasEnvPrefixes() {
+	if ps.SourceIsEnabled(EnvironmentSource) &&
+		(ps.HasEnvPrefixes() || ps.HasEnvVars()) {
 		return true
 	}
 
-	for _, g := range ps.groups {
-		if len(g.configFiles) > 0 {
-			return true
+	if ps.SourceIsEnabled(EnvFileSource) && len(ps.envFiles) > 0 {
+		return true
+	}
+
+	if ps.SourceIsEnabled(FinalConfigFileSource) &&
+		len(ps.finalCfgFiles) > 0 {
+		return true
+	}
+
+	if ps.SourceIsEnabled(GroupConfigFileSource) {
+		for _, g := range ps.groups {
+			if len(g.configFiles) > 0 {
+				return true
+			}
 		}
 	}
 
@@ -568,9 +659,9 @@ func (ps PSet) HasEnvPrefixes() bool {
 }
 
 // HasGlobalConfigFiles returns true if there are any non-group-specific
-// config files for this program, false otherwise
+// config files or config directories for this program, false otherwise
 func (ps PSet) HasGlobalConfigFiles() bool {
-	return len(ps.configFiles) > 0
+	return len(ps.configFiles) > 0 || len(ps.configDirs) > 0
 }
 
 // FindMatchingNamedParams returns a, possibly empty, slice of parameter
@@ -644,6 +735,10 @@ func (ps *PSet) ShouldExit() {
 	ps.shouldExit = true
 }
 
+// ExitStatus returns the status with which PSet.Parse will exit if it
+// should exit. See the SetExitStatus method.
+func (ps *PSet) ExitStatus() int { return ps.exitStatus }
+
 // SetExitStatus sets the exitStatus to the supplied value (if it is greater
 // than the prior status) and sets the shouldExit flag which will cause
 // PSet.Parse to exit.
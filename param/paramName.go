@@ -39,7 +39,7 @@ func (ps *PSet) nameCheck(name, whereAdded string) error {
 			whereAdded, err)
 	}
 
-	altP, exists := ps.nameToParam[name]
+	altP, exists := ps.findParam(name)
 	if exists {
 		errDesc := fmt.Sprintf("parameter name %q has already been used", name)
 		if altP.altNames[0] != name {
@@ -54,5 +54,46 @@ func (ps *PSet) nameCheck(name, whereAdded string) error {
 		return errors.New(errDesc)
 	}
 
+	if r := []rune(name); len(r) == 1 {
+		if altP, exists := ps.findShortName(r[0]); exists {
+			return fmt.Errorf("parameter name %q has already been used"+
+				" as the short name of %q"+
+				"\n  this param added at: %s"+
+				"\n  originally added at: %s",
+				name, altP.name, whereAdded, altP.whereAdded)
+		}
+	}
+
+	return ps.subCmdNameCheck(name, whereAdded)
+}
+
+// subCmdNameCheck returns an error if the name has already been used as a
+// name or short name of a parameter of any of the sub-commands. A parameter
+// of the parent would hide the sub-command's parameter.
+func (ps *PSet) subCmdNameCheck(name, whereAdded string) error {
+	r := []rune(name)
+
+	for _, sc := range ps.descendantSubCmds() {
+		if altP, exists := sc.ps.nameToParam[name]; exists {
+			return fmt.Errorf("parameter name %q has already been used"+
+				" by the sub-command %q"+
+				"\n  this param added at: %s"+
+				"\n  originally added at: %s",
+				name, sc.name, whereAdded, altP.whereAdded)
+		}
+
+		if len(r) != 1 {
+			continue
+		}
+
+		if altP, exists := sc.ps.shortNameToParam[r[0]]; exists {
+			return fmt.Errorf("parameter name %q has already been used"+
+				" as the short name of %q by the sub-command %q"+
+				"\n  this param added at: %s"+
+				"\n  originally added at: %s",
+				name, altP.name, sc.name, whereAdded, altP.whereAdded)
+		}
+	}
+
 	return nil
 }
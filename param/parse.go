@@ -1,7 +1,6 @@
 package param
 
 import (
-	"errors"
 	"fmt"
 	"maps"
 	"os"
@@ -14,13 +13,28 @@ import (
 
 // Parse will initialise the parameter values
 //
+// It takes the values from each of the sources of parameter values in
+// turn. By default the order is as follows.
+//
 // It will first look in the configuration files (if any filenames have been
-// set using the SetConfigFile function).
+// set using the SetConfigFile function), the group configuration files
+// being read before the common ones.
+//
+// Next it will read any env files (if any have been added using the
+// AddEnvFile function).
+//
+// Then it will look in the environment (if any parameters have been bound
+// to environment variables using the EnvVar function or any environment
+// prefix strings have been set using the SetEnvPrefix function).
+//
+// Then it will process the command line arguments.
 //
-// Next it will look in the environment (if any environment prefix strings
-// have been set using the SetEnvPrefix function).
+// Lastly it will read any final configuration files (if any have been
+// added using the AddFinalConfigFile function).
 //
-// Lastly it will process the command line arguments.
+// The order can be changed, and sources can be disabled, using the
+// SetSourceOrder function. Values set by later sources will replace those
+// set by earlier ones.
 //
 // It takes zero or more arguments each of which is a slice of strings. If no
 // arguments are given then it uses the command line arguments (excluding the
@@ -28,6 +42,8 @@ import (
 // then all the slices are concatenated together and the result is parsed in
 // place of the command line arguments.
 //
+// Once all the sources have been processed any final checks are run.
+//
 // Before any further processing the helper's ProcessArgs method is
 // called. This is expected to act on any helper parameters and to report any
 // errors.
@@ -57,15 +73,15 @@ func (ps *PSet) Parse(args ...[]string) {
 
 	ps.checkForTerminalParams()
 	ps.checkSeeRefs()
+	ps.checkDeprecations()
+	ps.checkConstraints()
+	ps.prepareSubCommands()
 
 	if len(args) == 0 {
 		ps.progName = os.Args[0]
 		ps.progBaseName = filepath.Base(ps.progName)
 	}
 
-	ps.getParamsFromConfigFiles()
-	ps.getParamsFromEnvironment()
-
 	var loc *location.L
 
 	var suppliedParams []string
@@ -84,7 +100,28 @@ func (ps *PSet) Parse(args ...[]string) {
 		}
 	}
 
-	ps.ParamParse(loc, suppliedParams)
+	ps.findProfiles(suppliedParams)
+
+	for _, st := range ps.sourceOrder {
+		switch st {
+		case GroupConfigFileSource:
+			ps.getParamsFromGroupConfigFiles()
+		case ConfigFileSource:
+			ps.getParamsFromConfigFiles()
+		case EnvFileSource:
+			ps.getParamsFromEnvFiles()
+		case EnvironmentSource:
+			ps.getParamsFromEnvironment()
+		case CommandLineSource:
+			ps.getParamsFromStringSlice(loc, suppliedParams)
+		case FinalConfigFileSource:
+			ps.getParamsFromFinalConfigFiles()
+		}
+	}
+
+	ps.checkProfiles()
+
+	ps.runPostParseChecks()
 
 	ps.reportUnexpectedTrailingParams()
 
@@ -107,10 +144,29 @@ func (ps *PSet) Parse(args ...[]string) {
 // any final checks. It is not expected that most users would want to use
 // this method.
 func (ps *PSet) ParamParse(loc *location.L, params []string) {
+	ps.findProfiles(params)
 	ps.getParamsFromStringSlice(loc, params)
 
+	ps.runPostParseChecks()
+}
+
+// runPostParseChecks performs the checks which can only be made once all
+// the parameter values have been set. The checks are then made for the
+// chosen sub-command, if any.
+func (ps *PSet) runPostParseChecks() {
+	ps.detectMissingSubCommand()
 	ps.detectMandatoryParamsNotSet()
+	ps.checkConstraintsAreMet()
+	ps.runFinalChecks()
+
+	if sc := ps.subCmdChosen; sc != nil {
+		sc.ps.runPostParseChecks()
+	}
+}
 
+// runFinalChecks calls each of the final check functions in turn and
+// records any errors returned
+func (ps *PSet) runFinalChecks() {
 	for _, fcf := range ps.finalChecks {
 		err := fcf()
 		if err != nil {
@@ -145,9 +201,8 @@ func caller() string {
 func (ps *PSet) detectMandatoryParamsNotSet() {
 	for _, p := range ps.byName {
 		if p.AttrIsSet(MustBeSet) &&
-			len(p.whereIsParamSet) == 0 {
-			ps.AddErr(p.name,
-				errors.New("this parameter must be set somewhere"))
+			len(p.whereSetToCheck()) == 0 {
+			ps.AddErr(p.name, &MissingMandatoryError{Name: p.name})
 		}
 	}
 }
@@ -156,7 +211,7 @@ func (ps *PSet) detectMandatoryParamsNotSet() {
 // PSet named params or positional params.
 func (ps *PSet) checkRefParamExists(from, ref, refSrc string) {
 	var paramExists bool
-	if _, paramExists = ps.nameToParam[ref]; !paramExists {
+	if _, paramExists = ps.findParam(ref); !paramExists {
 		_, paramExists = ps.nameToPosParam[ref]
 	}
 
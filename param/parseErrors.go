@@ -0,0 +1,275 @@
+package param
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/location.mod/location"
+)
+
+// ParamErrDetails holds the details common to the errors found where a
+// parameter was given. The Name is the name of the parameter as given, the
+// Loc records where it was given and the Source is the source of the
+// parameter value (one of the Src... values such as SrcCommandLine). The
+// errors reporting that something was not given (MissingMandatoryError,
+// MissingPositionalError and MissingSubCommandError) have no location and
+// so do not hold these details.
+//
+// The errors recorded in the PSet's error map (see PSet.Errors) will be one
+// of the error types below where appropriate and so errors.As can be used
+// to find specific errors. For instance:
+//
+//	var bve *param.BadValueError
+//	if errors.As(err, &bve) {
+//	    ...
+//	}
+type ParamErrDetails struct {
+	Name   string
+	Loc    location.L
+	Source string
+}
+
+// mkParamErrDetails returns a ParamErrDetails for the named parameter at
+// the given location
+func mkParamErrDetails(name string, loc *location.L) ParamErrDetails {
+	return ParamErrDetails{
+		Name:   name,
+		Loc:    *loc,
+		Source: loc.Note(),
+	}
+}
+
+// Details returns the details of the error. As it is promoted to each of
+// the error types below it can be used to find the details of any of them,
+// for instance:
+//
+//	var d interface{ Details() param.ParamErrDetails }
+//	if errors.As(err, &d) {
+//	    src := d.Details().Source
+//	    ...
+//	}
+func (d ParamErrDetails) Details() ParamErrDetails {
+	return d
+}
+
+// errorText returns the message followed by the location
+func (d ParamErrDetails) errorText(msg string) string {
+	return d.Loc.Error(msg).Error()
+}
+
+// UnknownParamError records that a parameter was given which is not a
+// parameter of the program. The Suggestions are the names of any
+// parameters with a name close to that given. ShortName will be true if the
+// parameter was given as a short name. Ambiguous will be true if the name
+// was an abbreviation of more than one parameter, in which case the
+// Suggestions are the names it could be an abbreviation of. If the
+// parameter did not start with any of the parameter prefixes then the
+// Prefixes will hold them.
+type UnknownParamError struct {
+	ParamErrDetails
+	Suggestions []string
+	ShortName   bool
+	Ambiguous   bool
+	Prefixes    []string
+}
+
+// Error returns the error message
+func (e *UnknownParamError) Error() string {
+	switch {
+	case e.ShortName:
+		return e.errorText(fmt.Sprintf(
+			"%q is not the short name of any parameter of this program",
+			[]rune(e.Name)[0]))
+	case len(e.Prefixes) > 0:
+		return e.errorText(fmt.Sprintf("parameter %q does not start with %s",
+			e.Name, english.JoinQuoted(e.Prefixes, ", ", " or ")))
+	case e.Ambiguous:
+		return e.errorText(fmt.Sprintf(
+			"the abbreviation %q is ambiguous, it could be any of: %s",
+			e.Name, english.JoinQuoted(e.Suggestions, ", ", " or ")))
+	}
+
+	msg := "this is not a parameter of this program."
+
+	if len(e.Suggestions) != 0 {
+		msg += "\n\nDid you mean:\n   " + strings.Join(e.Suggestions, "\n   ")
+	}
+
+	return e.errorText(msg)
+}
+
+// MissingValueError records that a parameter which must be followed by a
+// value was given without one. Err is the error returned by the setter.
+type MissingValueError struct {
+	ParamErrDetails
+	Err error
+}
+
+// Error returns the error message
+func (e *MissingValueError) Error() string {
+	return e.errorText(e.Err.Error())
+}
+
+// Unwrap returns the error returned by the setter
+func (e *MissingValueError) Unwrap() error {
+	return e.Err
+}
+
+// BadValueError records that the parameter could not be set. Err is the
+// error returned by the setter. Value is the value given, HasValue will be
+// false if no value was given.
+type BadValueError struct {
+	ParamErrDetails
+	Value    string
+	HasValue bool
+	Err      error
+}
+
+// Error returns the error message
+func (e *BadValueError) Error() string {
+	return e.errorText(e.Err.Error())
+}
+
+// Unwrap returns the error returned by the setter
+func (e *BadValueError) Unwrap() error {
+	return e.Err
+}
+
+// SetOnlyOnceError records that a parameter with the SetOnlyOnce attribute
+// was given after it had already been set. PrevLoc records where it was
+// first set.
+type SetOnlyOnceError struct {
+	ParamErrDetails
+	PrevLoc string
+}
+
+// Error returns the error message
+func (e *SetOnlyOnceError) Error() string {
+	return e.errorText(
+		"This may only be set once but has already been set at " + e.PrevLoc)
+}
+
+// CommandLineOnlyError records that a parameter with the CommandLineOnly
+// attribute was given somewhere other than the command line.
+type CommandLineOnlyError struct {
+	ParamErrDetails
+}
+
+// Error returns the error message
+func (e *CommandLineOnlyError) Error() string {
+	return e.errorText("The parameter can only be set on the command line")
+}
+
+// PostActionError records that one of the actions to be performed after the
+// parameter has been set returned an error. Err is the error returned by
+// the action.
+type PostActionError struct {
+	ParamErrDetails
+	Err error
+}
+
+// Error returns the error message
+func (e *PostActionError) Error() string {
+	return e.errorText(e.Err.Error())
+}
+
+// Unwrap returns the error returned by the action
+func (e *PostActionError) Unwrap() error {
+	return e.Err
+}
+
+// BadLineError records that a line in a file of parameters, such as an env
+// file or the profile section header of a config file, could not be
+// understood. Err describes the problem. The Name is empty as no parameter
+// could be found on the line.
+type BadLineError struct {
+	ParamErrDetails
+	Err error
+}
+
+// Error returns the error message
+func (e *BadLineError) Error() string {
+	return e.errorText(e.Err.Error())
+}
+
+// Unwrap returns the error describing the problem
+func (e *BadLineError) Unwrap() error {
+	return e.Err
+}
+
+// GroupMismatchError records that a parameter was given in the config file
+// for a parameter group but it is not a member of that group.
+type GroupMismatchError struct {
+	ParamErrDetails
+	Group string
+}
+
+// Error returns the error message
+func (e *GroupMismatchError) Error() string {
+	return e.errorText("this parameter is not a member of group: " + e.Group)
+}
+
+// MissingMandatoryError records that a parameter with the MustBeSet
+// attribute has not been set. As the parameter was not given it has no
+// location.
+type MissingMandatoryError struct {
+	Name string
+}
+
+// Error returns the error message
+func (e *MissingMandatoryError) Error() string {
+	return "this parameter must be set somewhere"
+}
+
+// MissingPositionalError records that fewer positional parameters were
+// given than are needed. Count is the number of positional parameters that
+// are missing and Desc describes the positional parameters.
+type MissingPositionalError struct {
+	Count int
+	Desc  string
+}
+
+// Error returns the error message
+func (e *MissingPositionalError) Error() string {
+	if e.Count == 1 {
+		return "a parameter is missing," +
+			" one more positional parameter is needed. " + e.Desc
+	}
+
+	return fmt.Sprintf("some parameters are missing,"+
+		" %d more positional parameters are needed. %s",
+		e.Count, e.Desc)
+}
+
+// MissingSubCommandError records that the parameter set has sub-commands
+// but none was given. The SubCommands are the names of the sub-commands
+// that could have been given.
+type MissingSubCommandError struct {
+	SubCommands []string
+}
+
+// Error returns the error message
+func (e *MissingSubCommandError) Error() string {
+	return "a sub-command must be given, one of: " +
+		english.JoinQuoted(e.SubCommands, ", ", " or ")
+}
+
+// setErr returns the error to record when the setter of the parameter
+// returns an error
+func (p *ByName) setErr(loc *location.L, paramParts []string, err error,
+) error {
+	d := mkParamErrDetails(p.name, loc)
+
+	if len(paramParts) == 1 && p.setter.ValueReq() == Mandatory {
+		return &MissingValueError{ParamErrDetails: d, Err: err}
+	}
+
+	bve := &BadValueError{ParamErrDetails: d, Err: err}
+	if len(paramParts) > 1 {
+		bve.Value = paramParts[1]
+		bve.HasValue = true
+	}
+
+	return bve
+}
@@ -0,0 +1,318 @@
+package param_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// findErr returns the first error recorded against the named parameter
+// which matches the target, as for errors.As
+func findErr(ps *param.PSet, name string, target any) bool {
+	for _, err := range ps.Errors()[name] {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestParseErrors(t *testing.T) {
+	ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetShortNamesAllowed)
+	ps.AddConfigFile("testdata/parseErrors.cfg", filecheck.MustExist)
+	ps.AddGroup("grp", "a group")
+	ps.AddGroupConfigFile("grp", "testdata/parseErrors-group.cfg",
+		filecheck.MustExist)
+
+	var (
+		count, once, cl, other, must int64
+		flag                         bool
+	)
+
+	ps.Add("count", psetter.Int[int64]{Value: &count}, "desc",
+		param.GroupName("grp"))
+	ps.Add("once", psetter.Int[int64]{Value: &once}, "desc",
+		param.Attrs(param.SetOnlyOnce))
+	ps.Add("cl", psetter.Int[int64]{Value: &cl}, "desc",
+		param.Attrs(param.CommandLineOnly))
+	ps.Add("other", psetter.Int[int64]{Value: &other}, "desc")
+	ps.Add("must", psetter.Int[int64]{Value: &must}, "desc",
+		param.Attrs(param.MustBeSet))
+	ps.Add("flag", psetter.Bool{Value: &flag}, "desc",
+		param.ShortName('f'))
+
+	ps.Parse([]string{
+		"-cont", "1",
+		"-count=x",
+		"-once=1", "-once=2",
+		"-fz",
+		"-flag=maybe",
+		"-other",
+	})
+
+	const id = "parse errors"
+
+	var upe *param.UnknownParamError
+	if findErr(ps, "cont", &upe) {
+		testhelper.DiffString(t, id, "unknown param: name", upe.Name, "cont")
+		testhelper.DiffString(t, id, "unknown param: source",
+			upe.Source, param.SrcCommandLine)
+		testhelper.DiffStringSlice(t, id, "unknown param: suggestions",
+			upe.Suggestions, []string{"count"})
+	} else {
+		t.Error(id + ": no UnknownParamError for cont")
+	}
+
+	if findErr(ps, "z", &upe) {
+		testhelper.DiffBool(t, id, "unknown short name", upe.ShortName, true)
+	} else {
+		t.Error(id + ": no UnknownParamError for the short name z")
+	}
+
+	var bve *param.BadValueError
+	if findErr(ps, "count", &bve) {
+		testhelper.DiffString(t, id, "bad value: value", bve.Value, "x")
+		testhelper.DiffBool(t, id, "bad value: has value", bve.HasValue, true)
+
+		if bve.Unwrap() == nil {
+			t.Error(id + ": the BadValueError should wrap the setter error")
+		}
+	} else {
+		t.Error(id + ": no BadValueError for count")
+	}
+
+	if !findErr(ps, "flag", &bve) {
+		t.Error(id + ": no BadValueError for flag")
+	}
+
+	var sooe *param.SetOnlyOnceError
+	if findErr(ps, "once", &sooe) {
+		testhelper.DiffString(t, id, "set only once: previous location",
+			sooe.PrevLoc, `[command line]: Supplied Parameter:4: "-once=1"`)
+	} else {
+		t.Error(id + ": no SetOnlyOnceError for once")
+	}
+
+	var mve *param.MissingValueError
+	if !findErr(ps, "other", &mve) {
+		t.Error(id + ": no MissingValueError for other")
+	}
+
+	var cloe *param.CommandLineOnlyError
+	if findErr(ps, "cl", &cloe) {
+		testhelper.DiffString(t, id, "command line only: source",
+			cloe.Source, param.SrcConfigFilePfx)
+	} else {
+		t.Error(id + ": no CommandLineOnlyError for cl")
+	}
+
+	var gme *param.GroupMismatchError
+	if findErr(ps, "other", &gme) {
+		testhelper.DiffString(t, id, "group mismatch: group", gme.Group, "grp")
+	} else {
+		t.Error(id + ": no GroupMismatchError for other")
+	}
+
+	var mme *param.MissingMandatoryError
+	if findErr(ps, "must", &mme) {
+		testhelper.DiffString(t, id, "missing mandatory: name",
+			mme.Name, "must")
+	} else {
+		t.Error(id + ": no MissingMandatoryError for must")
+	}
+}
+
+func TestParseErrorsTyped(t *testing.T) {
+	const id = "typed parse errors"
+
+	var (
+		count, counter, pos int64
+		actionErr           = errors.New("action failed")
+	)
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt(
+		param.SetParamPrefixes("-", "--"),
+		param.SetAbbreviationsAllowed)
+	ps.AddByPos("pos", psetter.Int[int64]{Value: &pos}, "desc")
+	ps.Add("count", psetter.Int[int64]{Value: &count}, "desc",
+		param.PostAction(
+			func(_ location.L, _ *param.BaseParam, _ []string) error {
+				return actionErr
+			}))
+	ps.Add("counter", psetter.Int[int64]{Value: &counter}, "desc")
+
+	ps.Parse([]string{"1", "bad", "-coun=1", "-count=2"})
+
+	var upe *param.UnknownParamError
+	if findErr(ps, "bad", &upe) {
+		testhelper.DiffStringSlice(t, id, "bad prefix: prefixes",
+			upe.Prefixes, []string{"--", "-"})
+		testhelper.DiffString(t, id, "bad prefix: message", upe.Error(),
+			`parameter "bad" does not start with "--" or "-"`+"\n"+
+				`At: [command line]: Supplied Parameter:2: "bad"`)
+	} else {
+		t.Error(id + ": no UnknownParamError for bad")
+	}
+
+	if findErr(ps, "coun", &upe) {
+		testhelper.DiffBool(t, id, "ambiguous", upe.Ambiguous, true)
+		testhelper.DiffStringSlice(t, id, "ambiguous: suggestions",
+			upe.Suggestions, []string{"count", "counter"})
+	} else {
+		t.Error(id + ": no UnknownParamError for coun")
+	}
+
+	var pae *param.PostActionError
+	if findErr(ps, "count", &pae) {
+		testhelper.DiffString(t, id, "post action: source",
+			pae.Source, param.SrcCommandLine)
+
+		if !errors.Is(pae, actionErr) {
+			t.Error(id + ": the PostActionError should wrap the action error")
+		}
+	} else {
+		t.Error(id + ": no PostActionError for count")
+	}
+
+	ps = paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddByPos("pos", psetter.Int[int64]{Value: &pos}, "desc")
+	ps.Parse([]string{})
+
+	var mpe *param.MissingPositionalError
+	if findErr(ps, "", &mpe) {
+		testhelper.DiffInt(t, id, "missing positional: count", mpe.Count, 1)
+	} else {
+		t.Error(id + ": no MissingPositionalError")
+	}
+
+	ps = paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddSubCommand("build", "desc", func(_ *param.PSet) {})
+	ps.AddSubCommand("test", "desc", func(_ *param.PSet) {})
+	ps.Parse([]string{})
+
+	var mse *param.MissingSubCommandError
+	if findErr(ps, "", &mse) {
+		testhelper.DiffStringSlice(t, id, "missing sub-command",
+			mse.SubCommands, []string{"build", "test"})
+	} else {
+		t.Error(id + ": no MissingSubCommandError")
+	}
+}
+
+func TestParseErrorsPositional(t *testing.T) {
+	const id = "positional parse errors"
+
+	var (
+		pos       int64
+		actionErr = errors.New("action failed")
+	)
+
+	posName := "Positional parameter: 1 (pos)"
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddByPos("pos", psetter.Int[int64]{Value: &pos}, "desc")
+	ps.Parse([]string{"nonesuch"})
+
+	var bve *param.BadValueError
+	if findErr(ps, posName, &bve) {
+		testhelper.DiffString(t, id, "bad value: value", bve.Value, "nonesuch")
+		testhelper.DiffString(t, id, "bad value: source",
+			bve.Source, param.SrcCommandLine)
+	} else {
+		t.Error(id + ": no BadValueError for pos")
+	}
+
+	ps = paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddByPos("pos", psetter.Int[int64]{Value: &pos}, "desc",
+		param.ByPosPostAction(
+			func(_ location.L, _ *param.BaseParam, _ []string) error {
+				return actionErr
+			}))
+	ps.Parse([]string{"1"})
+
+	var pae *param.PostActionError
+	if findErr(ps, "pos", &pae) {
+		testhelper.DiffString(t, id, "post action: name", pae.Name, "pos")
+		testhelper.DiffString(t, id, "post action: source",
+			pae.Source, param.SrcCommandLine)
+
+		if !errors.Is(pae, actionErr) {
+			t.Error(id + ": the PostActionError should wrap the action error")
+		}
+	} else {
+		t.Error(id + ": no PostActionError for pos")
+	}
+}
+
+func TestParseErrorsFileLines(t *testing.T) {
+	const (
+		id         = "file line parse errors"
+		envFile    = "testdata/envfile-bad.env"
+		profFile   = "testdata/profiles-bad.cfg"
+		interpFile = "testdata/interpolate.cfg"
+	)
+
+	var name, single, colour, outdir, logdir, cache string
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.SetEnvPrefix("PTEST_EF_")
+	ps.AddEnvFile(envFile, filecheck.MustExist)
+	ps.Add("name", psetter.String[string]{Value: &name}, "desc")
+	ps.Add("single", psetter.String[string]{Value: &single}, "desc")
+	ps.Parse([]string{})
+
+	var ble *param.BadLineError
+	if findErr(ps, "envfile: "+envFile, &ble) {
+		testhelper.DiffString(t, id, "env file: name", ble.Name, "")
+		testhelper.DiffString(t, id, "env file: message", ble.Error(),
+			"the line should be of the form NAME=value\n"+
+				"At: [envfile]: "+envFile+":2: no equals sign")
+	} else {
+		t.Error(id + ": no BadLineError for the env file")
+	}
+
+	ps = paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigFile(profFile, filecheck.MustExist)
+	ps.Add("name", psetter.String[string]{Value: &name}, "desc")
+	ps.Add("colour", psetter.String[string]{Value: &colour}, "desc")
+	ps.Parse([]string{})
+
+	if findErr(ps, "config file: "+profFile, &ble) {
+		testhelper.DiffString(t, id, "profile header: source",
+			ble.Source, param.SrcConfigFilePfx)
+		testhelper.DiffString(t, id, "profile header: error",
+			ble.Err.Error(),
+			"a profile section header should be of the form [name, ...]")
+	} else {
+		t.Error(id + ": no BadLineError for the profile section header")
+	}
+
+	t.Setenv("PTEST_INTERP_BASE", "/base")
+	t.Setenv("PTEST_INTERP_NONESUCH", "")
+	_ = os.Unsetenv("PTEST_INTERP_NONESUCH")
+
+	ps = paramset.NewNoHelpNoExitNoErrRpt(param.SetConfigInterpolation)
+	ps.AddConfigFile(interpFile, filecheck.MustExist)
+	ps.Add("outdir", psetter.String[string]{Value: &outdir}, "desc")
+	ps.Add("logdir", psetter.String[string]{Value: &logdir}, "desc")
+	ps.Add("cache", psetter.String[string]{Value: &cache}, "desc")
+	ps.Parse([]string{})
+
+	var bve *param.BadValueError
+	if findErr(ps, "cache", &bve) {
+		testhelper.DiffString(t, id, "interpolation: value",
+			bve.Value, "${PTEST_INTERP_NONESUCH}")
+		testhelper.DiffString(t, id, "interpolation: source",
+			bve.Source, param.SrcConfigFilePfx)
+	} else {
+		t.Error(id + ": no BadValueError for the interpolated value")
+	}
+}
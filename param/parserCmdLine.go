@@ -1,11 +1,10 @@
 package param
 
 import (
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
-	"github.com/nickwells/english.mod/english"
 	"github.com/nickwells/location.mod/location"
 )
 
@@ -46,20 +45,11 @@ func (ps *PSet) reportMissingParams(missingCount int) {
 		return
 	}
 
-	var err error
-
-	if missingCount == 1 {
-		err = errors.New("a parameter is missing," +
-			" one more positional parameter is needed. " +
-			ps.makeByPosParamDesc())
-	} else {
-		err = fmt.Errorf(
-			"some parameters are missing,"+
-				" %d more positional parameters are needed. %s",
-			missingCount, ps.makeByPosParamDesc())
-	}
-
-	ps.AddErr("", err)
+	ps.AddErr("",
+		&MissingPositionalError{
+			Count: missingCount,
+			Desc:  ps.makeByPosParamDesc(),
+		})
 }
 
 type parsingStatus int
@@ -109,22 +99,65 @@ func (ps *PSet) handleParamsByName(loc *location.L, params []string) {
 			break
 		}
 
+		if sc, ok := ps.subCmds[pStr]; ok {
+			ps.parseSubCommand(sc, loc, params[i+1:])
+			return
+		}
+
+		if ps.isShortNameBundle(pStr) {
+			i = ps.handleShortNames(loc, params, i)
+
+			if ps.terminalParamSeen {
+				break
+			}
+
+			continue
+		}
+
 		paramName, paramVal, hasParamVal := strings.Cut(pStr, "=")
 
-		trimmedParam, err := ps.trimParam(paramName)
-		if err != nil {
-			ps.AddErr(trimmedParam, loc.Error(err.Error()))
+		trimmedParam, ok := ps.trimParam(paramName)
+		if !ok {
+			ps.AddErr(trimmedParam,
+				&UnknownParamError{
+					ParamErrDetails: mkParamErrDetails(trimmedParam, loc),
+					Prefixes:        slices.Clone(ps.paramPrefixes),
+				})
+
 			continue
 		}
 
-		paramParts := append([]string{}, trimmedParam)
+		abbrevNote := ""
+
+		p, ok := ps.findParam(trimmedParam)
+		if !ok && ps.abbreviationsAllowed {
+			fullName, ambiguous := ps.matchAbbreviation(trimmedParam)
+			if ambiguous != nil {
+				ps.AddErr(trimmedParam,
+					&UnknownParamError{
+						ParamErrDetails: mkParamErrDetails(trimmedParam, loc),
+						Suggestions:     ambiguous,
+						Ambiguous:       true,
+					})
+
+				continue
+			}
+
+			if fullName != "" {
+				p, ok = ps.findParam(fullName)
+				trimmedParam = fullName
+				abbrevNote = fmt.Sprintf(" (an abbreviation of %q)", fullName)
+				loc.SetContent(fmt.Sprintf("%q", pStr) + abbrevNote)
+			}
+		}
 
-		p, ok := ps.nameToParam[trimmedParam]
 		if !ok {
 			ps.recordUnexpectedParam(trimmedParam, loc)
 			continue
 		}
 
+		paramParts := append([]string{}, trimmedParam)
+
 		if hasParamVal {
 			paramParts = append(paramParts, paramVal)
 		} else if p.setter.ValueReq() == Mandatory {
@@ -136,7 +169,8 @@ func (ps *PSet) handleParamsByName(loc *location.L, params []string) {
 				paramParts = append(paramParts, params[i])
 
 				loc.SetContent(
-					fmt.Sprintf("%q %q", paramName, paramParts[1]))
+					fmt.Sprintf("%q %q", paramName, paramParts[1]) +
+						abbrevNote)
 			}
 		}
 
@@ -178,19 +212,14 @@ func (ps *PSet) TrimPrefixesFromParam(pName string) string {
 	return pName
 }
 
-// trimParam trims the parameter of its prefix (if any). It returns an error
+// trimParam trims the parameter of its prefix (if any). It returns false
 // if the parameter does not start with any of the given prefixes.
-func (ps *PSet) trimParam(pName string) (string, error) {
+func (ps *PSet) trimParam(pName string) (string, bool) {
 	if len(ps.paramPrefixes) == 0 {
-		return pName, nil
+		return pName, true
 	}
 
 	trimmedParam := ps.TrimPrefixesFromParam(pName)
-	if trimmedParam != pName {
-		return trimmedParam, nil
-	}
 
-	return pName, fmt.Errorf("parameter %q does not start with %s",
-		pName,
-		english.JoinQuoted(ps.paramPrefixes, ", ", " or "))
+	return trimmedParam, trimmedParam != pName
 }
@@ -14,10 +14,12 @@ import (
 )
 
 // ConfigFileDetails records the details of a configuration
-// file. Specifically its name and details about whether or not it must exist
+// file. Specifically its name, details about whether or not it must exist
+// and the format of its contents
 type ConfigFileDetails struct {
 	Name         string
 	CfConstraint filecheck.Exists
+	Format       ConfigFileFormat
 	eRule        existenceRule
 }
 
@@ -35,6 +37,10 @@ func (cfd ConfigFileDetails) String() string {
 		s += " (must exist)"
 	}
 
+	if cfd.Format != ConfigFmtLine {
+		s += " (" + cfd.Format.String() + ")"
+	}
+
 	return s
 }
 
@@ -44,6 +50,7 @@ func (cfd ConfigFileDetails) String() string {
 type groupParamLineParser struct {
 	ps    *PSet
 	gName string
+	pt    profileTracker
 }
 
 // paramLineParser is a type which satisfies the LineParser interface and
@@ -51,6 +58,7 @@ type groupParamLineParser struct {
 type paramLineParser struct {
 	ps    *PSet
 	eRule existenceRule
+	pt    profileTracker
 }
 
 // cmdLineFileLineParser is a type which satisfies the LineParser interface
@@ -58,6 +66,7 @@ type paramLineParser struct {
 // paramSet member
 type cmdLineFileLineParser struct {
 	ps *PSet
+	pt profileTracker
 }
 
 // configFileEntry holds the parts of a config file line parsed out from
@@ -151,23 +160,34 @@ func splitParamSpec(spec string) ([]string, string) {
 // the parameter value from the parameter name and the value string which has
 // been stripped of any surrounding whitespace.
 //
-// Note that no error is ever returned, the return type is there purely to
-// satisfy the fileparse package interface.
+// Any profile section headers are recorded and lines in sections for
+// profiles which have not been selected are ignored. An error is only
+// returned if a section header is malformed.
 func (cllp cmdLineFileLineParser) ParseLine(line string, loc *location.L) error {
+	if skip, err := cllp.pt.skipLine(line, loc); skip {
+		return err
+	}
+
 	cfe := splitLine(line)
 
 	if cfe.ignoreForThisProgram(cllp.ps.progBaseName) {
 		return nil
 	}
 
-	loc.SetContent(line)
+	loc.SetContent(cllp.pt.content(line, loc))
 
-	if p, ok := cllp.ps.nameToParam[cfe.paramName]; ok {
-		p.processParam(loc, cfe.paramParts())
-	} else {
+	p, ok := cllp.ps.findParam(cfe.paramName)
+	if !ok {
 		cllp.ps.recordUnexpectedParam(cfe.paramName, loc)
+		return nil
 	}
 
+	if !cllp.ps.interpolateEntry(&cfe, loc) {
+		return nil
+	}
+
+	p.processParam(loc, cfe.paramParts())
+
 	return nil
 }
 
@@ -180,9 +200,14 @@ func (cllp cmdLineFileLineParser) ParseLine(line string, loc *location.L) error
 // the parameter value from the parameter name and the value string which has
 // been stripped of any surrounding whitespace.
 //
-// Note that no error is ever returned, the return type is there purely to
-// satisfy the fileparse package interface.
+// Any profile section headers are recorded and lines in sections for
+// profiles which have not been selected are ignored. An error is only
+// returned if a section header is malformed.
 func (pflp paramLineParser) ParseLine(line string, loc *location.L) error {
+	if skip, err := pflp.pt.skipLine(line, loc); skip {
+		return err
+	}
+
 	cfe := splitLine(line)
 
 	if cfe.ignoreForThisProgram(pflp.ps.progBaseName) {
@@ -194,7 +219,13 @@ func (pflp paramLineParser) ParseLine(line string, loc *location.L) error {
 		eRule = paramMustExist
 	}
 
-	loc.SetContent(line)
+	loc.SetContent(pflp.pt.content(line, loc))
+
+	if pflp.ps.isConfigParam(cfe.paramName) &&
+		!pflp.ps.interpolateEntry(&cfe, loc) {
+		return nil
+	}
+
 	pflp.ps.setValue(cfe.paramParts(), loc, eRule, "")
 
 	return nil
@@ -209,16 +240,27 @@ func (pflp paramLineParser) ParseLine(line string, loc *location.L) error {
 // the parameter value from the parameter name and the value string which has
 // been stripped of any surrounding whitespace.
 //
-// Note that no error is ever returned, the return type is there purely to
-// satisfy the fileparse package interface.
+// Any profile section headers are recorded and lines in sections for
+// profiles which have not been selected are ignored. An error is only
+// returned if a section header is malformed.
 func (gflp groupParamLineParser) ParseLine(line string, loc *location.L) error {
+	if skip, err := gflp.pt.skipLine(line, loc); skip {
+		return err
+	}
+
 	cfe := splitLine(line)
 
 	if cfe.ignoreForThisProgram(gflp.ps.progBaseName) {
 		return nil
 	}
 
-	loc.SetContent(line)
+	loc.SetContent(gflp.pt.content(line, loc))
+
+	if gflp.ps.isConfigParam(cfe.paramName) &&
+		!gflp.ps.interpolateEntry(&cfe, loc) {
+		return nil
+	}
+
 	gflp.ps.setValue(cfe.paramParts(), loc, paramMustExist, gflp.gName)
 
 	return nil
@@ -273,6 +315,14 @@ func checkExistenceConstraint(fName string, c filecheck.Exists) {
 // particular parameter group, the parameter must be recognised or else it is
 // reported as an error.
 //
+// The config file may be divided into profile sections, each introduced by
+// a line giving the profile names in square brackets, for instance:
+//
+//	[dev]
+//
+// The lines in such a section are only used if one of the profiles has been
+// selected. See the SelectsProfiles function for details.
+//
 // The config file supports the features of a file parsed by the
 // fileparse.FP such as comments and include files.
 //
@@ -422,6 +472,37 @@ func (ps *PSet) AddGroupConfigFile(gName, fName string, c filecheck.Exists) {
 		})
 }
 
+// AddFinalConfigFile adds a config file which is read after the command
+// line has been processed (unless a different order has been given with
+// SetSourceOrder). The values it sets will therefore replace any given on
+// the command line or in any other source. This can be used for
+// system-wide settings which an administrator wants to impose and which
+// the user should not be able to override. Final config files are
+// processed in the order they are added.
+//
+// The file has the same format as the files added with AddConfigFile.
+//
+// The config file must be added before the parameters are parsed; this will
+// panic otherwise.
+func (ps *PSet) AddFinalConfigFile(fName string, c filecheck.Exists) {
+	ps.panicIfAlreadyParsed(
+		fmt.Sprintf("can't add the final config file %q", fName))
+
+	checkExistenceConstraint(fName, c)
+
+	ps.finalCfgFiles = append(ps.finalCfgFiles,
+		ConfigFileDetails{
+			Name:         fName,
+			CfConstraint: c,
+			eRule:        paramNeedNotExist,
+		})
+}
+
+// FinalConfigFiles returns a copy of the current final config file details.
+func (ps *PSet) FinalConfigFiles() []ConfigFileDetails {
+	return slices.Clone(ps.finalCfgFiles)
+}
+
 // ConfigFiles returns a copy of the current config file details.
 func (ps *PSet) ConfigFiles() []ConfigFileDetails {
 	cf := make([]ConfigFileDetails, len(ps.configFiles))
@@ -463,13 +544,16 @@ func checkCFErrs(ps *PSet, errs []error, cf ConfigFileDetails, desc string) {
 	ps.AddErr(desc+": "+cf.Name, errs...)
 }
 
-// getParamsFromConfigFiles will construct a line parser and then parse the
-// config files - the group-specific config files first and then the common
-// files.
-func (ps *PSet) getParamsFromConfigFiles() {
+// getParamsFromGroupConfigFiles will construct a line parser and then parse
+// the group-specific config files.
+func (ps *PSet) getParamsFromGroupConfigFiles() {
 	for gName, g := range ps.groups {
 		desc := SrcConfigFilePfx + " for " + gName
-		fp := fileparse.New(desc, groupParamLineParser{ps: ps, gName: gName})
+		fp := fileparse.New(desc, groupParamLineParser{
+			ps:    ps,
+			gName: gName,
+			pt:    newProfileTracker(ps),
+		})
 
 		for _, cf := range g.configFiles {
 			errs := fp.Parse(cf.Name)
@@ -477,10 +561,36 @@ func (ps *PSet) getParamsFromConfigFiles() {
 			checkCFErrs(ps, errs, cf, desc)
 		}
 	}
+}
+
+// getParamsFromConfigFiles will parse the common config files and then the
+// files in any config directories.
+func (ps *PSet) getParamsFromConfigFiles() {
+	ps.parseConfigFiles(ps.configFiles, SrcConfigFilePfx)
+	ps.getParamsFromConfigDirs()
+}
 
-	for _, cf := range ps.configFiles {
-		desc := SrcConfigFilePfx
-		fp := fileparse.New(desc, paramLineParser{ps: ps, eRule: cf.eRule})
+// getParamsFromFinalConfigFiles will parse the final config files.
+func (ps *PSet) getParamsFromFinalConfigFiles() {
+	ps.parseConfigFiles(ps.finalCfgFiles, SrcConfigFilePfx+" (final)")
+}
+
+// parseConfigFiles will construct a line parser and then parse each of the
+// config files in turn, recording any errors against the description.
+func (ps *PSet) parseConfigFiles(cfs []ConfigFileDetails, desc string) {
+	for _, cf := range cfs {
+		if cf.Format != ConfigFmtLine {
+			errs := ps.parseStructuredConfigFile(cf, desc)
+			checkCFErrs(ps, errs, cf, desc)
+
+			continue
+		}
+
+		fp := fileparse.New(desc, paramLineParser{
+			ps:    ps,
+			eRule: cf.eRule,
+			pt:    newProfileTracker(ps),
+		})
 		errs := fp.Parse(cf.Name)
 		checkCFErrs(ps, errs, cf, desc)
 	}
@@ -496,10 +606,13 @@ func ConfigFileActionFunc(_ location.L, p *BaseParam, paramVals []string) error
 	}
 
 	name := paramVals[1]
-	desc := "supplied config file"
+	desc := SrcSuppliedConfigFile
 
 	cf := ConfigFileDetails{Name: name, CfConstraint: filecheck.MustExist}
-	fp := fileparse.New(desc, cmdLineFileLineParser{ps: p.ps})
+	fp := fileparse.New(desc, cmdLineFileLineParser{
+		ps: p.ps,
+		pt: newProfileTracker(p.ps),
+	})
 	errs := fp.Parse(cf.Name)
 	checkCFErrs(p.ps, errs, cf, desc)
 
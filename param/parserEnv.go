@@ -78,6 +78,78 @@ func (ps *PSet) EnvPrefixes() []string {
 	return ep
 }
 
+// checkEnvVarName checks the environment variable name and returns a
+// non-nil error if there are any problems.
+func checkEnvVarName(name string) error {
+	if name == "" {
+		return errors.New("the environment variable name must not be empty")
+	}
+
+	if strings.ContainsAny(name, "= \t\n") {
+		return fmt.Errorf("bad environment variable name: %q:"+
+			" it must not contain '=' or any white space", name)
+	}
+
+	return nil
+}
+
+// EnvVar returns a ByNameOptFunc which will bind the parameter to the named
+// environment variables. Unlike the environment variables recognised
+// through the environment prefixes (see AddEnvPrefix) these names are used
+// exactly as given; no prefix is needed and no conversion of the name is
+// performed. This lets the program honour standard environment variables
+// such as EDITOR or NO_COLOR.
+//
+// If more than one name is given then the first of them that is set in the
+// environment is used. An environment variable which is set to the empty
+// string is ignored. If the parameter does not take a value then it is set
+// whenever the environment variable is set to a non-empty value, whatever
+// that value is; this is how NO_COLOR is used. The bound environment
+// variables are processed before those having an environment prefix and so
+// a parameter set through a prefixed environment variable will take
+// precedence. As with any other
+// environment variable, a value given on the command line will take
+// precedence.
+//
+// It will return an error if any name is empty or contains an '=' or any
+// white space or if it has already been bound to a parameter. It will also
+// return an error if the parameter belongs to a sub-command; the
+// environment is read before the sub-command is known. Add will panic if
+// the parameter also has the CommandLineOnly attribute.
+func EnvVar(names ...string) ByNameOptFunc {
+	return func(p *ByName) error {
+		if p.ps.parent != nil {
+			return fmt.Errorf("the parameter cannot be bound to"+
+				" environment variables: it belongs to the sub-command %q",
+				p.ps.subCmdName)
+		}
+
+		for _, name := range names {
+			if err := checkEnvVarName(name); err != nil {
+				return err
+			}
+
+			if other, ok := p.ps.envVarToParam[name]; ok {
+				return fmt.Errorf(
+					"the environment variable %q"+
+						" is already bound to the parameter %q",
+					name, other.name)
+			}
+
+			p.ps.envVarToParam[name] = p
+			p.envVars = append(p.envVars, name)
+		}
+
+		return nil
+	}
+}
+
+// HasEnvVars returns true if any parameter has been bound to an environment
+// variable, false otherwise. See the EnvVar function.
+func (ps PSet) HasEnvVars() bool {
+	return len(ps.envVarToParam) > 0
+}
+
 // ConvertParamNameToEnvVarName converts a parameter name to a valid
 // environment variable name. Note that in order to be recognised it will
 // need to be prefixed by a recognised environment variable prefix as
@@ -95,32 +167,93 @@ func ConvertEnvVarNameToParamName(name string) string {
 	return strings.ReplaceAll(name, "_", "-")
 }
 
-func (ps *PSet) getParamsFromEnvironment() {
-	if len(ps.envPrefixes) == 0 {
+// envEntry holds an environment variable name and value together with the
+// location where it was found
+type envEntry struct {
+	name string
+	val  string
+	loc  location.L
+}
+
+// setParamsFromEnvVars sets the values of any parameters which have been
+// bound to environment variables. Only the first of the bound environment
+// variables that is set to a non-empty value is used.
+func (ps *PSet) setParamsFromEnvVars(entries []envEntry) {
+	if len(ps.envVarToParam) == 0 {
 		return
 	}
 
-	loc := location.New("")
-	loc.SetNote(SrcEnvironment)
+	byName := make(map[string]envEntry, len(entries))
+	for _, e := range entries {
+		byName[e.name] = e
+	}
+
+	for _, p := range ps.byName {
+		for _, name := range p.envVars {
+			e, ok := byName[name]
+			if !ok || e.val == "" {
+				continue
+			}
+
+			paramParts := []string{p.name, e.val}
+			if p.setter.ValueReq() == None {
+				paramParts = paramParts[:1]
+			}
+
+			ps.setValue(paramParts, &e.loc, paramMustExist, "")
+
+			break // we've found a value so stop looking
+		}
+	}
+}
 
-	for _, param := range os.Environ() {
-		paramName, paramVal, hasParamVal := strings.Cut(param, "=")
+// setParamsFromEnvPrefixes sets the values of any parameters given by
+// environment variables having one of the environment prefixes.
+func (ps *PSet) setParamsFromEnvPrefixes(entries []envEntry) {
+	for _, e := range entries {
 		for _, envPrefix := range ps.envPrefixes {
-			trimmedParam := strings.TrimPrefix(paramName, envPrefix)
+			trimmedName := strings.TrimPrefix(e.name, envPrefix)
 			// We only process those env vars that start with the
 			// envPrefix (so trimming the prefix will change the name)
-			if trimmedParam != paramName {
-				paramParts := append([]string{},
-					ConvertEnvVarNameToParamName(trimmedParam))
-				if hasParamVal {
-					paramParts = append(paramParts, paramVal)
+			if trimmedName != e.name {
+				paramParts := []string{
+					ConvertEnvVarNameToParamName(trimmedName),
+					e.val,
 				}
 
-				loc.SetContent(param)
-				ps.setValue(paramParts, loc, paramNeedNotExist, "")
+				ps.setValue(paramParts, &e.loc, paramNeedNotExist, "")
 
 				break // we've found a match so stop looking
 			}
 		}
 	}
 }
+
+// setParamsFromEnvEntries sets the values of any parameters given by the
+// environment entries. Parameters bound to environment variables are set
+// first followed by any environment variables having one of the
+// environment prefixes.
+func (ps *PSet) setParamsFromEnvEntries(entries []envEntry) {
+	ps.setParamsFromEnvVars(entries)
+	ps.setParamsFromEnvPrefixes(entries)
+}
+
+// getParamsFromEnvironment sets the values of any parameters given through
+// the environment.
+func (ps *PSet) getParamsFromEnvironment() {
+	environ := os.Environ()
+	entries := make([]envEntry, 0, len(environ))
+
+	loc := location.New("")
+	loc.SetNote(SrcEnvironment)
+
+	for _, ev := range environ {
+		e := envEntry{loc: *loc}
+		e.name, e.val, _ = strings.Cut(ev, "=")
+		e.loc.SetContent(ev)
+
+		entries = append(entries, e)
+	}
+
+	ps.setParamsFromEnvEntries(entries)
+}
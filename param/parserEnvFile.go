@@ -0,0 +1,221 @@
+package param
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/location.mod/location"
+)
+
+// envFileExportPfx is the optional prefix on an env file line
+const envFileExportPfx = "export "
+
+// AddEnvFile adds a dotenv file from which environment variables will be
+// read. The entries in the file are treated exactly as if they had been
+// found in the environment: those with a name bound to a parameter (see
+// EnvVar) or having one of the environment prefixes (see AddEnvPrefix) are
+// used to set parameter values. Files are processed in the order they are
+// added, after any configuration files and before the environment itself so
+// that values in the environment will take precedence.
+//
+// The file name may start with ~/ to refer to the home directory of the
+// user.
+//
+// Each line of the file should be of the form:
+//
+//	[export ]NAME=value
+//
+// Blank lines and lines starting with a '#' are ignored. The value may be
+// enclosed in single quotes, in which case it is taken literally, or in
+// double quotes, in which case the escape sequences \n, \t, \", \$ and \\
+// are replaced. An unquoted value has any surrounding white space removed
+// and any comment (a '#' preceded by white space) is stripped off.
+//
+// The env file must be added before the parameters are parsed; this will
+// panic otherwise. It will also panic if the existence constraint is
+// MustNotExist.
+func (ps *PSet) AddEnvFile(fName string, c filecheck.Exists) {
+	ps.panicIfAlreadyParsed(
+		fmt.Sprintf("can't add the env file %q", fName))
+
+	if c == filecheck.MustNotExist {
+		panic(fmt.Sprintf("env file %q: bad existence constraint.", fName))
+	}
+
+	ps.envFiles = append(ps.envFiles,
+		ConfigFileDetails{
+			Name:         fName,
+			CfConstraint: c,
+			eRule:        paramNeedNotExist,
+		})
+}
+
+// EnvFiles returns a copy of the current env file details.
+func (ps *PSet) EnvFiles() []ConfigFileDetails {
+	ef := make([]ConfigFileDetails, len(ps.envFiles))
+	copy(ef, ps.envFiles)
+
+	return ef
+}
+
+// closingQuote returns the index of the quote which closes the value, whose
+// first character is the opening quote, q. Within double quotes a backslash
+// escapes the following character. It returns -1 if there is no closing
+// quote.
+func closingQuote(val string, q byte) int {
+	for i := 1; i < len(val); i++ {
+		switch val[i] {
+		case '\\':
+			if q == '"' {
+				i++
+			}
+		case q:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unquoteEnvFileVal returns the value with any quotes removed and escape
+// sequences replaced. It returns an error if the value is badly quoted.
+func unquoteEnvFileVal(val string) (string, error) {
+	if val == "" {
+		return val, nil
+	}
+
+	switch q := val[0]; q {
+	case '\'', '"':
+		end := closingQuote(val, q)
+		if end < 0 {
+			return "", fmt.Errorf("the value has no closing quote (%c)", q)
+		}
+
+		if rest := strings.TrimSpace(val[end+1:]); rest != "" &&
+			!strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf(
+				"unexpected text after the closing quote: %q", rest)
+		}
+
+		val = val[1:end]
+
+		if q == '"' {
+			val = strings.NewReplacer(
+				`\n`, "\n",
+				`\t`, "\t",
+				`\"`, `"`,
+				`\$`, `$`,
+				`\\`, `\`,
+			).Replace(val)
+		}
+
+		return val, nil
+	}
+
+	for i, r := range val {
+		if r == '#' && i > 0 && (val[i-1] == ' ' || val[i-1] == '\t') {
+			val = val[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(val), nil
+}
+
+// parseEnvFileLine splits the env file line into the name and value. It
+// returns an error if the line is not well-formed.
+func parseEnvFileLine(line string) (envEntry, error) {
+	line = strings.TrimPrefix(line, envFileExportPfx)
+
+	name, val, found := strings.Cut(line, "=")
+	if !found {
+		return envEntry{},
+			errors.New("the line should be of the form NAME=value")
+	}
+
+	name = strings.TrimSpace(name)
+	if err := checkEnvVarName(name); err != nil {
+		return envEntry{}, err
+	}
+
+	val, err := unquoteEnvFileVal(strings.TrimSpace(val))
+	if err != nil {
+		return envEntry{}, err
+	}
+
+	return envEntry{name: name, val: val}, nil
+}
+
+// readEnvFile reads the env file and returns the entries found. Any errors
+// in the file contents are added to the PSet errors. If the file cannot be
+// read then an error is returned.
+func (ps *PSet) readEnvFile(ef ConfigFileDetails, desc string,
+) ([]envEntry, error) {
+	fName, err := fileparse.FixFileName(ef.Name)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't expand: %q : %w", ef.Name, err)
+	}
+
+	f, err := os.Open(fName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []envEntry
+
+	loc := location.New(fName)
+	loc.SetNote(SrcEnvFile)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		loc.Incr()
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		loc.SetContent(line)
+
+		e, err := parseEnvFileLine(line)
+		if err != nil {
+			ps.AddErr(desc+": "+ef.Name,
+				&BadLineError{
+					ParamErrDetails: mkParamErrDetails("", loc),
+					Err:             err,
+				})
+			continue
+		}
+
+		e.loc = *loc
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
+
+// getParamsFromEnvFiles reads each of the env files in turn and sets
+// parameter values from the entries found.
+func (ps *PSet) getParamsFromEnvFiles() {
+	for _, ef := range ps.envFiles {
+		desc := SrcEnvFile
+
+		entries, err := ps.readEnvFile(ef, desc)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) ||
+				ef.CfConstraint != filecheck.Optional {
+				ps.AddErr(desc+": "+ef.Name, err)
+			}
+
+			continue
+		}
+
+		ps.setParamsFromEnvEntries(entries)
+	}
+}
@@ -0,0 +1,119 @@
+package param
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestParseEnvFileLine(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		line    string
+		expName string
+		expVal  string
+	}{
+		{
+			ID:      testhelper.MkID("simple"),
+			line:    "NAME=value",
+			expName: "NAME",
+			expVal:  "value",
+		},
+		{
+			ID:      testhelper.MkID("export prefix"),
+			line:    "export NAME=value",
+			expName: "NAME",
+			expVal:  "value",
+		},
+		{
+			ID:      testhelper.MkID("white space"),
+			line:    "NAME = value with spaces ",
+			expName: "NAME",
+			expVal:  "value with spaces",
+		},
+		{
+			ID:      testhelper.MkID("empty value"),
+			line:    "NAME=",
+			expName: "NAME",
+		},
+		{
+			ID:      testhelper.MkID("trailing comment"),
+			line:    "NAME=value # comment",
+			expName: "NAME",
+			expVal:  "value",
+		},
+		{
+			ID:      testhelper.MkID("hash in value"),
+			line:    "NAME=val#ue",
+			expName: "NAME",
+			expVal:  "val#ue",
+		},
+		{
+			ID:      testhelper.MkID("single quoted"),
+			line:    `NAME='a \n # b' # comment`,
+			expName: "NAME",
+			expVal:  `a \n # b`,
+		},
+		{
+			ID:      testhelper.MkID("double quoted"),
+			line:    `NAME="a\tb \"c\" \$d \\e\n"`,
+			expName: "NAME",
+			expVal:  "a\tb \"c\" $d \\e\n",
+		},
+		{
+			ID:      testhelper.MkID("quote in trailing comment"),
+			line:    `NAME="a" # it's "here"`,
+			expName: "NAME",
+			expVal:  "a",
+		},
+		{
+			ID:      testhelper.MkID("single quote in trailing comment"),
+			line:    `NAME='a' # it's`,
+			expName: "NAME",
+			expVal:  "a",
+		},
+		{
+			ID:      testhelper.MkID("escaped backslash before closing quote"),
+			line:    `NAME="a\\" # "b"`,
+			expName: "NAME",
+			expVal:  `a\`,
+		},
+		{
+			ID:   testhelper.MkID("no equals"),
+			line: "NAME",
+			ExpErr: testhelper.MkExpErr(
+				"the line should be of the form NAME=value"),
+		},
+		{
+			ID:   testhelper.MkID("no name"),
+			line: "=value",
+			ExpErr: testhelper.MkExpErr(
+				"the environment variable name must not be empty"),
+		},
+		{
+			ID:     testhelper.MkID("no closing quote"),
+			line:   `NAME="value`,
+			ExpErr: testhelper.MkExpErr(`the value has no closing quote (")`),
+		},
+		{
+			ID:     testhelper.MkID("escaped closing quote"),
+			line:   `NAME="value\"`,
+			ExpErr: testhelper.MkExpErr(`the value has no closing quote (")`),
+		},
+		{
+			ID:   testhelper.MkID("text after closing quote"),
+			line: `NAME='value' more`,
+			ExpErr: testhelper.MkExpErr(
+				`unexpected text after the closing quote: "more"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		e, err := parseEnvFileLine(tc.line)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "name", e.name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "value", e.val, tc.expVal)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package param_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestEnvFile(t *testing.T) {
+	const (
+		envFile     = "testdata/envfile.env"
+		badEnvFile  = "testdata/envfile-bad.env"
+		noSuchFile  = "testdata/envfile.nosuch"
+		envFileLoc  = "[envfile]: " + envFile + ":"
+		envFileDesc = "envfile: "
+		nameLine    = "export PTEST_EF_name=plain   # trailing comment"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		envFiles   []string
+		mustExist  bool
+		env        map[string]string
+		expErrs    map[string][]string
+		expName    string
+		expSingle  string
+		expDouble  string
+		expEditor  string
+		expNameSet []string
+	}{
+		{
+			ID:        testhelper.MkID("good file"),
+			envFiles:  []string{envFile},
+			expName:   "plain",
+			expSingle: "not # a comment",
+			expDouble: "line1\nline2 \"quoted\"",
+			expEditor: "ed",
+			expNameSet: []string{
+				envFileLoc + "2: " + nameLine,
+			},
+		},
+		{
+			ID:        testhelper.MkID("environment takes precedence"),
+			envFiles:  []string{envFile},
+			env:       map[string]string{"PTEST_EF_name": "from-env"},
+			expName:   "from-env",
+			expSingle: "not # a comment",
+			expDouble: "line1\nline2 \"quoted\"",
+			expEditor: "ed",
+			expNameSet: []string{
+				envFileLoc + "2: " + nameLine,
+				"[environment]: :0: PTEST_EF_name=from-env",
+			},
+		},
+		{
+			ID:       testhelper.MkID("bad file"),
+			envFiles: []string{badEnvFile},
+			expErrs: map[string][]string{
+				envFileDesc + badEnvFile: {
+					"the line should be of the form NAME=value",
+					badEnvFile + ":2: no equals sign",
+					"the value has no closing quote (')",
+					badEnvFile + ":3: PTEST_EF_single='unterminated",
+				},
+			},
+			expName: "ok",
+			expNameSet: []string{
+				"[envfile]: " + badEnvFile + ":1: PTEST_EF_name=ok",
+			},
+		},
+		{
+			ID:       testhelper.MkID("missing optional file"),
+			envFiles: []string{noSuchFile},
+		},
+		{
+			ID:        testhelper.MkID("missing mandatory file"),
+			envFiles:  []string{noSuchFile},
+			mustExist: true,
+			expErrs: map[string][]string{
+				envFileDesc + noSuchFile: {"no such file or directory"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			for _, name := range []string{
+				"PTEST_EF_name", "PTEST_EF_single",
+				"PTEST_EF_double", "PTEST_EF_EDITOR",
+			} {
+				val, ok := tc.env[name]
+				t.Setenv(name, val)
+
+				if !ok {
+					_ = os.Unsetenv(name)
+				}
+			}
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+			ps.SetEnvPrefix("PTEST_EF_")
+
+			exists := filecheck.Optional
+			if tc.mustExist {
+				exists = filecheck.MustExist
+			}
+
+			for _, ef := range tc.envFiles {
+				ps.AddEnvFile(ef, exists)
+			}
+
+			var name, single, double, editor string
+
+			nameParam := ps.Add("name",
+				psetter.String[string]{Value: &name}, "desc")
+			ps.Add("single", psetter.String[string]{Value: &single}, "desc")
+			ps.Add("double", psetter.String[string]{Value: &double}, "desc")
+			ps.Add("editor", psetter.String[string]{Value: &editor}, "desc",
+				param.EnvVar("PTEST_EF_EDITOR"))
+
+			ps.Parse([]string{})
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+			testhelper.DiffString(t, tc.IDStr(), "name", name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "single",
+				single, tc.expSingle)
+			testhelper.DiffString(t, tc.IDStr(), "double",
+				double, tc.expDouble)
+			testhelper.DiffString(t, tc.IDStr(), "editor",
+				editor, tc.expEditor)
+			testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+				nameParam.WhereSet(), tc.expNameSet)
+		})
+	}
+}
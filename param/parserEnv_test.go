@@ -1,10 +1,12 @@
 package param_test
 
 import (
+	"os"
 	"testing"
 
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
 	"github.com/nickwells/testhelper.mod/v2/testhelper"
 )
 
@@ -152,3 +154,225 @@ func panicEnvPrefix(t *testing.T, seq []pfxFunc, ps *param.PSet,
 
 	return panicked, panicVal
 }
+
+func TestEnvVarParse(t *testing.T) {
+	const (
+		envLoc     = "[environment]: :0: "
+		cmdLineLoc = "[command line]: Supplied Parameter:"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		env         map[string]string
+		params      []string
+		expVal      string
+		expWhereSet []string
+	}{
+		{
+			ID:     testhelper.MkID("not set"),
+			expVal: "vi",
+		},
+		{
+			ID:     testhelper.MkID("first env var"),
+			env:    map[string]string{"PTEST_VISUAL": "code"},
+			expVal: "code",
+			expWhereSet: []string{
+				envLoc + "PTEST_VISUAL=code",
+			},
+		},
+		{
+			ID: testhelper.MkID("both env vars - first used"),
+			env: map[string]string{
+				"PTEST_VISUAL": "code",
+				"PTEST_EDITOR": "ed",
+			},
+			expVal: "code",
+			expWhereSet: []string{
+				envLoc + "PTEST_VISUAL=code",
+			},
+		},
+		{
+			ID: testhelper.MkID("empty env var ignored"),
+			env: map[string]string{
+				"PTEST_VISUAL": "",
+				"PTEST_EDITOR": "ed",
+			},
+			expVal: "ed",
+			expWhereSet: []string{
+				envLoc + "PTEST_EDITOR=ed",
+			},
+		},
+		{
+			ID: testhelper.MkID("prefixed env var takes precedence"),
+			env: map[string]string{
+				"PTEST_EDITOR":     "ed",
+				"PTEST_PFX_editor": "emacs",
+			},
+			expVal: "emacs",
+			expWhereSet: []string{
+				envLoc + "PTEST_EDITOR=ed",
+				envLoc + "PTEST_PFX_editor=emacs",
+			},
+		},
+		{
+			ID:     testhelper.MkID("command line takes precedence"),
+			env:    map[string]string{"PTEST_EDITOR": "ed"},
+			params: []string{"-editor", "nano"},
+			expVal: "nano",
+			expWhereSet: []string{
+				envLoc + "PTEST_EDITOR=ed",
+				cmdLineLoc + `2: "-editor" "nano"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			for _, name := range []string{
+				"PTEST_VISUAL", "PTEST_EDITOR", "PTEST_PFX_editor",
+			} {
+				val, ok := tc.env[name]
+				t.Setenv(name, val)
+
+				if !ok {
+					_ = os.Unsetenv(name)
+				}
+			}
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+			ps.SetEnvPrefix("PTEST_PFX_")
+
+			editor := "vi"
+			p := ps.Add("editor", psetter.String[string]{Value: &editor},
+				"the editor to use",
+				param.EnvVar("PTEST_VISUAL", "PTEST_EDITOR"))
+
+			ps.Parse(tc.params)
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+
+			testhelper.DiffString(t, tc.IDStr(), "editor",
+				editor, tc.expVal)
+			testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+				p.WhereSet(), tc.expWhereSet)
+		})
+	}
+}
+
+func TestEnvVarNoValue(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		val      string
+		expCount int
+	}{
+		{
+			ID:       testhelper.MkID("set"),
+			val:      "1",
+			expCount: 1,
+		},
+		{
+			ID:       testhelper.MkID("set to any value"),
+			val:      "no",
+			expCount: 1,
+		},
+		{
+			ID: testhelper.MkID("empty"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Setenv("PTEST_NO_COLOR", tc.val)
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+			var count int
+
+			ps.Add("no-colour", psetter.Counter[int]{Value: &count},
+				"don't use colour",
+				param.EnvVar("PTEST_NO_COLOR"))
+
+			ps.Parse([]string{})
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+			testhelper.DiffInt(t, tc.IDStr(), "count", count, tc.expCount)
+		})
+	}
+}
+
+func TestEnvVarAdd(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		opts []param.ByNameOptFunc
+	}{
+		{
+			ID:   testhelper.MkID("good"),
+			opts: []param.ByNameOptFunc{param.EnvVar("NEW_VAR")},
+		},
+		{
+			ID:   testhelper.MkID("empty name"),
+			opts: []param.ByNameOptFunc{param.EnvVar("")},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"the environment variable name must not be empty"),
+		},
+		{
+			ID:   testhelper.MkID("bad name"),
+			opts: []param.ByNameOptFunc{param.EnvVar("NEW=VAR")},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				`bad environment variable name: "NEW=VAR":`+
+					` it must not contain '=' or any white space`),
+		},
+		{
+			ID:   testhelper.MkID("already bound"),
+			opts: []param.ByNameOptFunc{param.EnvVar("OLD_VAR")},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				`the environment variable "OLD_VAR"`+
+					` is already bound to the parameter "old"`),
+		},
+		{
+			ID: testhelper.MkID("command line only"),
+			opts: []param.ByNameOptFunc{
+				param.EnvVar("NEW_VAR"),
+				param.Attrs(param.CommandLineOnly),
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"it may only be set on the command line"+
+					` but it is bound to the environment variables:`+
+					` ["NEW_VAR"]`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		ps.Add("old", psetter.String[string]{Value: new(string)}, "desc",
+			param.EnvVar("OLD_VAR"))
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Add("new", psetter.String[string]{Value: new(string)}, "desc",
+				tc.opts...)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
+
+func TestEnvVarSubCommand(t *testing.T) {
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+	panicked, panicVal := testhelper.PanicSafe(func() {
+		ps.AddSubCommand("build", "build the thing", func(ps *param.PSet) {
+			ps.Add("fast", psetter.Bool{Value: new(bool)}, "desc",
+				param.EnvVar("FAST"))
+		})
+	})
+	testhelper.PanicCheckError(t, "sub-command param", panicked, true,
+		panicVal, []string{
+			`can't add named parameter: "fast"`,
+			"the parameter cannot be bound to environment variables:" +
+				` it belongs to the sub-command "build"`,
+		})
+}
@@ -0,0 +1,289 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/location.mod/location"
+)
+
+// DfltProfile is the name of the config file section which is always
+// applied regardless of which profiles have been selected
+const DfltProfile = "default"
+
+// SelectsProfiles is a ByNameOptFunc which marks the parameter as the one
+// used to select the config file profiles. Config files may be divided into
+// sections, each introduced by a line giving the names of the profiles it
+// is for in square brackets, for instance:
+//
+//	[dev, staging]
+//
+// The lines in a section are only used if one of its profiles has been
+// selected. Lines before the first section header and those in the
+// DfltProfile section are always used.
+//
+// The value of the parameter should be a comma-separated list of profile
+// names. As the profiles must be known before any config files are read the
+// command line is searched for the parameter before any other source is
+// processed. The parameter should therefore also have the CommandLineOnly
+// attribute.
+//
+// Profile sections are only supported in config files having the line
+// format, not in the structured formats.
+//
+// Once all the parameters have been processed an error is recorded against
+// the parameter for each selected profile which is not named in any of the
+// section headers of the config files that were read.
+//
+// It will return an error if the parameter does not take a value or if
+// another parameter has already been marked as selecting profiles.
+func SelectsProfiles(p *ByName) error {
+	if p.setter.ValueReq() != Mandatory {
+		return errors.New(
+			"a parameter selecting profiles must take a value")
+	}
+
+	if other := p.ps.profileParam; other != nil {
+		return fmt.Errorf(
+			"the profiles are already selected by the parameter %q",
+			other.name)
+	}
+
+	p.ps.profileParam = p
+
+	return nil
+}
+
+// HasProfileParam returns true if a parameter has been marked as selecting
+// the config file profiles, false otherwise. See the SelectsProfiles
+// function.
+func (ps PSet) HasProfileParam() bool {
+	return ps.profileParam != nil
+}
+
+// Profiles returns a copy of the config file profiles that have been
+// selected. This will only be set once the parameters have been parsed.
+func (ps *PSet) Profiles() []string {
+	return slices.Clone(ps.profiles)
+}
+
+// addKnownProfiles records the profiles as having been named in a config
+// file section header. They are recorded in the top-level PSet as that is
+// where the profiles are selected.
+func (ps *PSet) addKnownProfiles(profiles []string) {
+	for ps.parent != nil {
+		ps = ps.parent
+	}
+
+	if ps.knownProfiles == nil {
+		ps.knownProfiles = map[string]bool{}
+	}
+
+	for _, name := range profiles {
+		ps.knownProfiles[name] = true
+	}
+}
+
+// checkProfiles records an error against the parameter selecting the
+// profiles for each selected profile which is not named in any config file
+// section header. The DfltProfile is always allowed.
+func (ps *PSet) checkProfiles() {
+	if ps.profileParam == nil {
+		return
+	}
+
+	known := slices.Sorted(maps.Keys(ps.knownProfiles))
+
+	for _, name := range ps.profiles {
+		if name == DfltProfile || ps.knownProfiles[name] {
+			continue
+		}
+
+		if len(known) == 0 {
+			ps.AddErr(ps.profileParam.name,
+				fmt.Errorf("the profile %q is not known:"+
+					" no profiles are named in the config files",
+					name))
+
+			continue
+		}
+
+		ps.AddErr(ps.profileParam.name,
+			fmt.Errorf("the profile %q is not known,"+
+				" it must be one of: %s",
+				name, english.JoinQuoted(known, ", ", " or ")))
+	}
+}
+
+// splitProfiles splits the value into a list of profile names, stripping
+// any surrounding white space and discarding empty names
+func splitProfiles(val string) []string {
+	var profiles []string
+
+	for p := range strings.SplitSeq(val, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			profiles = append(profiles, p)
+		}
+	}
+
+	return profiles
+}
+
+// findProfiles searches the parameters for the one selecting profiles and
+// records the profiles it gives. No errors are reported, any problems will
+// be found when the parameters are processed in full. If the parameter is
+// given more than once the last value is used.
+func (ps *PSet) findProfiles(params []string) {
+	if ps.profileParam == nil {
+		return
+	}
+
+	for i := len(ps.byPos); i < len(params); i++ {
+		pStr := params[i]
+
+		if pStr == ps.terminalParam {
+			return
+		}
+
+		if _, ok := ps.subCmds[pStr]; ok {
+			return
+		}
+
+		paramName, paramVal, hasParamVal := strings.Cut(pStr, "=")
+
+		trimmedParam, ok := ps.trimParam(paramName)
+		if !ok {
+			continue
+		}
+
+		p, ok := ps.findParam(trimmedParam)
+		if !ok && ps.abbreviationsAllowed {
+			fullName, ambiguous := ps.matchAbbreviation(trimmedParam)
+			if ambiguous == nil {
+				p, ok = ps.findParam(fullName)
+			}
+		}
+
+		if !ok {
+			continue
+		}
+
+		if !hasParamVal && p.setter.ValueReq() == Mandatory {
+			if i == len(params)-1 {
+				return
+			}
+
+			i++
+			paramVal = params[i]
+		}
+
+		if p == ps.profileParam {
+			ps.profiles = splitProfiles(paramVal)
+		}
+	}
+}
+
+// profileTracker records the profile section of each config file as it is
+// parsed. The section is recorded for each file separately so that an
+// included file starts in the default section and the including file
+// resumes in its own section.
+type profileTracker struct {
+	ps       *PSet
+	sections map[string][]string
+}
+
+// newProfileTracker returns a new profileTracker
+func newProfileTracker(ps *PSet) profileTracker {
+	return profileTracker{
+		ps:       ps,
+		sections: map[string][]string{},
+	}
+}
+
+// isSectionHeader returns true if the line is a profile section header, in
+// which case the section is recorded. It returns a non-nil error if the
+// header is malformed.
+func (pt profileTracker) isSectionHeader(line string, loc *location.L,
+) (bool, error) {
+	if !strings.HasPrefix(line, "[") {
+		return false, nil
+	}
+
+	names, ok := strings.CutPrefix(line, "[")
+	if names, ok = strings.CutSuffix(names, "]"); !ok {
+		loc.SetContent(line)
+
+		return true, &BadLineError{
+			ParamErrDetails: mkParamErrDetails("", loc),
+			Err: errors.New(
+				"a profile section header should be of the form [name, ...]"),
+		}
+	}
+
+	profiles := splitProfiles(names)
+	if len(profiles) == 0 {
+		loc.SetContent(line)
+
+		return true, &BadLineError{
+			ParamErrDetails: mkParamErrDetails("", loc),
+			Err:             errors.New("the profile section header has no names"),
+		}
+	}
+
+	pt.sections[loc.Source()] = profiles
+	pt.ps.addKnownProfiles(profiles)
+
+	return true, nil
+}
+
+// section returns the profiles of the current section of the file. It
+// returns nil if the current section is the default section.
+func (pt profileTracker) section(loc *location.L) []string {
+	profiles := pt.sections[loc.Source()]
+	if slices.Contains(profiles, DfltProfile) {
+		return nil
+	}
+
+	return profiles
+}
+
+// applies returns true if the lines in the current section of the file
+// should be used
+func (pt profileTracker) applies(loc *location.L) bool {
+	profiles := pt.section(loc)
+	if profiles == nil {
+		return true
+	}
+
+	return slices.ContainsFunc(profiles, func(p string) bool {
+		return slices.Contains(pt.ps.profiles, p)
+	})
+}
+
+// content returns the line to be recorded as the content of the location,
+// prefixed with the profile section if it is not the default section.
+func (pt profileTracker) content(line string, loc *location.L) string {
+	profiles := pt.section(loc)
+	if profiles == nil {
+		return line
+	}
+
+	return "[" + strings.Join(profiles, ", ") + "] " + line
+}
+
+// skipLine checks the line for a profile section header and reports
+// whether or not the line should be skipped. The line should be skipped if
+// it is a section header or if it is in a section for a profile that has
+// not been selected. Any error in the header is returned.
+func (pt profileTracker) skipLine(line string, loc *location.L) (bool, error) {
+	isHeader, err := pt.isSectionHeader(line, loc)
+	if isHeader {
+		return true, err
+	}
+
+	return !pt.applies(loc), nil
+}
@@ -0,0 +1,194 @@
+package param_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestProfiles(t *testing.T) {
+	const (
+		cfgFile    = "testdata/profiles.cfg"
+		badCfgFile = "testdata/profiles-bad.cfg"
+		cfgLoc     = "[config file]: " + cfgFile + ":"
+		badCfgLoc  = "[config file]: " + badCfgFile + ":"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		cfgFile     string
+		args        []string
+		expErrs     map[string][]string
+		expName     string
+		expColour   string
+		expProfiles []string
+		expNameSet  []string
+	}{
+		{
+			ID:        testhelper.MkID("no profiles"),
+			cfgFile:   cfgFile,
+			expName:   "base",
+			expColour: "red",
+			expNameSet: []string{
+				cfgLoc + "2: name=base",
+			},
+		},
+		{
+			ID:          testhelper.MkID("dev profile"),
+			cfgFile:     cfgFile,
+			args:        []string{"-profile", "dev"},
+			expName:     "dev-val",
+			expColour:   "red",
+			expProfiles: []string{"dev"},
+			expNameSet: []string{
+				cfgLoc + "2: name=base",
+				cfgLoc + "4: [dev] name=dev-val",
+			},
+		},
+		{
+			ID:          testhelper.MkID("staging profile"),
+			cfgFile:     cfgFile,
+			args:        []string{"-profile=staging"},
+			expName:     "prod-val",
+			expColour:   "red",
+			expProfiles: []string{"staging"},
+			expNameSet: []string{
+				cfgLoc + "2: name=base",
+				cfgLoc + "6: [prod, staging] name=prod-val",
+			},
+		},
+		{
+			ID:          testhelper.MkID("several profiles"),
+			cfgFile:     cfgFile,
+			args:        []string{"-colour", "blue", "-profile", "dev, prod"},
+			expName:     "prod-val",
+			expColour:   "blue",
+			expProfiles: []string{"dev", "prod"},
+			expNameSet: []string{
+				cfgLoc + "2: name=base",
+				cfgLoc + "4: [dev] name=dev-val",
+				cfgLoc + "6: [prod, staging] name=prod-val",
+			},
+		},
+		{
+			ID:      testhelper.MkID("unknown profile"),
+			cfgFile: cfgFile,
+			args:    []string{"-profile", "nonesuch"},
+			expErrs: map[string][]string{
+				"profile": {
+					`the profile "nonesuch" is not known,` +
+						` it must be one of: "default", "dev", "prod"` +
+						` or "staging"`,
+				},
+			},
+			expName:     "base",
+			expColour:   "red",
+			expProfiles: []string{"nonesuch"},
+			expNameSet: []string{
+				cfgLoc + "2: name=base",
+			},
+		},
+		{
+			ID:          testhelper.MkID("bad section headers"),
+			cfgFile:     badCfgFile,
+			args:        []string{"-profile", "dev"},
+			expProfiles: []string{"dev"},
+			expErrs: map[string][]string{
+				"config file: " + badCfgFile: {
+					"a profile section header should be of the form" +
+						" [name, ...]",
+					badCfgFile + ":2: [dev",
+					"the profile section header has no names",
+					badCfgFile + ":4: [ ]",
+				},
+				"profile": {
+					`the profile "dev" is not known:` +
+						" no profiles are named in the config files",
+				},
+			},
+			expName: "dev-val",
+			expNameSet: []string{
+				badCfgLoc + "1: name=base",
+				badCfgLoc + "3: name=dev-val",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+			ps.AddConfigFile(tc.cfgFile, filecheck.MustExist)
+
+			var name, colour string
+
+			var profiles []string
+
+			nameParam := ps.Add("name",
+				psetter.String[string]{Value: &name}, "desc")
+			ps.Add("colour", psetter.String[string]{Value: &colour}, "desc")
+			ps.Add("profile", psetter.StrList[string]{Value: &profiles},
+				"desc",
+				param.SelectsProfiles,
+				param.Attrs(param.CommandLineOnly))
+
+			ps.Parse(tc.args)
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+			testhelper.DiffString(t, tc.IDStr(), "name", name, tc.expName)
+			testhelper.DiffString(t, tc.IDStr(), "colour",
+				colour, tc.expColour)
+			testhelper.DiffStringSlice(t, tc.IDStr(), "profiles",
+				ps.Profiles(), tc.expProfiles)
+			testhelper.DiffStringSlice(t, tc.IDStr(), "where set",
+				nameParam.WhereSet(), tc.expNameSet)
+		})
+	}
+}
+
+func TestSelectsProfiles(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		setter    param.Setter
+		hasOthers bool
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			setter: psetter.String[string]{Value: new(string)},
+		},
+		{
+			ID:     testhelper.MkID("no value"),
+			setter: psetter.Bool{Value: new(bool)},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"a parameter selecting profiles must take a value"),
+		},
+		{
+			ID:        testhelper.MkID("already selected"),
+			setter:    psetter.String[string]{Value: new(string)},
+			hasOthers: true,
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				`the profiles are already selected by the parameter "old"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		if tc.hasOthers {
+			ps.Add("old", psetter.String[string]{Value: new(string)}, "desc",
+				param.SelectsProfiles)
+		}
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Add("new", tc.setter, "desc", param.SelectsProfiles)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
@@ -0,0 +1,594 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/nickwells/errutil.mod/errutil"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// ChangeEvent records the change in the value of a Reloadable parameter
+// when the parameters are reloaded. The values are as given by the
+// CurrentValue method of the parameter's setter. The Source is where the
+// new value was set; it is empty if the parameter is no longer set by any
+// source and so has been returned to its initial value.
+//
+// The Generation identifies the reload which made the change. All the
+// changes made by a reload have the same Generation. The new values are
+// all stored before any of the ChangeEvents are returned (or sent by
+// Watch). They are stored one at a time and so another goroutine reading
+// them while they are being stored may see some new values and some old.
+type ChangeEvent struct {
+	Name       string
+	OldVal     string
+	NewVal     string
+	Source     string
+	Generation uint64
+}
+
+// reloadEntry records a value of a Reloadable parameter found while the
+// parameter sources are being re-read
+type reloadEntry struct {
+	paramParts []string
+	loc        location.L
+}
+
+// valueSnapshot records the value of the variable that a setter sets so
+// that it can be restored later. If the setter is a ValueSaver it is used to
+// save and restore the value, otherwise the value is found through the
+// setter's Value field.
+type valueSnapshot struct {
+	saver ptypes.ValueSaver
+	saved any
+
+	ptr reflect.Value
+	val reflect.Value
+}
+
+// cloneValue returns a copy of the value. Slices and maps are copied so
+// that changes to the original will not be seen in the copy.
+func cloneValue(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return c
+		}
+
+		c.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		reflect.Copy(c, v)
+	case reflect.Map:
+		if v.IsNil() {
+			return c
+		}
+
+		c.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		c.Set(v)
+	}
+
+	return c
+}
+
+// takeSnapshot records the value of the variable that the setter sets. The
+// setter must either be a ValueSaver or else be a struct (or a pointer to
+// one) with a Value field holding a non-nil pointer to the variable. It
+// returns an error if not.
+func takeSnapshot(s Setter) (valueSnapshot, error) {
+	if vs, ok := s.(ptypes.ValueSaver); ok {
+		return valueSnapshot{saver: vs, saved: vs.SavedValue()}, nil
+	}
+
+	ptr, err := valueField(s)
+	if err != nil {
+		return valueSnapshot{}, err
+	}
+
+	if ptr.IsNil() {
+		return valueSnapshot{},
+			fmt.Errorf("the setter (%T) has a nil Value field", s)
+	}
+
+	return valueSnapshot{ptr: ptr, val: cloneValue(ptr.Elem())}, nil
+}
+
+// valueField returns the Value field of the setter which must be a struct
+// (or a pointer to one) with a Value field holding a pointer. It returns an
+// error if not.
+func valueField(s Setter) (reflect.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{},
+			fmt.Errorf("the setter (%T) is not a struct", s)
+	}
+
+	ptr := v.FieldByName("Value")
+	if !ptr.IsValid() || ptr.Kind() != reflect.Pointer {
+		return reflect.Value{},
+			fmt.Errorf("the setter (%T) has no Value pointer field", s)
+	}
+
+	return ptr, nil
+}
+
+// newScratch returns a copy of the setter which sets a new variable, rather
+// than the one the setter sets, together with a snapshot of the new
+// variable. The new variable is given the value in the snapshot. This
+// allows new values to be tried without changing the original variable.
+func newScratch(s Setter, vs valueSnapshot) (Setter, valueSnapshot, error) {
+	if _, err := valueField(s); err != nil {
+		return nil, valueSnapshot{}, err
+	}
+
+	sv := reflect.ValueOf(s)
+	isPtr := sv.Kind() == reflect.Pointer
+	sv = reflect.Indirect(sv)
+
+	scratch := reflect.New(sv.Type()).Elem()
+	scratch.Set(sv)
+
+	target := reflect.New(scratch.FieldByName("Value").Type().Elem())
+	scratch.FieldByName("Value").Set(target)
+
+	if isPtr {
+		scratch = scratch.Addr()
+	}
+
+	ss, ok := scratch.Interface().(Setter)
+	if !ok {
+		return nil, valueSnapshot{},
+			fmt.Errorf("the copy of the setter (%T) is not a Setter", s)
+	}
+
+	if vs.saver != nil {
+		saver, ok := ss.(ptypes.ValueSaver)
+		if !ok {
+			return nil, valueSnapshot{},
+				fmt.Errorf("the copy of the setter (%T) is not a ValueSaver",
+					s)
+		}
+
+		saver.RestoreValue(vs.saved)
+
+		return ss, valueSnapshot{saver: saver, saved: vs.saved}, nil
+	}
+
+	target.Elem().Set(cloneValue(vs.val))
+
+	return ss, valueSnapshot{ptr: target, val: cloneValue(vs.val)}, nil
+}
+
+// current returns a snapshot of the current value of the variable
+func (vs valueSnapshot) current() valueSnapshot {
+	if vs.saver != nil {
+		return valueSnapshot{saver: vs.saver, saved: vs.saver.SavedValue()}
+	}
+
+	return valueSnapshot{ptr: vs.ptr, val: cloneValue(vs.ptr.Elem())}
+}
+
+// restore sets the variable back to the value in the snapshot
+func (vs valueSnapshot) restore() {
+	vs.store(vs)
+}
+
+// store sets the variable to the value recorded in the other snapshot. A
+// ValueSaver is used to set the value if there is one so that the value
+// is set safely.
+func (vs valueSnapshot) store(other valueSnapshot) {
+	if vs.saver != nil {
+		vs.saver.RestoreValue(other.saved)
+		return
+	}
+
+	vs.ptr.Elem().Set(cloneValue(other.val))
+}
+
+// equal returns true if the value recorded in the other snapshot is the
+// same as the value in this one
+func (vs valueSnapshot) equal(other valueSnapshot) bool {
+	if vs.saver != nil {
+		return reflect.DeepEqual(vs.saved, other.saved)
+	}
+
+	return reflect.DeepEqual(vs.val.Interface(), other.val.Interface())
+}
+
+// checkReloadable returns an error if the parameter cannot be reloaded. If
+// it can be then the initial value is recorded so that the new values can
+// be applied to it when the parameter is reloaded.
+func (p *ByName) checkReloadable() error {
+	if p.AttrIsSet(CommandLineOnly) {
+		return errors.New(
+			"a Reloadable parameter must not be CommandLineOnly")
+	}
+
+	vs, err := takeSnapshot(p.setter)
+	if err == nil {
+		_, _, err = newScratch(p.setter, vs)
+	}
+
+	if err != nil {
+		return fmt.Errorf("the parameter cannot be Reloadable: %w", err)
+	}
+
+	p.initialVal = vs
+
+	return nil
+}
+
+// recordCmdLineEntry records the value of a Reloadable parameter given on
+// the command line, or in a config file named on the command line (see
+// ConfigFileActionFunc), so that it can be reapplied when the parameters
+// are reloaded
+func (p *ByName) recordCmdLineEntry(loc *location.L, paramParts []string) {
+	p.cmdLineEntries = append(p.cmdLineEntries,
+		reloadEntry{
+			paramParts: slices.Clone(paramParts),
+			loc:        *loc,
+		})
+}
+
+// recordReloadEntry records the value of a Reloadable parameter found while
+// the parameters are being reloaded. Other parameters are ignored.
+func (p *ByName) recordReloadEntry(loc *location.L, paramParts []string) {
+	if !p.AttrIsSet(Reloadable) {
+		return
+	}
+
+	p.ps.reloadEntries[p] = append(p.ps.reloadEntries[p],
+		reloadEntry{
+			paramParts: slices.Clone(paramParts),
+			loc:        *loc,
+		})
+}
+
+// reloadableParams returns the Reloadable parameters
+func (ps *PSet) reloadableParams() []*ByName {
+	var params []*ByName
+
+	for _, p := range ps.byName {
+		if p.AttrIsSet(Reloadable) {
+			params = append(params, p)
+		}
+	}
+
+	return params
+}
+
+// HasReloadableParams returns true if any of the parameters has the
+// Reloadable attribute set, false otherwise.
+func (ps *PSet) HasReloadableParams() bool {
+	return len(ps.reloadableParams()) > 0
+}
+
+// errMapToError returns a single error combining all the errors in the
+// error map or nil if there are none
+func errMapToError(em errutil.ErrMap) error {
+	var errs []error
+
+	keys := em.Keys()
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		for _, err := range em[k] {
+			errs = append(errs, fmt.Errorf("%s: %w", k, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectErrs calls the function with a new, empty, error map and list of
+// unused parameters in place of those of the PSet. Any errors recorded by
+// the function are returned and the original error map and unused
+// parameters are restored.
+func (ps *PSet) collectErrs(f func()) error {
+	errMap, errorCount, unusedParams :=
+		ps.errMap, ps.errorCount, ps.unusedParams
+	defer func() {
+		ps.errMap, ps.errorCount, ps.unusedParams =
+			errMap, errorCount, unusedParams
+	}()
+
+	ps.errMap = *(errutil.NewErrMap())
+	ps.errorCount = 0
+	ps.unusedParams = make(map[string][]string)
+
+	f()
+
+	return errMapToError(ps.errMap)
+}
+
+// collectReloadEntries re-reads the parameter sources and returns the
+// values found for each Reloadable parameter, in the order in which they
+// should be applied. The command line is not re-read, instead the values
+// originally given are used.
+func (ps *PSet) collectReloadEntries(params []*ByName,
+) (map[*ByName][]reloadEntry, error) {
+	ps.reloading = true
+	ps.reloadEntries = make(map[*ByName][]reloadEntry)
+
+	defer func() {
+		ps.reloading = false
+		ps.reloadEntries = nil
+	}()
+
+	err := ps.collectErrs(func() {
+		for _, st := range ps.sourceOrder {
+			switch st {
+			case GroupConfigFileSource:
+				ps.getParamsFromGroupConfigFiles()
+			case ConfigFileSource:
+				ps.getParamsFromConfigFiles()
+			case EnvFileSource:
+				ps.getParamsFromEnvFiles()
+			case EnvironmentSource:
+				ps.getParamsFromEnvironment()
+			case CommandLineSource:
+				for _, p := range params {
+					ps.reloadEntries[p] = append(ps.reloadEntries[p],
+						p.cmdLineEntries...)
+				}
+			case FinalConfigFileSource:
+				ps.getParamsFromFinalConfigFiles()
+			}
+		}
+	})
+
+	return ps.reloadEntries, err
+}
+
+// reloadCandidate records the new value of a Reloadable parameter. The
+// value is held in a scratch copy of the parameter's setter so that it can
+// be checked before it is stored.
+type reloadCandidate struct {
+	setter Setter
+	val    valueSnapshot
+	where  []string
+}
+
+// buildCandidate returns the value the parameter would have if each of the
+// entries were applied in turn to its initial value. The entries are
+// applied to a scratch copy of the setter and so the parameter value is not
+// changed. It also returns any errors.
+func (p *ByName) buildCandidate(entries []reloadEntry,
+) (*reloadCandidate, error) {
+	ss, vs, err := newScratch(p.setter, p.initialVal)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &reloadCandidate{setter: ss}
+
+	var errs []error
+
+	for _, e := range entries {
+		if p.AttrIsSet(SetOnlyOnce) && len(c.where) > 0 {
+			break
+		}
+
+		if err := p.setWith(ss, e.paramParts); err != nil {
+			errs = append(errs,
+				fmt.Errorf("%s: %w",
+					p.name, p.setErr(&e.loc, e.paramParts, err)))
+
+			continue
+		}
+
+		c.where = append(c.where, e.loc.String())
+	}
+
+	c.val = vs.current()
+
+	return c, errors.Join(errs...)
+}
+
+// whereSetToCheck returns the places where the parameter has been set. If
+// the parameters are being reloaded this will be where the new value of a
+// Reloadable parameter was set.
+func (p *ByName) whereSetToCheck() []string {
+	if p.candidate != nil {
+		return p.candidate.where
+	}
+
+	return p.whereIsParamSet
+}
+
+// valueToCheck returns the current value of the parameter. If the
+// parameters are being reloaded this will be the new value of a Reloadable
+// parameter.
+func (p *ByName) valueToCheck() string {
+	if p.candidate != nil {
+		return p.candidate.setter.CurrentValue()
+	}
+
+	return p.setter.CurrentValue()
+}
+
+// ReloadCheckFunc is the type of a function to be called when the
+// parameters are reloaded. It is passed the changes that would be made to
+// the parameter values and should return an error if they are not
+// acceptable.
+type ReloadCheckFunc func(changes []ChangeEvent) error
+
+// AddReloadCheck will add a function to the list of functions to be called
+// when the parameters are reloaded. They are called with the new values of
+// the parameters before they are stored and so, unlike the final checks
+// (see AddFinalCheck), can stop a bad value from ever being seen. If any of
+// them returns an error the new values are not used.
+//
+// This will panic if called after the parameters have been parsed.
+func (ps *PSet) AddReloadCheck(f ReloadCheckFunc) {
+	ps.panicIfAlreadyParsed("can't add a reload check function")
+
+	ps.reloadChecks = append(ps.reloadChecks, f)
+}
+
+// checkCandidates runs the checks for missing mandatory parameters, the
+// constraints and the reload checks against the new values of the
+// parameters. It returns any errors.
+func (ps *PSet) checkCandidates(cands map[*ByName]*reloadCandidate,
+	changes []ChangeEvent,
+) error {
+	for p, c := range cands {
+		p.candidate = c
+	}
+
+	defer func() {
+		for p := range cands {
+			p.candidate = nil
+		}
+	}()
+
+	return ps.collectErrs(func() {
+		ps.detectMandatoryParamsNotSet()
+		ps.checkConstraintsAreMet()
+
+		for _, f := range ps.reloadChecks {
+			ps.AddErr("Reload Checks", f(changes))
+		}
+	})
+}
+
+// Reload re-reads the configuration files, env files and the environment
+// and gives new values to any parameters having the Reloadable attribute.
+// For each Reloadable parameter the values found are applied to the value
+// it had when it was added to the PSet, in the same order as when the
+// parameters were parsed. The command line is not re-read but any values
+// given there for Reloadable parameters, including those from config files
+// named on the command line (see ConfigFileActionFunc), are applied again
+// so that the precedence of the sources is preserved. Such config files are
+// not re-read and so the values applied are those read when the parameters
+// were parsed. Other parameters are not changed.
+//
+// The new values are built in scratch copies of the parameters' setters
+// and the parameter values are not changed until all of the new values have
+// been found and checked. Any missing mandatory parameters, any violated
+// constraints or any errors from the reload checks (see AddReloadCheck) or
+// from re-reading the sources will cause an error to be returned and the
+// parameters will be unchanged. Otherwise the values which have changed are
+// stored, one after another, and a ChangeEvent is returned for each of
+// them. If nothing has changed then no values are stored.
+//
+// The final checks (see AddFinalCheck) are then run. As these read the
+// program's variables they can only be run once the new values have been
+// stored. If they fail the previous values are restored and an error is
+// returned. Use the setter checks, the constraints or the reload checks
+// for any checks that must pass before a new value can be seen.
+//
+// Any post actions of the parameters are not called.
+//
+// If the values are to be read by other goroutines the parameters should
+// use the atomic setters from the psetter package (such as
+// psetter.AtomicInt or psetter.Atomic) so that they can be read safely.
+//
+// It will return an error if the parameters have not been parsed.
+func (ps *PSet) Reload() ([]ChangeEvent, error) {
+	if !ps.parsed {
+		return nil, errors.New("the parameters have not yet been parsed")
+	}
+
+	ps.reloadMu.Lock()
+	defer ps.reloadMu.Unlock()
+
+	params := ps.reloadableParams()
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	entries, err := ps.collectReloadEntries(params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reload the parameters: %w", err)
+	}
+
+	cands := make(map[*ByName]*reloadCandidate, len(params))
+
+	var errs []error
+
+	for _, p := range params {
+		c, err := p.buildCandidate(entries[p])
+		errs = append(errs, err)
+		cands[p] = c
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("cannot reload the parameters: %w", err)
+	}
+
+	gen := ps.reloadGen + 1
+
+	var (
+		changes []ChangeEvent
+		changed []*ByName
+	)
+
+	for _, p := range params {
+		c := cands[p]
+		if c.val.equal(p.initialVal.current()) {
+			continue
+		}
+
+		ce := ChangeEvent{
+			Name:       p.name,
+			OldVal:     p.setter.CurrentValue(),
+			NewVal:     c.setter.CurrentValue(),
+			Generation: gen,
+		}
+		if n := len(c.where); n > 0 {
+			ce.Source = c.where[n-1]
+		}
+
+		changes = append(changes, ce)
+		changed = append(changed, p)
+	}
+
+	if err = ps.checkCandidates(cands, changes); err != nil {
+		return nil, fmt.Errorf("cannot reload the parameters: %w", err)
+	}
+
+	prev := make(map[*ByName]valueSnapshot, len(changed))
+	prevWhere := make(map[*ByName][]string, len(params))
+
+	for _, p := range params {
+		prevWhere[p] = p.whereIsParamSet
+		p.whereIsParamSet = cands[p].where
+	}
+
+	for _, p := range changed {
+		prev[p] = p.initialVal.current()
+		p.initialVal.store(cands[p].val)
+	}
+
+	if len(changed) > 0 {
+		err = ps.collectErrs(ps.runFinalChecks)
+	}
+
+	if err != nil {
+		for _, p := range changed {
+			p.initialVal.store(prev[p])
+		}
+
+		for _, p := range params {
+			p.whereIsParamSet = prevWhere[p]
+		}
+
+		return nil, fmt.Errorf("cannot reload the parameters: %w", err)
+	}
+
+	if len(changes) > 0 {
+		ps.reloadGen = gen
+	}
+
+	return changes, nil
+}
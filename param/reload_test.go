@@ -0,0 +1,382 @@
+package param_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// reloadVals holds the values of the parameters used in the reload tests
+type reloadVals struct {
+	count int64
+	level int64
+	name  string
+	tags  []string
+}
+
+// mkReloadPSet creates a PSet having Reloadable parameters and parses it
+func mkReloadPSet(t *testing.T, cfgFile string, v *reloadVals) *param.PSet {
+	t.Helper()
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigFile(cfgFile, filecheck.MustExist)
+
+	ps.Add("count", psetter.Int[int64]{Value: &v.count}, "desc",
+		param.Attrs(param.Reloadable))
+	ps.Add("level", psetter.Int[int64]{Value: &v.level}, "desc",
+		param.Attrs(param.Reloadable))
+	ps.Add("name", psetter.String[string]{Value: &v.name}, "desc")
+	ps.Add("tags", psetter.StrList[string]{Value: &v.tags}, "desc",
+		param.Attrs(param.Reloadable))
+
+	ps.AddFinalCheck(func() error {
+		const maxCount = 10
+		if v.count > maxCount {
+			return errors.New("the count is too big")
+		}
+
+		return nil
+	})
+
+	ps.AddReloadCheck(func(changes []param.ChangeEvent) error {
+		for _, ce := range changes {
+			if ce.Name == "tags" && ce.NewVal == "bad" {
+				return errors.New("the tags are bad")
+			}
+		}
+
+		return nil
+	})
+
+	ps.Parse([]string{"-level", "5"})
+
+	return ps
+}
+
+// writeCfgFile writes the contents to the named file
+func writeCfgFile(t *testing.T, fName, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(fName, []byte(contents), 0o600); err != nil {
+		t.Fatal("cannot write the config file:", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "reload.cfg")
+	writeCfgFile(t, cfgFile, "count=1\nname=first\nlevel=3\n")
+
+	var v reloadVals
+
+	ps := mkReloadPSet(t, cfgFile, &v)
+	errMapCheck(t, "initial parse", ps.Errors(), nil)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		cfg        string
+		expChanges []param.ChangeEvent
+		expVals    reloadVals
+	}{
+		{
+			ID:  testhelper.MkID("new values"),
+			cfg: "count=2\nname=second\nlevel=3\ntags=a,b\n",
+			expChanges: []param.ChangeEvent{
+				{
+					Name:   "count",
+					OldVal: "1",
+					NewVal: "2",
+					Source: "[config file]: " + cfgFile + ":1: count=2",
+
+					Generation: 1,
+				},
+				{
+					Name:   "tags",
+					OldVal: "",
+					NewVal: "a,b",
+					Source: "[config file]: " + cfgFile + ":4: tags=a,b",
+
+					Generation: 1,
+				},
+			},
+			expVals: reloadVals{
+				count: 2, level: 5, name: "first", tags: []string{"a", "b"},
+			},
+		},
+		{
+			ID:  testhelper.MkID("no change"),
+			cfg: "count=2\nlevel=4\ntags=a,b\n",
+			expVals: reloadVals{
+				count: 2, level: 5, name: "first", tags: []string{"a", "b"},
+			},
+		},
+		{
+			ID:     testhelper.MkID("final check fails"),
+			cfg:    "count=20\n",
+			ExpErr: testhelper.MkExpErr("the count is too big"),
+			expVals: reloadVals{
+				count: 2, level: 5, name: "first", tags: []string{"a", "b"},
+			},
+		},
+		{
+			ID:     testhelper.MkID("reload check fails"),
+			cfg:    "count=3\ntags=bad\n",
+			ExpErr: testhelper.MkExpErr("the tags are bad"),
+			expVals: reloadVals{
+				count: 2, level: 5, name: "first", tags: []string{"a", "b"},
+			},
+		},
+		{
+			ID:  testhelper.MkID("bad value"),
+			cfg: "count=3\ntags=c\nlevel=x\n",
+			ExpErr: testhelper.MkExpErr("level: ",
+				`could not interpret "x" as a whole number`),
+			expVals: reloadVals{
+				count: 2, level: 5, name: "first", tags: []string{"a", "b"},
+			},
+		},
+		{
+			ID:  testhelper.MkID("values removed"),
+			cfg: "name=third\n",
+			expChanges: []param.ChangeEvent{
+				{Name: "count", OldVal: "2", NewVal: "0", Generation: 2},
+				{Name: "tags", OldVal: "a,b", NewVal: "", Generation: 2},
+			},
+			expVals: reloadVals{count: 0, level: 5, name: "first"},
+		},
+	}
+
+	for _, tc := range testCases {
+		writeCfgFile(t, cfgFile, tc.cfg)
+
+		changes, err := ps.Reload()
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffSlice(t, tc.IDStr(), "changes",
+			changes, tc.expChanges)
+		testhelper.DiffInt(t, tc.IDStr(), "count", v.count, tc.expVals.count)
+		testhelper.DiffInt(t, tc.IDStr(), "level", v.level, tc.expVals.level)
+		testhelper.DiffString(t, tc.IDStr(), "name", v.name, tc.expVals.name)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "tags",
+			v.tags, tc.expVals.tags)
+	}
+
+	errMapCheck(t, "after reloading", ps.Errors(), nil)
+}
+
+func TestReloadNotParsed(t *testing.T) {
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+	_, err := ps.Reload()
+	testhelper.DiffErr(t, "not parsed", "error", err,
+		errors.New("the parameters have not yet been parsed"))
+}
+
+func TestReloadableAdd(t *testing.T) {
+	var i int64
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		setter param.Setter
+		attrs  param.Attributes
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			setter: psetter.Int[int64]{Value: &i},
+			attrs:  param.Reloadable,
+		},
+		{
+			ID:     testhelper.MkID("command line only"),
+			setter: psetter.Int[int64]{Value: &i},
+			attrs:  param.Reloadable | param.CommandLineOnly,
+			ExpPanic: testhelper.MkExpPanic(
+				"a Reloadable parameter must not be CommandLineOnly"),
+		},
+		{
+			ID:     testhelper.MkID("no Value field"),
+			setter: psetter.Nil{},
+			attrs:  param.Reloadable,
+			ExpPanic: testhelper.MkExpPanic(
+				"the parameter cannot be Reloadable:",
+				"the setter (psetter.Nil) has no Value pointer field"),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Add("p", tc.setter, "desc", param.Attrs(tc.attrs))
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "watch.cfg")
+	writeCfgFile(t, cfgFile, "count=1\n")
+
+	var v reloadVals
+
+	ps := mkReloadPSet(t, cfgFile, &v)
+	errMapCheck(t, "initial parse", ps.Errors(), nil)
+
+	_, err := ps.Watch(context.Background(), param.WatchInterval(0))
+	testhelper.DiffErr(t, "bad interval", "error", err,
+		errors.New("the watch interval (0s) must be greater than zero"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := ps.Watch(ctx, param.WatchInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = ps.Watch(ctx)
+	testhelper.DiffErr(t, "second watch", "error", err,
+		errors.New("the parameters are already being watched"))
+
+	writeCfgFile(t, cfgFile, "count=7\nname=second\n")
+
+	select {
+	case ce := <-ch:
+		testhelper.DiffString(t, "watch", "name", ce.Name, "count")
+		testhelper.DiffString(t, "watch", "new value", ce.NewVal, "7")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change event was received")
+	}
+
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Error("the channel should be closed once the context is done")
+	}
+}
+
+func TestReloadAtomic(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "atomic.cfg")
+	writeCfgFile(t, cfgFile, "count=1\n")
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigFile(cfgFile, filecheck.MustExist)
+
+	count := &psetter.AtomicVal[int64]{}
+
+	ps.Add("count", psetter.AtomicInt[int64]{Value: count}, "desc",
+		param.Attrs(param.Reloadable))
+	ps.Parse([]string{})
+	errMapCheck(t, "initial parse", ps.Errors(), nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for range 1000 {
+			if c := count.Load(); c < 0 || c > 2 {
+				t.Error("unexpected count:", c)
+			}
+		}
+	}()
+
+	writeCfgFile(t, cfgFile, "count=2\n")
+
+	changes, err := ps.Reload()
+	<-done
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	testhelper.DiffSlice(t, "atomic reload", "changes", changes,
+		[]param.ChangeEvent{
+			{
+				Name:   "count",
+				OldVal: "1",
+				NewVal: "2",
+				Source: "[config file]: " + cfgFile + ":1: count=2",
+
+				Generation: 1,
+			},
+		})
+	testhelper.DiffInt(t, "atomic reload", "count", count.Load(), 2)
+
+	// reload the unchanged config file while reading the value
+	// continuously, the value must never change
+	stop := make(chan struct{})
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if c := count.Load(); c != 2 {
+				t.Error("the count changed during an unchanged reload:", c)
+				return
+			}
+		}
+	}()
+
+	for range 20 {
+		changes, err = ps.Reload()
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+
+		testhelper.DiffInt(t, "unchanged reload", "changes", len(changes), 0)
+	}
+
+	close(stop)
+	<-done
+}
+
+func TestReloadSuppliedConfigFile(t *testing.T) {
+	const id = "reload supplied config file"
+
+	dir := t.TempDir()
+	cfgFile := filepath.Join(dir, "reload.cfg")
+	paramsFile := filepath.Join(dir, "params.cfg")
+
+	writeCfgFile(t, cfgFile, "count=1\n")
+	writeCfgFile(t, paramsFile, "count=7\n")
+
+	var (
+		count      int64
+		paramsFrom string
+	)
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.AddConfigFile(cfgFile, filecheck.MustExist)
+	ps.Add("count", psetter.Int[int64]{Value: &count}, "desc",
+		param.Attrs(param.Reloadable))
+	ps.Add("params-file", psetter.String[string]{Value: &paramsFrom}, "desc",
+		param.PostAction(param.ConfigFileActionFunc))
+	ps.Parse([]string{"-params-file", paramsFile})
+	errMapCheck(t, id, ps.Errors(), nil)
+	testhelper.DiffInt(t, id, "initial count", count, 7)
+
+	writeCfgFile(t, cfgFile, "count=2\n")
+
+	changes, err := ps.Reload()
+	if err != nil {
+		t.Fatal(id+": unexpected error:", err)
+	}
+
+	testhelper.DiffInt(t, id, "changes", len(changes), 0)
+	testhelper.DiffInt(t, id, "reloaded count", count, 7)
+}
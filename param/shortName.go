@@ -0,0 +1,225 @@
+package param
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+// ShortNamePrefix is the prefix that must be given before a short name (or
+// a bundle of short names) on the command line.
+const ShortNamePrefix = "-"
+
+// ShortNamesAllowed returns true if single-character short names can be
+// given for parameters in this parameter set.
+func (ps *PSet) ShortNamesAllowed() bool { return ps.shortNamesAllowed }
+
+// SetShortNamesAllowed sets the flag allowing single-character short names
+// to be given for the parameters in this parameter set. See also
+// [SetShortNamesAllowed] (an option function that can be passed to
+// [NewSet]).
+//
+// Once this is set, parameters can be given a short name (see
+// [ShortName]) and these short names can then be given on the command line,
+// in the POSIX style, after a single dash. Several short names can be
+// bundled together after a single dash so that "-vx" is the same as "-v
+// -x". A short name can be repeated ("-vvv") in which case the parameter
+// will be processed each time it is seen; a psetter.Counter can be used to
+// count the occurrences. If a parameter with a short name takes a value
+// then the value can be given immediately after the short name ("-ofile"),
+// after an "=" ("-o=file") or as the next argument ("-o file"); any
+// characters following the short name are taken as the value and so it must
+// be the last short name in any bundle.
+//
+// Long parameter names can still be given after either of the parameter
+// prefixes. A parameter given after a single dash is first matched against
+// the long parameter names and only if it is not found is it treated as a
+// bundle of short names.
+//
+// This must be called before the parameters are parsed; this will panic
+// otherwise. It should also be called before any parameters with short
+// names are added.
+func (ps *PSet) SetShortNamesAllowed() {
+	ps.panicIfAlreadyParsed("can't allow short parameter names")
+
+	ps.shortNamesAllowed = true
+}
+
+// SetShortNamesAllowed is a PSetOptFunc that sets the flag allowing
+// single-character short names to be given for the parameters in this
+// parameter set. See also the [PSet.SetShortNamesAllowed] method.
+func SetShortNamesAllowed(ps *PSet) error {
+	ps.shortNamesAllowed = true
+
+	return nil
+}
+
+// ShortName returns the single-character short name of the parameter. If
+// the parameter has no short name it will return 0.
+func (p ByName) ShortName() rune { return p.shortName }
+
+// shortNameCheck returns an error if the rune cannot be used as a short
+// name. A short name must be an ASCII letter or digit.
+func shortNameCheck(r rune) error {
+	if (r >= 'a' && r <= 'z') ||
+		(r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9') {
+		return nil
+	}
+
+	return fmt.Errorf("the short name %q is invalid."+
+		" It must be a single letter or digit",
+		r)
+}
+
+// ShortName returns a ByNameOptFunc which will give the parameter a
+// single-character short name. It will return an error if short names are
+// not allowed for the parameter set (see [PSet.SetShortNamesAllowed]), if
+// the parameter already has a short name, if the short name is not a letter
+// or a digit or if the short name has already been used.
+func ShortName(r rune) ByNameOptFunc {
+	return func(p *ByName) error {
+		if !p.ps.shortNamesAllowed {
+			return fmt.Errorf("the short name %q cannot be given:"+
+				" short names are not allowed for this parameter set",
+				r)
+		}
+
+		if p.shortName != 0 {
+			return fmt.Errorf("the short name %q cannot be given:"+
+				" the parameter already has the short name %q",
+				r, p.shortName)
+		}
+
+		if err := shortNameCheck(r); err != nil {
+			return err
+		}
+
+		if altP, exists := p.ps.findShortName(r); exists {
+			return fmt.Errorf("short name %q has already been used"+
+				" by parameter %q",
+				r, altP.name)
+		}
+
+		if altP, exists := p.ps.findParam(string(r)); exists {
+			return fmt.Errorf("short name %q has already been used"+
+				" as a name of parameter %q",
+				r, altP.name)
+		}
+
+		for _, sc := range p.ps.descendantSubCmds() {
+			altP, exists := sc.ps.shortNameToParam[r]
+			if !exists {
+				altP, exists = sc.ps.nameToParam[string(r)]
+			}
+
+			if exists {
+				return fmt.Errorf("short name %q has already been used"+
+					" by parameter %q of the sub-command %q",
+					r, altP.name, sc.name)
+			}
+		}
+
+		p.shortName = r
+		p.ps.shortNameToParam[r] = p
+
+		return nil
+	}
+}
+
+// findShortName will look for the parameter with the given short name in
+// the PSet and, if it is not found, in any parent PSet.
+func (ps *PSet) findShortName(r rune) (*ByName, bool) {
+	for s := ps; s != nil; s = s.parent {
+		if p, ok := s.shortNameToParam[r]; ok {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+// isShortNameBundle returns true if the parameter should be processed as a
+// bundle of short names. This is the case if short names are allowed, the
+// parameter starts with a single ShortNamePrefix, it is not a long parameter
+// name and the first character is a short name.
+func (ps *PSet) isShortNameBundle(pStr string) bool {
+	if !ps.shortNamesAllowed {
+		return false
+	}
+
+	flags, ok := strings.CutPrefix(pStr, ShortNamePrefix)
+	if !ok || flags == "" || strings.HasPrefix(flags, ShortNamePrefix) {
+		return false
+	}
+
+	name, _, _ := strings.Cut(flags, "=")
+	if _, exists := ps.findParam(name); exists {
+		return false
+	}
+
+	_, exists := ps.findShortName([]rune(flags)[0])
+
+	return exists
+}
+
+// handleShortNames processes the i'th parameter as a bundle of short names.
+// It returns the index of the last parameter used which will be greater than
+// i if the value of the last short name was taken from the next parameter.
+func (ps *PSet) handleShortNames(loc *location.L, params []string, i int,
+) int {
+	flags := []rune(strings.TrimPrefix(params[i], ShortNamePrefix))
+
+	for j, r := range flags {
+		p, ok := ps.findShortName(r)
+		if !ok {
+			ps.AddErr(string(r),
+				&UnknownParamError{
+					ParamErrDetails: mkParamErrDetails(string(r), loc),
+					ShortName:       true,
+				})
+
+			return i
+		}
+
+		paramParts := []string{p.name}
+		rest := string(flags[j+1:])
+
+		switch p.setter.ValueReq() {
+		case Mandatory:
+			rest = strings.TrimPrefix(rest, "=")
+			if rest != "" {
+				paramParts = append(paramParts, rest)
+			} else if i < (len(params) - 1) {
+				i++
+
+				loc.Incr()
+
+				paramParts = append(paramParts, params[i])
+
+				loc.SetContent(
+					fmt.Sprintf("%q %q", params[i-1], params[i]))
+			}
+
+			p.processParam(loc, paramParts)
+
+			return i
+		case Optional:
+			if val, hasVal := strings.CutPrefix(rest, "="); hasVal {
+				paramParts = append(paramParts, val)
+				p.processParam(loc, cleanParamParts(p, paramParts))
+
+				return i
+			}
+		}
+
+		p.processParam(loc, paramParts)
+
+		if ps.terminalParamSeen {
+			return i
+		}
+	}
+
+	return i
+}
@@ -0,0 +1,198 @@
+package param_test
+
+import (
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// shortNameTestVals holds the values set by the short name test parameters
+type shortNameTestVals struct {
+	verbosity int
+	dryRun    bool
+	colour    bool
+	out       string
+}
+
+// mkShortNamePSet creates a PSet with short names allowed and some
+// parameters with short names
+func mkShortNamePSet(v *shortNameTestVals) *param.PSet {
+	ps := paramset.NewNoHelpNoExitNoErrRpt(param.SetShortNamesAllowed)
+
+	ps.Add("verbose", psetter.Counter[int]{Value: &v.verbosity},
+		"be more verbose",
+		param.ShortName('v'))
+	ps.Add("dry-run", psetter.Bool{Value: &v.dryRun},
+		"don't do anything",
+		param.ShortName('n'))
+	ps.Add("colour", psetter.Bool{Value: &v.colour},
+		"use colour",
+		param.ShortName('c'))
+	ps.Add("output", psetter.String[string]{Value: &v.out},
+		"where to write",
+		param.ShortName('o'))
+
+	return ps
+}
+
+func TestShortNameParse(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		params  []string
+		expErrs map[string][]string
+		expVals shortNameTestVals
+	}{
+		{
+			ID:      testhelper.MkID("single short names"),
+			params:  []string{"-v", "-n"},
+			expVals: shortNameTestVals{verbosity: 1, dryRun: true},
+		},
+		{
+			ID:      testhelper.MkID("bundled and repeated short names"),
+			params:  []string{"-vvn", "-v"},
+			expVals: shortNameTestVals{verbosity: 3, dryRun: true},
+		},
+		{
+			ID:      testhelper.MkID("long names with either prefix"),
+			params:  []string{"-verbose", "--dry-run", "--verbose"},
+			expVals: shortNameTestVals{verbosity: 2, dryRun: true},
+		},
+		{
+			ID:      testhelper.MkID("attached value"),
+			params:  []string{"-vofile"},
+			expVals: shortNameTestVals{verbosity: 1, out: "file"},
+		},
+		{
+			ID:      testhelper.MkID("attached value after '='"),
+			params:  []string{"-o=file"},
+			expVals: shortNameTestVals{out: "file"},
+		},
+		{
+			ID:      testhelper.MkID("value as next param"),
+			params:  []string{"-no", "file", "-v"},
+			expVals: shortNameTestVals{verbosity: 1, dryRun: true, out: "file"},
+		},
+		{
+			ID:      testhelper.MkID("optional value"),
+			params:  []string{"-vc=false", "-n"},
+			expVals: shortNameTestVals{verbosity: 1, dryRun: true},
+		},
+		{
+			ID:     testhelper.MkID("short names after '--' prefix"),
+			params: []string{"--vn"},
+			expErrs: map[string][]string{
+				"vn": {"this is not a parameter of this program"},
+			},
+		},
+		{
+			ID:     testhelper.MkID("unknown short name in bundle"),
+			params: []string{"-vxn"},
+			expErrs: map[string][]string{
+				"x": {
+					"'x' is not the short name of any parameter" +
+						" of this program",
+				},
+			},
+			expVals: shortNameTestVals{verbosity: 1},
+		},
+		{
+			ID:     testhelper.MkID("missing value"),
+			params: []string{"-o"},
+			expErrs: map[string][]string{
+				"output": {"a value must follow this parameter"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var v shortNameTestVals
+
+		ps := mkShortNamePSet(&v)
+		ps.Parse(tc.params)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+		testhelper.DiffInt(t, tc.IDStr(), "verbosity",
+			v.verbosity, tc.expVals.verbosity)
+		testhelper.DiffBool(t, tc.IDStr(), "dry-run",
+			v.dryRun, tc.expVals.dryRun)
+		testhelper.DiffBool(t, tc.IDStr(), "colour",
+			v.colour, tc.expVals.colour)
+		testhelper.DiffString(t, tc.IDStr(), "output",
+			v.out, tc.expVals.out)
+	}
+}
+
+func TestShortNameAdd(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		noShortNames bool
+		name         string
+		opts         []param.ByNameOptFunc
+	}{
+		{
+			ID:   testhelper.MkID("good"),
+			name: "new",
+			opts: []param.ByNameOptFunc{param.ShortName('N')},
+		},
+		{
+			ID:           testhelper.MkID("short names not allowed"),
+			noShortNames: true,
+			name:         "new",
+			opts:         []param.ByNameOptFunc{param.ShortName('N')},
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add named parameter: "new"`,
+				"short names are not allowed for this parameter set"),
+		},
+		{
+			ID:   testhelper.MkID("bad short name"),
+			name: "new",
+			opts: []param.ByNameOptFunc{param.ShortName('-')},
+			ExpPanic: testhelper.MkExpPanic(
+				`the short name '-' is invalid`),
+		},
+		{
+			ID:   testhelper.MkID("two short names"),
+			name: "new",
+			opts: []param.ByNameOptFunc{
+				param.ShortName('N'),
+				param.ShortName('M'),
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`the parameter already has the short name 'N'`),
+		},
+		{
+			ID:   testhelper.MkID("duplicate short name"),
+			name: "new",
+			opts: []param.ByNameOptFunc{param.ShortName('v')},
+			ExpPanic: testhelper.MkExpPanic(
+				`short name 'v' has already been used by parameter "verbose"`),
+		},
+		{
+			ID:   testhelper.MkID("long name clashes with short name"),
+			name: "v",
+			ExpPanic: testhelper.MkExpPanic(
+				`parameter name "v" has already been used` +
+					` as the short name of "verbose"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		var v shortNameTestVals
+
+		ps := mkShortNamePSet(&v)
+		if tc.noShortNames {
+			ps = paramset.NewNoHelpNoExitNoErrRpt()
+		}
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			var b bool
+			ps.Add(tc.name, psetter.Bool{Value: &b}, "desc", tc.opts...)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
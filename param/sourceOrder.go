@@ -0,0 +1,114 @@
+package param
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// SourceType identifies one of the sources from which parameter values are
+// taken. The sources are processed in turn with later sources overriding
+// the values set by earlier ones.
+type SourceType int
+
+// These are the sources of parameter values.
+//
+// GroupConfigFileSource is the configuration files for the parameter groups.
+//
+// ConfigFileSource is the configuration files for the program.
+//
+// EnvFileSource is the env files.
+//
+// EnvironmentSource is the environment variables.
+//
+// CommandLineSource is the command line (or the parameters passed to the
+// Parse method).
+//
+// FinalConfigFileSource is the final configuration files. By default these
+// are processed after the command line so values given there cannot be
+// overridden.
+const (
+	GroupConfigFileSource SourceType = iota
+	ConfigFileSource
+	EnvFileSource
+	EnvironmentSource
+	CommandLineSource
+	FinalConfigFileSource
+)
+
+var sourceTypeNames = map[SourceType]string{
+	GroupConfigFileSource: "group config files",
+	ConfigFileSource:      "config files",
+	EnvFileSource:         "env files",
+	EnvironmentSource:     "environment",
+	CommandLineSource:     "command line",
+	FinalConfigFileSource: "final config files",
+}
+
+// String returns a description of the SourceType
+func (st SourceType) String() string {
+	if name, ok := sourceTypeNames[st]; ok {
+		return name
+	}
+
+	return "SourceType(" + strconv.Itoa(int(st)) + ")"
+}
+
+// dfltSourceOrder is the order in which the sources are processed if no
+// order has been given
+var dfltSourceOrder = []SourceType{
+	GroupConfigFileSource,
+	ConfigFileSource,
+	EnvFileSource,
+	EnvironmentSource,
+	CommandLineSource,
+	FinalConfigFileSource,
+}
+
+// SetSourceOrder returns a PSetOptFunc which will set the order in which
+// the sources of parameter values are processed. Values set by later
+// sources will replace those set by earlier ones, so a source given after
+// the CommandLineSource can be used for settings which the user cannot
+// override.
+//
+// Any source which is not given is disabled and will not be used even if,
+// for instance, configuration files have been added.
+//
+// It will return an error if any source is unknown or is given more than
+// once or if the CommandLineSource is not given.
+func SetSourceOrder(srcs ...SourceType) PSetOptFunc {
+	return func(ps *PSet) error {
+		for i, st := range srcs {
+			if _, ok := sourceTypeNames[st]; !ok {
+				return fmt.Errorf("unknown parameter source: %s", st)
+			}
+
+			if slices.Contains(srcs[:i], st) {
+				return fmt.Errorf(
+					"the parameter source %q is given more than once", st)
+			}
+		}
+
+		if !slices.Contains(srcs, CommandLineSource) {
+			return errors.New(
+				"the parameter sources must include the command line")
+		}
+
+		ps.sourceOrder = slices.Clone(srcs)
+
+		return nil
+	}
+}
+
+// SourceOrder returns a copy of the order in which the sources of
+// parameter values are processed. Only enabled sources are given.
+func (ps *PSet) SourceOrder() []SourceType {
+	return slices.Clone(ps.sourceOrder)
+}
+
+// SourceIsEnabled returns true if the source of parameter values will be
+// used, false otherwise.
+func (ps PSet) SourceIsEnabled(st SourceType) bool {
+	return slices.Contains(ps.sourceOrder, st)
+}
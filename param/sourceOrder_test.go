@@ -0,0 +1,184 @@
+package param_test
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSetSourceOrder(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		srcs     []param.SourceType
+		expOrder []param.SourceType
+	}{
+		{
+			ID: testhelper.MkID("good"),
+			srcs: []param.SourceType{
+				param.EnvironmentSource,
+				param.ConfigFileSource,
+				param.CommandLineSource,
+			},
+			expOrder: []param.SourceType{
+				param.EnvironmentSource,
+				param.ConfigFileSource,
+				param.CommandLineSource,
+			},
+		},
+		{
+			ID:       testhelper.MkID("command line only"),
+			srcs:     []param.SourceType{param.CommandLineSource},
+			expOrder: []param.SourceType{param.CommandLineSource},
+		},
+		{
+			ID: testhelper.MkID("duplicate"),
+			srcs: []param.SourceType{
+				param.ConfigFileSource,
+				param.CommandLineSource,
+				param.ConfigFileSource,
+			},
+			ExpErr: testhelper.MkExpErr(
+				`the parameter source "config files" is given more than once`),
+		},
+		{
+			ID: testhelper.MkID("unknown"),
+			srcs: []param.SourceType{
+				param.CommandLineSource,
+				param.SourceType(99),
+			},
+			ExpErr: testhelper.MkExpErr(
+				"unknown parameter source: SourceType(99)"),
+		},
+		{
+			ID:   testhelper.MkID("no command line"),
+			srcs: []param.SourceType{param.ConfigFileSource},
+			ExpErr: testhelper.MkExpErr(
+				"the parameter sources must include the command line"),
+		},
+	}
+
+	for _, tc := range testCases {
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		dfltOrder := ps.SourceOrder()
+
+		err := param.SetSourceOrder(tc.srcs...)(ps)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			if !slices.Equal(ps.SourceOrder(), tc.expOrder) {
+				t.Log(tc.IDStr())
+				t.Logf("\t: expected: %v", tc.expOrder)
+				t.Logf("\t:      got: %v", ps.SourceOrder())
+				t.Error("\t: unexpected source order")
+			}
+		} else if !slices.Equal(ps.SourceOrder(), dfltOrder) {
+			t.Log(tc.IDStr())
+			t.Error("\t: the source order was changed despite the error")
+		}
+	}
+}
+
+func TestSourceOrderParse(t *testing.T) {
+	const (
+		envVar   = "PTEST_SO_NAME"
+		cfgFile  = "testdata/sourceOrder.cfg"
+		lastFile = "testdata/sourceOrder-final.cfg"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		srcs    []param.SourceType
+		env     string
+		args    []string
+		expName string
+	}{
+		{
+			ID:      testhelper.MkID("default order, final file wins"),
+			env:     "env",
+			args:    []string{"-name", "cmdline"},
+			expName: "final",
+		},
+		{
+			ID: testhelper.MkID("final file disabled"),
+			srcs: []param.SourceType{
+				param.ConfigFileSource,
+				param.EnvironmentSource,
+				param.CommandLineSource,
+			},
+			env:     "env",
+			args:    []string{"-name", "cmdline"},
+			expName: "cmdline",
+		},
+		{
+			ID: testhelper.MkID("environment before config file"),
+			srcs: []param.SourceType{
+				param.EnvironmentSource,
+				param.ConfigFileSource,
+				param.CommandLineSource,
+			},
+			env:     "env",
+			expName: "config",
+		},
+		{
+			ID: testhelper.MkID("config file before environment"),
+			srcs: []param.SourceType{
+				param.ConfigFileSource,
+				param.EnvironmentSource,
+				param.CommandLineSource,
+			},
+			env:     "env",
+			expName: "env",
+		},
+		{
+			ID: testhelper.MkID("config file after command line"),
+			srcs: []param.SourceType{
+				param.CommandLineSource,
+				param.ConfigFileSource,
+			},
+			env:     "env",
+			args:    []string{"-name", "cmdline"},
+			expName: "config",
+		},
+		{
+			ID: testhelper.MkID("environment disabled"),
+			srcs: []param.SourceType{
+				param.CommandLineSource,
+			},
+			env: "env",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Setenv(envVar, tc.env)
+
+			if tc.env == "" {
+				_ = os.Unsetenv(envVar)
+			}
+
+			var psofs []param.PSetOptFunc
+			if tc.srcs != nil {
+				psofs = append(psofs, param.SetSourceOrder(tc.srcs...))
+			}
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt(psofs...)
+			ps.AddConfigFile(cfgFile, filecheck.MustExist)
+			ps.AddFinalConfigFile(lastFile, filecheck.MustExist)
+
+			var name string
+
+			ps.Add("name", psetter.String[string]{Value: &name}, "desc",
+				param.EnvVar(envVar))
+
+			ps.Parse(tc.args)
+
+			errMapCheck(t, tc.IDStr(), ps.Errors(), nil)
+			testhelper.DiffString(t, tc.IDStr(), "name", name, tc.expName)
+		})
+	}
+}
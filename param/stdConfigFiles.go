@@ -0,0 +1,98 @@
+package param
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/xdg.mod/xdg"
+)
+
+// StdConfigFileName is the name of the config file in each of the
+// directories searched by StdConfigFiles
+const StdConfigFileName = "common.cfg"
+
+// StdConfigFiles returns a PSetOptFunc which will add the standard config
+// files for the program. These follow the XDG Base Directory Specification
+// and are, in the order in which they are read:
+//
+//   - <dir>/<org>/<prog>/common.cfg for each directory in $XDG_CONFIG_DIRS
+//     (by default /etc/xdg). The directories are read in reverse order so
+//     that the first, most important, directory is read last.
+//   - <config-home>/<org>/<prog>/common.cfg where config-home is
+//     $XDG_CONFIG_HOME (by default $HOME/.config)
+//   - .<prog>rc in the current directory or, if there is no such file, the
+//     nearest parent directory having one. This allows settings local to a
+//     project.
+//
+// As the files are read in this order the settings in later files will
+// replace those in earlier ones so a user's settings will override the
+// system-wide ones and the project-local settings will override both.
+//
+// The files need not exist. They are program-specific and so any parameter
+// given in them must be a valid parameter of the program. The org may be
+// empty in which case that part of the path is omitted.
+//
+// It will return an error if the program name is empty or contains a path
+// separator.
+func StdConfigFiles(org, prog string) PSetOptFunc {
+	return func(ps *PSet) error {
+		if prog == "" {
+			return errors.New("the program name must not be empty")
+		}
+
+		if strings.ContainsRune(prog, filepath.Separator) {
+			return errors.New(
+				"the program name must not contain a path separator")
+		}
+
+		for _, dir := range slices.Backward(xdg.ConfigDirs()) {
+			if dir == "" {
+				continue
+			}
+
+			ps.AddConfigFileStrict(
+				filepath.Join(dir, org, prog, StdConfigFileName),
+				filecheck.Optional)
+		}
+
+		if dir := xdg.ConfigHome(); dir != "" {
+			ps.AddConfigFileStrict(
+				filepath.Join(dir, org, prog, StdConfigFileName),
+				filecheck.Optional)
+		}
+
+		if rcFile := findRCFile("." + prog + "rc"); rcFile != "" {
+			ps.AddConfigFileStrict(rcFile, filecheck.Optional)
+		}
+
+		return nil
+	}
+}
+
+// findRCFile searches the current directory and each of its parent
+// directories in turn for a file with the given name. It returns the
+// pathname of the first one found or the empty string if there is none.
+func findRCFile(name string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		fName := filepath.Join(dir, name)
+		if info, err := os.Stat(fName); err == nil && info.Mode().IsRegular() {
+			return fName
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
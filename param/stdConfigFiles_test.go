@@ -0,0 +1,101 @@
+//go:build linux
+
+package param_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestStdConfigFiles(t *testing.T) {
+	base := t.TempDir()
+	projDir := filepath.Join(base, "proj")
+	workDir := filepath.Join(projDir, "src", "pkg")
+
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatal("cannot make the working directory: ", err)
+	}
+
+	rcFile := filepath.Join(projDir, ".myprogrc")
+	if err := os.WriteFile(rcFile, []byte("# rc file\n"), 0o644); err != nil {
+		t.Fatal("cannot make the rc file: ", err)
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		org, prog  string
+		configDirs string
+		dir        string
+		expFiles   []string
+	}{
+		{
+			ID:         testhelper.MkID("all files"),
+			org:        "myorg",
+			prog:       "myprog",
+			configDirs: "/etc/first:/etc/second",
+			dir:        workDir,
+			expFiles: []string{
+				"/etc/second/myorg/myprog/common.cfg",
+				"/etc/first/myorg/myprog/common.cfg",
+				"/home/cfg/myorg/myprog/common.cfg",
+				rcFile,
+			},
+		},
+		{
+			ID:   testhelper.MkID("defaults, no org, no rc file"),
+			prog: "myprog",
+			dir:  base,
+			expFiles: []string{
+				"/etc/xdg/myprog/common.cfg",
+				"/home/cfg/myprog/common.cfg",
+			},
+		},
+		{
+			ID:     testhelper.MkID("no program name"),
+			dir:    base,
+			ExpErr: testhelper.MkExpErr("the program name must not be empty"),
+		},
+		{
+			ID:   testhelper.MkID("bad program name"),
+			prog: "my/prog",
+			dir:  base,
+			ExpErr: testhelper.MkExpErr(
+				"the program name must not contain a path separator"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_DIRS", tc.configDirs)
+			t.Setenv("XDG_CONFIG_HOME", "/home/cfg")
+			t.Chdir(tc.dir)
+
+			ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+			err := param.StdConfigFiles(tc.org, tc.prog)(ps)
+			if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+				return
+			}
+
+			var files []string
+			for _, cf := range ps.ConfigFiles() {
+				files = append(files, cf.Name)
+
+				if !cf.ParamsMustExist() {
+					t.Log(tc.IDStr())
+					t.Errorf("\t: %s: the config file should be strict",
+						cf.Name)
+				}
+			}
+
+			testhelper.DiffStringSlice(t, tc.IDStr(), "config files",
+				files, tc.expFiles)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package param
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+// subCmdSep separates the sub-command name from the parameter name when a
+// sub-command parameter is given in a configuration file or through an
+// environment variable
+const subCmdSep = "/"
+
+// SubCommand records the details of a sub-command. A sub-command is a word
+// given on the command line (without any parameter prefix) which selects a
+// particular mode of operation of the program, in the style of the 'git' or
+// 'go' commands. Each sub-command has its own parameter set which inherits
+// all the parameters of the parent parameter set.
+type SubCommand struct {
+	name       string
+	desc       string
+	ps         *PSet
+	whereAdded string
+}
+
+// Name returns the name of the sub-command
+func (sc SubCommand) Name() string { return sc.name }
+
+// Desc returns the description of the sub-command
+func (sc SubCommand) Desc() string { return sc.desc }
+
+// PSet returns the sub-command's parameter set. You can use this to get the
+// trailing parameters (if any) given after the sub-command.
+func (sc SubCommand) PSet() *PSet { return sc.ps }
+
+// AddSubCommand adds a new sub-command to the parameter set. The function f
+// is called with the new, child, parameter set and should add the
+// parameters, groups, notes etc specific to the sub-command. The child
+// parameter set inherits the parameters of its parent so any of the parent's
+// parameters can also be given after the sub-command name. The names of
+// the child's parameters must not clash with those of its parent; this is
+// checked whichever is added first.
+//
+// On the command line the sub-command is given as a word without any
+// parameter prefix. Any parameters following it are processed by the
+// sub-command's parameter set. If a parameter set has any sub-commands then
+// one of them must be given.
+//
+// A sub-command parameter can be given in a configuration file by giving
+// the sub-command name and a slash ("/") before the parameter name. This must
+// itself be preceded by a list of program names, so the line would look like:
+//
+//	prog/subcmd/param = value
+//
+// The sub-command name must be a valid parameter name and must not have
+// been used already. Any sub-commands must be added before the parameters
+// are parsed; this will panic otherwise.
+func (ps *PSet) AddSubCommand(name, desc string, f func(*PSet)) *SubCommand {
+	panicPrefix := fmt.Sprintf("can't add sub-command: %q", name)
+
+	ps.panicIfAlreadyParsed(panicPrefix)
+
+	name = strings.TrimSpace(name)
+
+	if err := ParameterNameCheck(name); err != nil {
+		panic(fmt.Errorf("%s: %w", panicPrefix, err))
+	}
+
+	whereAdded := caller()
+
+	if sc, exists := ps.subCmds[name]; exists {
+		panic(fmt.Errorf("%s: it has already been added at: %s",
+			panicPrefix, sc.whereAdded))
+	}
+
+	ppCount := len(ps.byPos)
+	if ppCount > 0 && ps.byPos[ppCount-1].isTerminal {
+		panic(fmt.Errorf("%s: it can never be used as"+
+			" the param set has a terminal positional parameter",
+			panicPrefix))
+	}
+
+	child := newSet(ps.helper)
+	child.parent = ps
+	child.subCmdName = name
+	child.progDesc = desc
+	child.shortNamesAllowed = ps.shortNamesAllowed
+	child.abbreviationsAllowed = ps.abbreviationsAllowed
+
+	sc := &SubCommand{
+		name:       name,
+		desc:       desc,
+		ps:         child,
+		whereAdded: whereAdded,
+	}
+	ps.subCmds[name] = sc
+
+	if f != nil {
+		f(child)
+	}
+
+	return sc
+}
+
+// HasSubCommands returns true if the PSet has any sub-commands
+func (ps *PSet) HasSubCommands() bool {
+	return len(ps.subCmds) > 0
+}
+
+// SubCommands returns the sub-commands of the PSet sorted by name
+func (ps *PSet) SubCommands() []*SubCommand {
+	names := slices.Sorted(maps.Keys(ps.subCmds))
+
+	scs := make([]*SubCommand, 0, len(names))
+	for _, n := range names {
+		scs = append(scs, ps.subCmds[n])
+	}
+
+	return scs
+}
+
+// GetSubCommand returns the named sub-command if it can be found. The error
+// will be set if not.
+func (ps *PSet) GetSubCommand(name string) (*SubCommand, error) {
+	if sc, ok := ps.subCmds[name]; ok {
+		return sc, nil
+	}
+
+	return nil, fmt.Errorf("sub-command %q does not exist", name)
+}
+
+// SubCommandChosen returns the sub-command given on the command line. It
+// will be nil if no sub-command has been chosen or if the parameters have
+// not yet been parsed.
+func (ps *PSet) SubCommandChosen() *SubCommand { return ps.subCmdChosen }
+
+// Parent returns the parent parameter set of a sub-command's parameter
+// set. For any other parameter set it will return nil.
+func (ps *PSet) Parent() *PSet { return ps.parent }
+
+// SubCommandName returns the name of the sub-command for which this is the
+// parameter set. For a parameter set which is not for a sub-command this
+// will return the empty string.
+func (ps *PSet) SubCommandName() string { return ps.subCmdName }
+
+// findParam will look for the named parameter in the PSet and, if it is not
+// found, in any parent PSet.
+func (ps *PSet) findParam(name string) (*ByName, bool) {
+	for s := ps; s != nil; s = s.parent {
+		if p, ok := s.nameToParam[name]; ok {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+// descendantSubCmds returns the sub-commands of the parameter set and those
+// of each of its sub-commands, in turn, in name order
+func (ps *PSet) descendantSubCmds() []*SubCommand {
+	var scs []*SubCommand
+
+	for _, name := range slices.Sorted(maps.Keys(ps.subCmds)) {
+		sc := ps.subCmds[name]
+		scs = append(scs, sc)
+		scs = append(scs, sc.ps.descendantSubCmds()...)
+	}
+
+	return scs
+}
+
+// setSubCmdValue sets the value of a parameter given with a sub-command
+// name prefix. If the sub-command is not recognised the parameter is
+// treated as any other unknown parameter.
+func (ps *PSet) setSubCmdValue(
+	scName, pName string,
+	paramParts []string, loc *location.L, eRule existenceRule, gName string,
+) {
+	sc, ok := ps.subCmds[scName]
+	if !ok {
+		if eRule == paramMustExist {
+			ps.recordUnexpectedParam(paramParts[0], loc)
+		} else {
+			ps.markAsUnused(paramParts[0], loc)
+		}
+
+		return
+	}
+
+	scParts := append([]string{pName}, paramParts[1:]...)
+	sc.ps.setValue(scParts, loc, eRule, gName)
+}
+
+// parseSubCommand records the chosen sub-command and passes the remaining
+// parameters to the sub-command's PSet for processing. The checks for the
+// sub-command, such as those for mandatory parameters and constraints, are
+// made after all the parameter sources have been processed (see
+// runPostParseChecks).
+func (ps *PSet) parseSubCommand(
+	sc *SubCommand, loc *location.L, params []string,
+) {
+	ps.subCmdChosen = sc
+
+	c := sc.ps
+	c.progName = ps.progName + " " + sc.name
+	c.progBaseName = ps.progBaseName + " " + sc.name
+	c.paramPrefixes = ps.paramPrefixes
+	c.shortestPrefix = ps.shortestPrefix
+	c.terminalParam = ps.terminalParam
+	c.shortNamesAllowed = ps.shortNamesAllowed
+	c.abbreviationsAllowed = ps.abbreviationsAllowed
+
+	c.getParamsFromStringSlice(loc, params)
+	c.reportUnexpectedTrailingParams()
+}
+
+// detectMissingSubCommand adds an error if the PSet has sub-commands but
+// none was chosen.
+func (ps *PSet) detectMissingSubCommand() {
+	if len(ps.subCmds) == 0 || ps.subCmdChosen != nil {
+		return
+	}
+
+	ps.AddErr("",
+		&MissingSubCommandError{
+			SubCommands: slices.Sorted(maps.Keys(ps.subCmds)),
+		})
+}
+
+// prepareSubCommands marks the sub-command PSets as parsed and performs the
+// same consistency checks as are applied to the parent PSet. It is applied
+// recursively to the sub-commands of the sub-commands.
+func (ps *PSet) prepareSubCommands() {
+	for _, sc := range ps.SubCommands() {
+		c := sc.ps
+		c.parsed = true
+		c.parseCalledFrom = ps.parseCalledFrom
+
+		c.checkForTerminalParams()
+		c.checkSeeRefs()
+		c.checkDeprecations()
+		c.checkConstraints()
+		c.prepareSubCommands()
+	}
+}
@@ -0,0 +1,282 @@
+package param_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// subCmdTestVals holds the values set by the sub-command test parameters
+type subCmdTestVals struct {
+	verbose bool
+	target  string
+	force   bool
+}
+
+// mkSubCmdPSet creates a PSet with a parent parameter and two sub-commands
+func mkSubCmdPSet(v *subCmdTestVals) *param.PSet {
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+	ps.Add("verbose", psetter.Bool{Value: &v.verbose}, "be verbose")
+
+	ps.AddSubCommand("build", "build the thing", func(ps *param.PSet) {
+		ps.Add("target", psetter.String[string]{Value: &v.target},
+			"what to build")
+	})
+	ps.AddSubCommand("clean", "clean up", func(ps *param.PSet) {
+		ps.Add("force", psetter.Bool{Value: &v.force}, "remove everything")
+	})
+
+	return ps
+}
+
+func TestSubCommandParse(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		params    []string
+		expErrs   map[string][]string
+		expSubCmd string
+		expVals   subCmdTestVals
+	}{
+		{
+			ID:        testhelper.MkID("subcmd with own and parent params"),
+			params:    []string{"build", "-target", "x", "-verbose"},
+			expSubCmd: "build",
+			expVals:   subCmdTestVals{verbose: true, target: "x"},
+		},
+		{
+			ID:        testhelper.MkID("parent param before subcmd"),
+			params:    []string{"-verbose", "clean", "-force"},
+			expSubCmd: "clean",
+			expVals:   subCmdTestVals{verbose: true, force: true},
+		},
+		{
+			ID:     testhelper.MkID("no subcmd"),
+			params: []string{"-verbose"},
+			expErrs: map[string][]string{
+				"": {`a sub-command must be given, one of: "build" or "clean"`},
+			},
+			expVals: subCmdTestVals{verbose: true},
+		},
+		{
+			ID:        testhelper.MkID("other subcmd param"),
+			params:    []string{"clean", "-target=x"},
+			expSubCmd: "clean",
+			expErrs: map[string][]string{
+				"target": {"this is not a parameter of this program"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var v subCmdTestVals
+
+		ps := mkSubCmdPSet(&v)
+		ps.Parse(tc.params)
+
+		errMapCheck(t, tc.IDStr(), ps.Errors(), tc.expErrs)
+
+		subCmd := ""
+		if sc := ps.SubCommandChosen(); sc != nil {
+			subCmd = sc.Name()
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "sub-command",
+			subCmd, tc.expSubCmd)
+		testhelper.DiffBool(t, tc.IDStr(), "verbose",
+			v.verbose, tc.expVals.verbose)
+		testhelper.DiffString(t, tc.IDStr(), "target",
+			v.target, tc.expVals.target)
+		testhelper.DiffBool(t, tc.IDStr(), "force",
+			v.force, tc.expVals.force)
+	}
+}
+
+func TestSubCommandConfigFile(t *testing.T) {
+	var v subCmdTestVals
+
+	ps := mkSubCmdPSet(&v)
+
+	fName := filepath.Join(t.TempDir(), "subcmd.cfg")
+
+	err := os.WriteFile(fName,
+		// the program name is the default as no arguments are taken
+		// from the command line
+		[]byte("verbose\nPROGRAM NAME UNKNOWN/build/target = y\n"),
+		0o600)
+	if err != nil {
+		t.Fatal("couldn't create the config file:", err)
+	}
+
+	ps.SetConfigFile(fName, filecheck.MustExist)
+	ps.Parse([]string{})
+
+	errMapCheck(t, "subcmd config file", ps.Errors(),
+		map[string][]string{
+			"": {"a sub-command must be given"},
+		})
+	testhelper.DiffBool(t, "subcmd config file", "verbose", v.verbose, true)
+	testhelper.DiffString(t, "subcmd config file", "target", v.target, "y")
+}
+
+func TestSubCommandFinalConfigFile(t *testing.T) {
+	const id = "subcmd final config file"
+
+	var v subCmdTestVals
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+
+	ps.Add("verbose", psetter.Bool{Value: &v.verbose}, "be verbose")
+
+	ps.AddSubCommand("build", "build the thing", func(ps *param.PSet) {
+		ps.Add("target", psetter.String[string]{Value: &v.target},
+			"what to build")
+		ps.AddConstraint(param.Requires("target", "verbose"))
+	})
+
+	fName := filepath.Join(t.TempDir(), "final.cfg")
+
+	err := os.WriteFile(fName, []byte("verbose\n"), 0o600)
+	if err != nil {
+		t.Fatal("couldn't create the config file:", err)
+	}
+
+	ps.AddFinalConfigFile(fName, filecheck.MustExist)
+	ps.Parse([]string{"build", "-target", "x"})
+
+	errMapCheck(t, id, ps.Errors(), nil)
+	testhelper.DiffBool(t, id, "verbose", v.verbose, true)
+	testhelper.DiffString(t, id, "target", v.target, "x")
+}
+
+func TestAddSubCommand(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		name  string
+		adder func(*param.PSet)
+	}{
+		{
+			ID:   testhelper.MkID("good"),
+			name: "new",
+		},
+		{
+			ID:   testhelper.MkID("bad name"),
+			name: "-new",
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add sub-command: "-new"`,
+				badParamErrorValue),
+		},
+		{
+			ID:   testhelper.MkID("duplicate name"),
+			name: "build",
+			ExpPanic: testhelper.MkExpPanic(
+				`can't add sub-command: "build"`,
+				"it has already been added at:"),
+		},
+		{
+			ID:   testhelper.MkID("param name clashes with parent"),
+			name: "new",
+			adder: func(ps *param.PSet) {
+				var b bool
+				ps.Add("verbose", psetter.Bool{Value: &b}, "desc")
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`parameter name "verbose" has already been used`),
+		},
+	}
+
+	for _, tc := range testCases {
+		var v subCmdTestVals
+
+		ps := mkSubCmdPSet(&v)
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.AddSubCommand(tc.name, "desc", tc.adder)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
+
+func TestAddParamAfterSubCommand(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		name  string
+		opts  []param.ByNameOptFunc
+		adder func(*param.PSet)
+	}{
+		{
+			ID:   testhelper.MkID("good"),
+			name: "new",
+		},
+		{
+			ID:   testhelper.MkID("name used by a sub-command"),
+			name: "target",
+			ExpPanic: testhelper.MkExpPanic(
+				`parameter name "target" has already been used`,
+				`by the sub-command "build"`),
+		},
+		{
+			ID:   testhelper.MkID("alt name used by a nested sub-command"),
+			name: "new",
+			opts: []param.ByNameOptFunc{param.AltNames("deep")},
+			adder: func(ps *param.PSet) {
+				ps.AddSubCommand("nested", "desc", func(ps *param.PSet) {
+					var b bool
+					ps.Add("deep", psetter.Bool{Value: &b}, "desc")
+				})
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`parameter name "deep" has already been used`,
+				`by the sub-command "nested"`),
+		},
+		{
+			ID:   testhelper.MkID("name used as a sub-command short name"),
+			name: "f",
+			adder: func(ps *param.PSet) {
+				var b bool
+				ps.Add("fast", psetter.Bool{Value: &b}, "desc",
+					param.ShortName('f'))
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`parameter name "f" has already been used`,
+				`as the short name of "fast" by the sub-command "new"`),
+		},
+		{
+			ID:   testhelper.MkID("short name used by a sub-command"),
+			name: "quiet",
+			opts: []param.ByNameOptFunc{param.ShortName('f')},
+			adder: func(ps *param.PSet) {
+				var b bool
+				ps.Add("fast", psetter.Bool{Value: &b}, "desc",
+					param.ShortName('f'))
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				`short name 'f' has already been used`,
+				`by parameter "fast" of the sub-command "new"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		var (
+			v subCmdTestVals
+			b bool
+		)
+
+		ps := mkSubCmdPSet(&v)
+		ps.SetShortNamesAllowed()
+		ps.AddSubCommand("new", "desc", tc.adder)
+
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			ps.Add(tc.name, psetter.Bool{Value: &b}, "desc", tc.opts...)
+		})
+		testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+	}
+}
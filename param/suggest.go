@@ -12,9 +12,20 @@ import (
 type SuggestionFunc func(ps *PSet, s string) []string
 
 // SuggestParams finds those parameter names the shortest distance from the
-// passed value and returns them
+// passed value and returns them. For a sub-command's PSet the names of the
+// parameters of any parent PSet are also considered. Deprecated names are
+// never suggested.
 func SuggestParams(ps *PSet, s string) []string {
-	return strdist.SuggestedVals(s, slices.Collect(maps.Keys(ps.nameToParam)))
+	var names []string
+	for pSet := ps; pSet != nil; pSet = pSet.parent {
+		for name := range pSet.nameToParam {
+			if !pSet.isDeprecatedName(name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return strdist.SuggestedVals(s, names)
 }
 
 // SuggestGroups finds those group names the shortest distance from the
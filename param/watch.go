@@ -0,0 +1,233 @@
+package param
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// DfltWatchInterval is the default interval between checks for changes to
+// the config files and env files when watching for changes
+const DfltWatchInterval = 5 * time.Second
+
+// watcher holds the settings used when watching for changes to the
+// parameter sources
+type watcher struct {
+	interval time.Duration
+	errFunc  func(error)
+}
+
+// WatchOptFunc is the type of a function that can be passed to the
+// PSet.Watch method to change how the parameter sources are watched.
+type WatchOptFunc = ptypes.OptFunc[watcher]
+
+// WatchInterval returns a WatchOptFunc which will set the interval between
+// checks for changes to the config files and env files. It will return an
+// error if the interval is not greater than zero.
+func WatchInterval(d time.Duration) WatchOptFunc {
+	return func(w *watcher) error {
+		if d <= 0 {
+			return fmt.Errorf(
+				"the watch interval (%s) must be greater than zero", d)
+		}
+
+		w.interval = d
+
+		return nil
+	}
+}
+
+// WatchErrFunc returns a WatchOptFunc which will set the function to be
+// called with any error found when the parameters are reloaded. By default
+// the error is reported on the standard error. It will return an error if
+// the function is nil.
+func WatchErrFunc(f func(error)) WatchOptFunc {
+	return func(w *watcher) error {
+		if f == nil {
+			return errors.New("the watch error function must not be nil")
+		}
+
+		w.errFunc = f
+
+		return nil
+	}
+}
+
+// fileState records the details of a file used to detect changes
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the state of the named file
+func statFile(name string) fileState {
+	fName, err := fileparse.FixFileName(name)
+	if err != nil {
+		return fileState{}
+	}
+
+	info, err := os.Stat(fName)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+}
+
+// watchedFiles returns the state of each of the config files, the files in
+// the config directories and the env files
+func (ps *PSet) watchedFiles() map[string]fileState {
+	files := map[string]fileState{}
+
+	addFiles := func(cfs []ConfigFileDetails) {
+		for _, cf := range cfs {
+			files[cf.Name] = statFile(cf.Name)
+		}
+	}
+
+	for _, g := range ps.groups {
+		addFiles(g.configFiles)
+	}
+
+	addFiles(ps.configFiles)
+	addFiles(ps.finalCfgFiles)
+	addFiles(ps.envFiles)
+
+	for _, cdd := range ps.configDirs {
+		files[cdd.Name] = statFile(cdd.Name)
+
+		names, err := cdd.Files()
+		if err != nil {
+			continue
+		}
+
+		for _, name := range names {
+			files[name] = statFile(name)
+		}
+	}
+
+	return files
+}
+
+// Watch starts a goroutine which will reload the parameters (see the
+// PSet.Reload method) whenever the program receives a SIGHUP signal or any
+// of the config files, the files in the config directories or the env files
+// are changed, created or removed. The files are checked at regular
+// intervals, see the WatchInterval function. It returns a channel on which
+// a ChangeEvent is sent for each parameter whose value has changed. Any
+// errors found when reloading the parameters are passed to the error
+// function, see the WatchErrFunc function; the parameters will keep their
+// previous values.
+//
+// The watching stops and the channel is closed when the context is done.
+// The caller should keep reading from the channel until then.
+//
+// Note that the values of the parameters will be changed while other
+// goroutines may be reading them. The parameters should use the atomic
+// setters from the psetter package (such as psetter.AtomicInt) so that the
+// values can be read safely at any time.
+//
+// It will return an error if the parameters have not been parsed, if there
+// are no Reloadable parameters, if the PSet is already being watched or if
+// any of the options return an error.
+func (ps *PSet) Watch(ctx context.Context, opts ...WatchOptFunc,
+) (<-chan ChangeEvent, error) {
+	if !ps.parsed {
+		return nil, errors.New("the parameters have not yet been parsed")
+	}
+
+	if !ps.HasReloadableParams() {
+		return nil, errors.New("there are no Reloadable parameters")
+	}
+
+	w := &watcher{
+		interval: DfltWatchInterval,
+		errFunc: func(err error) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", ps.progBaseName, err)
+		},
+	}
+
+	for _, o := range opts {
+		if err := o(w); err != nil {
+			return nil, err
+		}
+	}
+
+	ps.reloadMu.Lock()
+	defer ps.reloadMu.Unlock()
+
+	if ps.watching {
+		return nil, errors.New("the parameters are already being watched")
+	}
+
+	ps.watching = true
+
+	ch := make(chan ChangeEvent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go ps.watch(ctx, w, ch, sigCh, ps.watchedFiles())
+
+	return ch, nil
+}
+
+// watch waits for a signal or a change to the watched files and reloads the
+// parameters, sending any changes on the channel
+func (ps *PSet) watch(ctx context.Context, w *watcher,
+	ch chan<- ChangeEvent, sigCh chan os.Signal, state map[string]fileState,
+) {
+	ticker := time.NewTicker(w.interval)
+
+	defer func() {
+		ticker.Stop()
+		signal.Stop(sigCh)
+		close(ch)
+
+		ps.reloadMu.Lock()
+		ps.watching = false
+		ps.reloadMu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			state = ps.watchedFiles()
+		case <-ticker.C:
+			newState := ps.watchedFiles()
+			if maps.Equal(state, newState) {
+				continue
+			}
+
+			state = newState
+		}
+
+		changes, err := ps.Reload()
+		if err != nil {
+			w.errFunc(err)
+			continue
+		}
+
+		for _, ce := range changes {
+			select {
+			case ch <- ce:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package param
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// cfCommentLineLen is the target length of the comment lines written by
+// WriteConfig
+const cfCommentLineLen = 76
+
+// WriteConfigOpts records the options controlling the behaviour of
+// WriteConfig
+type WriteConfigOpts struct {
+	allParams       bool
+	noComments      bool
+	forThisProgOnly bool
+}
+
+// WriteConfigOptFunc is the type of an option function which can be passed
+// to WriteConfig
+type WriteConfigOptFunc = ptypes.OptFunc[WriteConfigOpts]
+
+// WriteAllParams is a WriteConfigOptFunc which causes WriteConfig to write
+// out every parameter, not just those whose values differ from their initial
+// values.
+func WriteAllParams(o *WriteConfigOpts) error {
+	o.allParams = true
+
+	return nil
+}
+
+// WriteNoComments is a WriteConfigOptFunc which causes WriteConfig to
+// suppress the comments giving the parameter descriptions and where the
+// values were set.
+func WriteNoComments(o *WriteConfigOpts) error {
+	o.noComments = true
+
+	return nil
+}
+
+// WriteForThisProgOnly is a WriteConfigOptFunc which causes WriteConfig to
+// prefix each parameter with the program name so that the configuration
+// will only apply to this program.
+func WriteForThisProgOnly(o *WriteConfigOpts) error {
+	o.forThisProgOnly = true
+
+	return nil
+}
+
+// configWriter holds the state needed while writing a configuration
+type configWriter struct {
+	w    *bufio.Writer
+	opts WriteConfigOpts
+}
+
+// comment writes the text as a sequence of comment lines, wrapping the
+// text to keep the lines reasonably short
+func (cw configWriter) comment(text string) {
+	for para := range strings.SplitSeq(text, "\n") {
+		line := "#"
+
+		for word := range strings.FieldsSeq(para) {
+			if len(line) > 1 && len(line)+1+len(word) > cfCommentLineLen {
+				fmt.Fprintln(cw.w, line)
+				line = "#"
+			}
+
+			line += " " + word
+		}
+
+		fmt.Fprintln(cw.w, line)
+	}
+}
+
+// paramLines returns the lines needed to recreate the parameter's value
+func paramLines(pfx string, p *ByName) []string {
+	name := pfx + p.name
+
+	if p.setter.ValueReq() == None {
+		lines := make([]string, 0, len(p.whereIsParamSet))
+		for range p.whereIsParamSet {
+			lines = append(lines, name)
+		}
+
+		return lines
+	}
+
+	val := p.setter.CurrentValue()
+	if !strings.Contains(val, "\n") {
+		return []string{name + " = " + val}
+	}
+
+	if ls, ok := p.setter.(listSeparator); ok {
+		return []string{
+			name + " = " + strings.ReplaceAll(val, "\n", ls.GetSeparator()),
+		}
+	}
+
+	var lines []string
+	for v := range strings.SplitSeq(val, "\n") {
+		lines = append(lines, name+" = "+v)
+	}
+
+	return lines
+}
+
+// shouldWrite returns true if the parameter should be written
+func (cw configWriter) shouldWrite(p *ByName) bool {
+	if p.AttrIsSet(CommandLineOnly) {
+		return false
+	}
+
+	if cw.opts.allParams {
+		return true
+	}
+
+	if p.setter.ValueReq() == None {
+		return p.HasBeenSet()
+	}
+
+	return p.setter.CurrentValue() != p.initialValue
+}
+
+// writeParams writes the parameters of the PSet, group by group. The
+// parameter names are prefixed with the given prefix.
+func (cw configWriter) writeParams(ps *PSet, pfx string) {
+	for _, g := range ps.GetGroups() {
+		groupIntroDone := false
+
+		for _, p := range g.Params() {
+			if !cw.shouldWrite(p) {
+				continue
+			}
+
+			lines := paramLines(pfx, p)
+
+			if !cw.opts.noComments {
+				if !groupIntroDone {
+					fmt.Fprintln(cw.w, "#")
+					cw.comment("Parameter group: " + g.name)
+					fmt.Fprintln(cw.w, "#")
+
+					groupIntroDone = true
+				}
+
+				fmt.Fprintln(cw.w)
+				cw.comment(p.name + ": " + p.description)
+
+				for _, ws := range p.whereIsParamSet {
+					cw.comment("set at: " + ws)
+				}
+			}
+
+			if len(lines) == 0 {
+				fmt.Fprintln(cw.w, "# "+pfx+p.name)
+			}
+
+			for _, l := range lines {
+				fmt.Fprintln(cw.w, l)
+			}
+		}
+	}
+}
+
+// WriteConfig writes the values of the named parameters to the writer in
+// the format of a configuration file (see [PSet.SetConfigFile]). The output
+// can be used as a configuration file or given as the value of a parameter
+// which reads parameters from a file.
+//
+// By default only those parameters whose current values differ from their
+// initial values are written but this can be changed through the
+// WriteAllParams option. Parameters with the CommandLineOnly attribute are
+// never written as they cannot be given in a configuration file. Each
+// parameter is preceded by comments giving its description and the places
+// where it was set; these can be suppressed through the WriteNoComments
+// option.
+//
+// A parameter which takes no value is written once for each time it was
+// set. A parameter which takes a list of values is written with its values
+// joined by the list separator. Note that values are stripped of any
+// surrounding white space when they are read from a configuration file.
+//
+// If a sub-command has been chosen its parameters are also written,
+// preceded by the program and sub-command names.
+//
+// This should only be called after the parameters have been parsed.
+func (ps *PSet) WriteConfig(w io.Writer, opts ...WriteConfigOptFunc) error {
+	cw := configWriter{w: bufio.NewWriter(w)}
+
+	for _, o := range opts {
+		if err := o(&cw.opts); err != nil {
+			return err
+		}
+	}
+
+	if !cw.opts.noComments {
+		cw.comment("Parameters for: " + ps.progName)
+	}
+
+	pfx := ""
+	if cw.opts.forThisProgOnly {
+		pfx = ps.progBaseName + "/"
+	}
+
+	cw.writeParams(ps, pfx)
+
+	if sc := ps.subCmdChosen; sc != nil {
+		cw.writeParams(sc.ps, ps.progBaseName+"/"+sc.name+subCmdSep)
+	}
+
+	return cw.w.Flush()
+}
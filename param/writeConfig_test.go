@@ -0,0 +1,174 @@
+package param_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// wcVals holds the values set by the parameters used in the WriteConfig
+// tests
+type wcVals struct {
+	i    int64
+	s    string
+	list []string
+	app  []string
+	cnt  int
+	cmd  bool
+}
+
+// addWriteConfigParams adds the parameters used in the WriteConfig tests
+func addWriteConfigParams(ps *param.PSet, v *wcVals) {
+	ps.Add("int", psetter.Int[int64]{Value: &v.i}, "an int")
+	ps.Add("str", psetter.String[string]{Value: &v.s}, "a string")
+	ps.Add("list", psetter.StrList[string]{Value: &v.list}, "a list")
+	ps.Add("app", psetter.StrListAppender[string]{Value: &v.app}, "appender")
+	ps.Add("cnt", psetter.Counter[int]{Value: &v.cnt}, "a counter")
+	ps.Add("cmd", psetter.Bool{Value: &v.cmd}, "command line only",
+		param.Attrs(param.CommandLineOnly))
+}
+
+func TestWriteConfig(t *testing.T) {
+	const cmdLineLoc = "# set at: [command line]: Supplied Parameter:"
+
+	params := []string{
+		"-int", "42",
+		"-list", "a,b",
+		"-app", "x", "-app", "y",
+		"-cnt", "-cnt",
+		"-cmd",
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		opts     []param.WriteConfigOptFunc
+		expLines []string
+	}{
+		{
+			ID: testhelper.MkID("default"),
+			expLines: []string{
+				"# Parameters for: PROGRAM NAME UNKNOWN",
+				"#",
+				"# Parameter group: cmd",
+				"#",
+				"",
+				"# app: appender",
+				cmdLineLoc + `6: "-app" "x"`,
+				cmdLineLoc + `8: "-app" "y"`,
+				"app = x",
+				"app = y",
+				"",
+				"# cnt: a counter",
+				cmdLineLoc + `9: "-cnt"`,
+				cmdLineLoc + `10: "-cnt"`,
+				"cnt",
+				"cnt",
+				"",
+				"# int: an int",
+				cmdLineLoc + `2: "-int" "42"`,
+				"int = 42",
+				"",
+				"# list: a list",
+				cmdLineLoc + `4: "-list" "a,b"`,
+				"list = a,b",
+			},
+		},
+		{
+			ID:   testhelper.MkID("no comments"),
+			opts: []param.WriteConfigOptFunc{param.WriteNoComments},
+			expLines: []string{
+				"app = x",
+				"app = y",
+				"cnt",
+				"cnt",
+				"int = 42",
+				"list = a,b",
+			},
+		},
+		{
+			ID: testhelper.MkID("all, no comments, this prog only"),
+			opts: []param.WriteConfigOptFunc{
+				param.WriteAllParams,
+				param.WriteNoComments,
+				param.WriteForThisProgOnly,
+			},
+			expLines: []string{
+				"PROGRAM NAME UNKNOWN/app = x",
+				"PROGRAM NAME UNKNOWN/app = y",
+				"PROGRAM NAME UNKNOWN/cnt",
+				"PROGRAM NAME UNKNOWN/cnt",
+				"PROGRAM NAME UNKNOWN/int = 42",
+				"PROGRAM NAME UNKNOWN/list = a,b",
+				"PROGRAM NAME UNKNOWN/str = ",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		var v wcVals
+
+		ps := paramset.NewNoHelpNoExitNoErrRpt()
+		addWriteConfigParams(ps, &v)
+		ps.Parse(params)
+
+		var buf strings.Builder
+
+		if err := ps.WriteConfig(&buf, tc.opts...); err != nil {
+			t.Log(tc.IDStr())
+			t.Error("\t: unexpected error writing the config:", err)
+		}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "config lines",
+			strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"),
+			tc.expLines)
+	}
+}
+
+func TestWriteConfigRoundTrip(t *testing.T) {
+	var v wcVals
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	addWriteConfigParams(ps, &v)
+	ps.Parse([]string{
+		"-int", "42",
+		"-str", "hello world",
+		"-list", "a,b",
+		"-app", "x", "-app", "y",
+		"-cnt", "-cnt", "-cnt",
+	})
+
+	var buf strings.Builder
+
+	if err := ps.WriteConfig(&buf); err != nil {
+		t.Fatal("unexpected error writing the config:", err)
+	}
+
+	fName := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(fName, []byte(buf.String()), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error writing the config file:", err)
+	}
+
+	var rtv wcVals
+
+	rtps := paramset.NewNoHelpNoExitNoErrRpt()
+	addWriteConfigParams(rtps, &rtv)
+	rtps.SetConfigFileStrict(fName, filecheck.MustExist)
+	rtps.Parse([]string{})
+
+	errMapCheck(t, "round trip", rtps.Errors(), nil)
+
+	testhelper.DiffInt(t, "round trip", "int", rtv.i, v.i)
+	testhelper.DiffString(t, "round trip", "str", rtv.s, v.s)
+	testhelper.DiffStringSlice(t, "round trip", "list", rtv.list, v.list)
+	testhelper.DiffStringSlice(t, "round trip", "app", rtv.app, v.app)
+	testhelper.DiffInt(t, "round trip", "cnt", rtv.cnt, v.cnt)
+}
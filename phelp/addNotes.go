@@ -51,7 +51,19 @@ func addNotes(ps *param.PSet) {
 			"\n\n"+
 			"For instance, for the prefix 'XX_' an environment"+
 			" variable called 'XX_a_b' will match a"+
-			" parameter called 'a-b'",
+			" parameter called 'a-b'"+
+			"\n\n"+
+			"Some parameters may also be set through environment"+
+			" variables with particular names, no prefix is needed"+
+			" for these. They will be listed in the description of"+
+			" the parameter and on the Alternative Sources help page."+
+			"\n\n"+
+			"Environment variables may also be read from env files."+
+			" Each line of such a file has the form NAME=value,"+
+			" optionally preceded by 'export '. These are treated"+
+			" exactly as if they had been set in the environment"+
+			" but any variable actually set in the environment will"+
+			" take precedence.",
 		param.NoteAttrs(param.DontShowNoteInStdUsage))
 
 	ps.AddNote("Alternative Sources - Configuration Files",
@@ -7,15 +7,51 @@ import (
 	"github.com/nickwells/filecheck.mod/filecheck"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/psetter"
-	"github.com/nickwells/param.mod/v7/ptypes"
 )
 
 const (
-	paramNameCompletionsQuiet   = "completions-quiet"
-	paramNameCompletionsZshDir  = "completions-zsh-dir"
-	paramNameCompletionsZshMake = "completions-zsh-make"
+	paramNameCompletionsQuiet    = "completions-quiet"
+	paramNameCompletionsQuery    = "completions-query"
+	paramNameCompletionsDynamic  = "completions-dynamic"
+	paramNameCompletionsZshDir   = "completions-zsh-dir"
+	paramNameCompletionsZshMake  = "completions-zsh-make"
+	paramNameCompletionsBashDir  = "completions-bash-dir"
+	paramNameCompletionsBashMake = "completions-bash-make"
+	paramNameCompletionsFishDir  = "completions-fish-dir"
+	paramNameCompletionsFishMake = "completions-fish-make"
 )
 
+// compDirCheck returns a final check function which will report an error if
+// the completions action requires a directory but the directory parameter
+// has not been set
+func compDirCheck(action *string, makeParam, dirParam *param.ByName,
+) param.FinalCheckFunc {
+	return func() error {
+		if *action == compActionShow {
+			return nil
+		}
+
+		if *action == compActionNone {
+			return nil
+		}
+
+		if !dirParam.HasBeenSet() {
+			// These parameters are processed before errors are reported so
+			// we should abort the creation of the completion file
+			*action = compActionNone
+
+			return fmt.Errorf(
+				"the %q parameter has been set (at: %s)"+
+					" but the %q parameter has not",
+				makeParam.Name(),
+				strings.Join(makeParam.WhereSet(), " and at "),
+				dirParam.Name())
+		}
+
+		return nil
+	}
+}
+
 // addParamCompletionParams will add the standard parameters for specifying
 // and creating shell completion functions into the parameter set
 func (h *StdHelp) addParamCompletionParams(ps *param.PSet) {
@@ -29,6 +65,54 @@ func (h *StdHelp) addParamCompletionParams(ps *param.PSet) {
 
 	_ = setConfigFileForGroupStdParamsCmpl(ps)
 
+	ps.Add(paramNameCompletionsQuiet,
+		psetter.Bool{
+			Value: &h.completionsQuiet,
+		},
+		"suppress any messages produced after generating"+
+			" or updating the completions file.",
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.DontShowInStdUsage),
+	)
+
+	ps.Add(paramNameCompletionsDynamic,
+		psetter.Bool{
+			Value: &h.completionsDynamic,
+		},
+		"generate completion functions which call back into the program"+
+			" each time a completion is needed rather than ones which"+
+			" hold a fixed list of the parameters and their values."+
+			" This lets the program offer values which can only be"+
+			" found at the time the command line is being typed."+
+			" Note that the program will be run each time you ask"+
+			" for a completion.",
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+	)
+
+	ps.Add(paramNameCompletionsQuery,
+		psetter.Bool{
+			Value: &h.completionsQuery,
+		},
+		"show the possible completions of the last of the"+
+			" following parameters, one per line, and exit."+
+			" This is intended to be used by the completion functions"+
+			" generated when the '"+paramNameCompletionsDynamic+
+			"' parameter is given and not to be given directly."+
+			" Any errors found while processing the parameters"+
+			" are ignored.",
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.IsTerminalParam|param.DontShowInStdUsage),
+	)
+
+	h.addZshCompletionParams(ps)
+	h.addBashCompletionParams(ps)
+	h.addFishCompletionParams(ps)
+}
+
+// addZshCompletionParams will add the parameters for creating zsh
+// completion functions into the parameter set
+func (h *StdHelp) addZshCompletionParams(ps *param.PSet) {
 	zshDirParam := ps.Add(paramNameCompletionsZshDir,
 		psetter.Pathname{
 			Value:       &h.zshCompDir,
@@ -43,69 +127,103 @@ func (h *StdHelp) addParamCompletionParams(ps *param.PSet) {
 		param.Attrs(param.DontShowInStdUsage),
 	)
 
-	ps.Add(paramNameCompletionsQuiet,
-		psetter.Bool{
-			Value: &h.completionsQuiet,
+	zshMakeCompletionsParam := ps.Add(paramNameCompletionsZshMake,
+		psetter.Enum[string]{
+			AllowedVals: compAllowedVals("zsh",
+				" The zsh completions directory name must be specified."),
+			Value: &h.zshCompAction,
 		},
-		"suppress any messages produced after generating"+
-			" or updating the completions file.",
+		"how to create the zsh completions file."+
+			" This specifies whether or if the file should be created."+
+			" If it is set to any value other than '"+compActionNone+
+			"' then the program will exit after the parameters are processed.",
+		param.SeeAlso(paramNameCompletionsZshDir),
 		param.GroupName(cmplGroupName),
-		param.Attrs(param.DontShowInStdUsage),
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
 	)
 
-	const needZshDir = " The zsh completions directory name must be specified."
+	// Final checks
+
+	ps.AddFinalCheck(
+		compDirCheck(&h.zshCompAction, zshMakeCompletionsParam, zshDirParam))
+}
 
-	zshMakeCompletionsParam := ps.Add(paramNameCompletionsZshMake,
+// addBashCompletionParams will add the parameters for creating bash
+// completion functions into the parameter set
+func (h *StdHelp) addBashCompletionParams(ps *param.PSet) {
+	bashDirParam := ps.Add(paramNameCompletionsBashDir,
+		psetter.Pathname{
+			Value:       &h.bashCompDir,
+			Expectation: filecheck.DirExists(),
+		},
+		"which directory should a bash completions function for this"+
+			" program be written to."+
+			" If you use the bash-completion package this could be"+
+			" the directory it searches for user completions"+
+			" (typically ~/.local/share/bash-completion/completions)"+
+			" and the completions will be loaded automatically."+
+			" Otherwise you will need to source the generated file"+
+			" from your .bashrc file.",
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.DontShowInStdUsage),
+	)
+
+	bashMakeCompletionsParam := ps.Add(paramNameCompletionsBashMake,
 		psetter.Enum[string]{
-			AllowedVals: ptypes.AllowedVals[string]{
-				zshCompActionRepl: "any existing zsh completions" +
-					" file for the program will be overwritten or a" +
-					" new file will be generated." +
-					needZshDir,
-				zshCompActionNew: "only generate the zsh completions file" +
-					" if it doesn't already exist. Any pre-existing" +
-					" file is protected and an error will be reported." +
-					needZshDir,
-				zshCompActionShow: "don't generate the zsh completions file." +
-					" The file that would have been generated is" +
-					" instead printed to standard output.",
-				zshCompActionNone: "do nothing.",
-			},
-			Value: &h.zshCompAction,
+			AllowedVals: compAllowedVals("bash",
+				" The bash completions directory name must be specified."),
+			Value: &h.bashCompAction,
 		},
-		"how to create the zsh completions file."+
+		"how to create the bash completions file."+
 			" This specifies whether or if the file should be created."+
-			" If it is set to any value other than '"+zshCompActionNone+
+			" If it is set to any value other than '"+compActionNone+
 			"' then the program will exit after the parameters are processed.",
-		param.SeeAlso(paramNameCompletionsZshDir),
+		param.SeeAlso(paramNameCompletionsBashDir),
 		param.GroupName(cmplGroupName),
 		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
 	)
 
 	// Final checks
 
-	ps.AddFinalCheck(func() error {
-		if h.zshCompAction == zshCompActionShow {
-			return nil
-		}
+	ps.AddFinalCheck(
+		compDirCheck(&h.bashCompAction, bashMakeCompletionsParam, bashDirParam))
+}
 
-		if h.zshCompAction == zshCompActionNone {
-			return nil
-		}
+// addFishCompletionParams will add the parameters for creating fish
+// completions into the parameter set
+func (h *StdHelp) addFishCompletionParams(ps *param.PSet) {
+	fishDirParam := ps.Add(paramNameCompletionsFishDir,
+		psetter.Pathname{
+			Value:       &h.fishCompDir,
+			Expectation: filecheck.DirExists(),
+		},
+		"which directory should the fish completions for this"+
+			" program be written to."+
+			" The directory should be in the list of directories"+
+			" given in the fish_complete_path variable"+
+			" (typically ~/.config/fish/completions)."+
+			" See the fish manual for more details.",
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.DontShowInStdUsage),
+	)
 
-		if !zshDirParam.HasBeenSet() {
-			// These parameters are processed before errors are reported so
-			// we should abort the creation of the completion file
-			h.zshCompAction = zshCompActionNone
+	fishMakeCompletionsParam := ps.Add(paramNameCompletionsFishMake,
+		psetter.Enum[string]{
+			AllowedVals: compAllowedVals("fish",
+				" The fish completions directory name must be specified."),
+			Value: &h.fishCompAction,
+		},
+		"how to create the fish completions file."+
+			" This specifies whether or if the file should be created."+
+			" If it is set to any value other than '"+compActionNone+
+			"' then the program will exit after the parameters are processed.",
+		param.SeeAlso(paramNameCompletionsFishDir),
+		param.GroupName(cmplGroupName),
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+	)
 
-			return fmt.Errorf(
-				"the %q parameter has been set (at: %s)"+
-					" but the %q parameter has not",
-				zshMakeCompletionsParam.Name(),
-				strings.Join(zshMakeCompletionsParam.WhereSet(), " and at "),
-				zshDirParam.Name())
-		}
+	// Final checks
 
-		return nil
-	})
+	ps.AddFinalCheck(
+		compDirCheck(&h.fishCompAction, fishMakeCompletionsParam, fishDirParam))
 }
@@ -37,6 +37,8 @@ type helpFmt string
 const (
 	helpFmtTypeStd      = helpFmt("standard")
 	helpFmtTypeMarkdown = helpFmt("markdown")
+	helpFmtTypeJSON     = helpFmt("json")
+	helpFmtTypeMan      = helpFmt("man")
 )
 
 const (
@@ -224,12 +226,30 @@ func (h *StdHelp) addUsageParams(ps *param.PSet) {
 				helpFmtTypeMarkdown: "markdown format. This will have" +
 					" markdown annotations applied. This can be useful" +
 					" to produce online documentation",
+				helpFmtTypeJSON: "a JSON description of all the" +
+					" parameters, groups, notes etc. The help sections" +
+					" chosen are ignored. This can be useful" +
+					" for other programs which need to know" +
+					" about the program's parameters",
+				helpFmtTypeMan: "a complete manual page in roff" +
+					" format. The help sections chosen are ignored." +
+					" This can be used to generate the manual page" +
+					" for the program",
 			},
 		},
 		"specify how the help message should be produced. Only some parts"+
 			" of the help message support this feature. They will mostly"+
 			" produce Standard format regardless of this setting.",
 		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+		param.PostAction(
+			func(_ location.L, _ *param.BaseParam, _ []string) error {
+				if h.helpFormat == helpFmtTypeJSON ||
+					h.helpFormat == helpFmtTypeMan {
+					h.helpRequested = true
+				}
+
+				return nil
+			}),
 		param.GroupName(helpGroupName),
 	)
 
@@ -11,10 +11,13 @@ const (
 	paramNameWhereSetFormat   = "params-where-set-fmt"
 	paramNameShowWhereSet     = "params-show-where-set"
 	paramNameShowUnused       = "params-show-unused"
+	paramNameDumpConfig       = "params-dump-config"
+	paramNameDumpConfigAll    = "params-dump-config-all"
 	paramNameDontShowErrors   = "params-dont-show-errors"
 	paramNameDontExitOnErrors = "params-dont-exit-on-errors"
 	paramNameExitAfterParsing = "params-exit-after-parsing"
 	paramNameFile             = "params-file"
+	paramNameProfile          = "params-profile"
 )
 
 const (
@@ -83,6 +86,40 @@ func (h *StdHelp) addParamHandlingParams(ps *param.PSet) {
 		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
 		param.GroupName(paramsGroupName))
 
+	ps.Add(paramNameDumpConfig,
+		psetter.Bool{Value: &h.paramsDumpConfig},
+		"after all the parameters are set their values will be"+
+			" printed in the format of a configuration file. Only"+
+			" those parameters whose values differ from their initial"+
+			" values are shown and each is preceded by comments"+
+			" giving its description and where it was set."+
+			" Parameters which can only be set on the command line"+
+			" are not shown."+
+			"\n\n"+
+			"This lets you save a set of parameters you have"+
+			" arrived at on the command line for later use, either as"+
+			" a configuration file or through the "+paramNameFile+
+			" parameter."+
+			exitAfterParamProcessing,
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+		param.GroupName(paramsGroupName),
+		param.SeeAlso(paramNameDumpConfigAll, paramNameFile),
+	)
+
+	ps.Add(paramNameDumpConfigAll,
+		psetter.Bool{Value: &h.paramsDumpConfigAll},
+		"after all the parameters are set their values will be"+
+			" printed in the format of a configuration file as for"+
+			" the "+paramNameDumpConfig+" parameter but all of the"+
+			" parameters will be shown, not just those whose values"+
+			" have changed."+
+			exitAfterParamProcessing,
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+		param.PostAction(paction.SetVal(&h.paramsDumpConfig, true)),
+		param.GroupName(paramsGroupName),
+		param.SeeAlso(paramNameDumpConfig),
+	)
+
 	ps.Add(paramNameDontShowErrors,
 		psetter.Bool{
 			Value:  &h.reportErrors,
@@ -129,4 +166,19 @@ func (h *StdHelp) addParamHandlingParams(ps *param.PSet) {
 		param.PostAction(param.ConfigFileActionFunc),
 		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
 		param.GroupName(paramsGroupName))
+
+	ps.Add(paramNameProfile,
+		psetter.StrList[string]{Value: &h.paramsProfiles},
+		"select the profiles to be used from the configuration files."+
+			" A configuration file may be divided into sections, each"+
+			" starting with a line giving the names of the profiles"+
+			" it is for in square brackets, such as '[dev, staging]'."+
+			" The parameters in a section are only used if one of"+
+			" its profiles is selected. Parameters before the first"+
+			" section and those in the '"+param.DfltProfile+"'"+
+			" section are always used.",
+		param.SelectsProfiles,
+		param.SeeAlso(paramNameFile),
+		param.Attrs(param.CommandLineOnly|param.DontShowInStdUsage),
+		param.GroupName(paramsGroupName))
 }
@@ -0,0 +1,344 @@
+package phelp
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"github.com/nickwells/twrap.mod/twrap"
+)
+
+// bashCompHasAction returns true if the StdHelp bash Completion Action is
+// not None, false otherwise.
+func bashCompHasAction(h *StdHelp) bool {
+	return h.bashCompAction != compActionNone
+}
+
+// bashWordList returns the words, excluding any unsafe words, as a single
+// string suitable to be given to the bash compgen command
+func bashWordList(words []string) string {
+	safeWords := make([]string, 0, len(words))
+
+	for _, w := range words {
+		if compSafeWord(w) {
+			safeWords = append(safeWords, w)
+		}
+	}
+
+	return "'" + strings.Join(safeWords, " ") + "'"
+}
+
+// bashFuncName returns the name of the bash completion function for the
+// program. Any characters in the program name which are not valid in a
+// function name are replaced with underscores.
+func bashFuncName(ps *param.PSet) string {
+	return "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9':
+			return r
+		}
+
+		return '_'
+	}, ps.ProgBaseName())
+}
+
+// bashValueCompletion returns the bash command to generate the completions
+// for the value of a parameter with the given setter. It returns the empty
+// string if no completions can be offered.
+func bashValueCompletion(s param.Setter) string {
+	spec := compSpec(s)
+
+	switch spec.Kind {
+	case ptypes.CompleteWords:
+		return `COMPREPLY=( $(compgen -W ` + bashWordList(spec.Words) +
+			` -- "$cur") )`
+	case ptypes.CompleteFiles:
+		if spec.Glob != "" {
+			return `COMPREPLY=( $(compgen -d -- "$cur")` +
+				` $(compgen -f -X '!` + spec.Glob + `' -- "$cur") )`
+		}
+
+		return `COMPREPLY=( $(compgen -f -- "$cur") )`
+	case ptypes.CompleteDirs:
+		return `COMPREPLY=( $(compgen -d -- "$cur") )`
+	case ptypes.CompleteHostnames:
+		return `COMPREPLY=( $(compgen -A hostname -- "$cur") )`
+	}
+
+	return ""
+}
+
+// bashParamNames returns all the names by which the parameter can be given
+// on the command line, without any prefix. Negated names are not included.
+func bashParamNames(p *param.ByName) []string {
+	names := p.AltNames()
+	if sn := p.ShortName(); sn != 0 {
+		names = append(names, string(sn))
+	}
+
+	return names
+}
+
+// bashCasePattern returns a case pattern matching each of the names with
+// each of the parameter prefixes
+func bashCasePattern(ps *param.PSet, names []string) string {
+	var pats []string
+
+	for _, name := range names {
+		for _, pfx := range ps.ParamPrefixes() {
+			pats = append(pats, pfx+name)
+		}
+	}
+
+	return strings.Join(pats, "|")
+}
+
+// bashWriteParamValueCases writes the case entries which complete the
+// values of the named parameters. A parameter which takes an optional value
+// only has its value completed if the value follows an '='.
+func bashWriteParamValueCases(ps *param.PSet, w io.Writer) {
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			valueReq := p.Setter().ValueReq()
+			if valueReq == param.None {
+				continue
+			}
+
+			fmt.Fprintf(w, "\t%s)\n", bashCasePattern(ps, bashParamNames(p)))
+
+			cmd := bashValueCompletion(p.Setter())
+
+			if valueReq == param.Optional {
+				if cmd != "" {
+					fmt.Fprintln(w, "\t\tif [ \"$afterEq\" -eq 1 ]; then")
+					fmt.Fprintf(w, "\t\t\t%s\n", cmd)
+					fmt.Fprintln(w, "\t\t\treturn 0")
+					fmt.Fprintln(w, "\t\tfi")
+				}
+
+				fmt.Fprintln(w, "\t\t;;")
+
+				continue
+			}
+
+			if cmd != "" {
+				fmt.Fprintf(w, "\t\t%s\n", cmd)
+			}
+
+			fmt.Fprintln(w, "\t\treturn 0")
+			fmt.Fprintln(w, "\t\t;;")
+		}
+	}
+}
+
+// bashWritePositionalCases writes the case statement which completes the
+// values of the positional parameters
+func bashWritePositionalCases(ps *param.PSet, w io.Writer) {
+	if ps.CountByPosParams() == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\tcase \"$COMP_CWORD\" in")
+
+	for i := range ps.CountByPosParams() {
+		bp, err := ps.GetParamByPos(i)
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "\t%d)\n", i+1)
+
+		if cmd := bashValueCompletion(bp.Setter()); cmd != "" {
+			fmt.Fprintf(w, "\t\t%s\n", cmd)
+		}
+
+		fmt.Fprintln(w, "\t\treturn 0")
+		fmt.Fprintln(w, "\t\t;;")
+	}
+
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w)
+}
+
+// bashWriteTrailingParamCheck writes the code which offers filenames as
+// completions once the terminal parameter has been given
+func bashWriteTrailingParamCheck(ps *param.PSet, w io.Writer) {
+	if !ps.TrailingParamsExpected() {
+		return
+	}
+
+	fmt.Fprintln(w, "\tlocal i")
+	fmt.Fprintln(w, "\tfor (( i=1; i < COMP_CWORD; i++ )); do")
+	fmt.Fprintf(w, "\t\tif [ \"${COMP_WORDS[i]}\" = %q ]; then\n",
+		ps.TerminalParam())
+	fmt.Fprintln(w, "\t\t\tCOMPREPLY=( $(compgen -f -- \"$cur\") )")
+	fmt.Fprintln(w, "\t\t\treturn 0")
+	fmt.Fprintln(w, "\t\tfi")
+	fmt.Fprintln(w, "\tdone")
+	fmt.Fprintln(w)
+}
+
+// bashAllParamNames returns all the names (including any negated names) by
+// which the parameters can be given on the command line, each with a
+// leading '-'
+func bashAllParamNames(ps *param.PSet) []string {
+	var names []string
+
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			for _, n := range bashParamNames(p) {
+				names = append(names, "-"+n)
+			}
+
+			for _, n := range p.NegatedNames() {
+				names = append(names, "-"+n)
+			}
+		}
+	}
+
+	return names
+}
+
+// bashWriteCompFunc writes a bash completion function for the current
+// executable. Note that bash splits the word being completed at any '=' so
+// the parameter whose value is being completed may be two words back.
+func bashWriteCompFunc(ps *param.PSet, w io.Writer) {
+	funcName := bashFuncName(ps)
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", ps.ProgBaseName())
+	fmt.Fprintf(w, "%s() {\n", funcName)
+	fmt.Fprintln(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"")
+	fmt.Fprintln(w, "\tlocal prev=\"\"")
+	fmt.Fprintln(w, "\tlocal opt=\"\"")
+	fmt.Fprintln(w, "\tlocal afterEq=0")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tif [ \"$COMP_CWORD\" -gt 0 ]; then")
+	fmt.Fprintln(w, "\t\tprev=\"${COMP_WORDS[COMP_CWORD-1]}\"")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tif [ \"$cur\" = \"=\" ]; then")
+	fmt.Fprintln(w, "\t\topt=\"$prev\"")
+	fmt.Fprintln(w, "\t\tcur=\"\"")
+	fmt.Fprintln(w, "\t\tafterEq=1")
+	fmt.Fprintln(w,
+		"\telif [ \"$prev\" = \"=\" ] && [ \"$COMP_CWORD\" -gt 1 ]; then")
+	fmt.Fprintln(w, "\t\topt=\"${COMP_WORDS[COMP_CWORD-2]}\"")
+	fmt.Fprintln(w, "\t\tafterEq=1")
+	fmt.Fprintln(w, "\telse")
+	fmt.Fprintln(w, "\t\topt=\"$prev\"")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w)
+
+	bashWriteTrailingParamCheck(ps, w)
+	bashWritePositionalCases(ps, w)
+
+	fmt.Fprintln(w, "\tcase \"$opt\" in")
+	bashWriteParamValueCases(ps, w)
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "\tCOMPREPLY=( $(compgen -W %s -- \"$cur\") )\n",
+		bashWordList(bashAllParamNames(ps)))
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -F %s %s\n", funcName, ps.ProgBaseName())
+}
+
+// bashCompletionHandler performs the appropriate action according to the
+// setting of the StdHelp bashCompAction member. It returns a suggested
+// exit status.
+func bashCompletionHandler(h *StdHelp, twc *twrap.TWConf, ps *param.PSet) int {
+	cfw := h.chooseCompFuncWriter(bashWriteCompFunc, bashWriteDynamicCompFunc)
+
+	switch h.bashCompAction {
+	case compActionNone:
+		return 0
+	case compActionShow:
+		cfw(ps, twc.W)
+		return 0
+	case compActionNew:
+		filename := bashCompFileName(h, ps)
+
+		err := makeNewCompFile(filename, ps, cfw)
+		if err == nil {
+			bashCompFileNotify(h, twc, filename)
+		}
+
+		return compHandleErr(err, ps, "bash")
+	case compActionRepl:
+		filename := bashCompFileName(h, ps)
+
+		err := replaceCompFile(filename, ps, cfw)
+		if err == nil {
+			bashCompFileNotify(h, twc, filename)
+		}
+
+		return compHandleErr(err, ps, "bash")
+	}
+
+	return compHandleErr(compUnknownActionErr("bash", h.bashCompAction), ps,
+		"bash")
+}
+
+// bashCompFileName returns the name of the completions file. This is the
+// name that the bash-completion package expects.
+func bashCompFileName(h *StdHelp, ps *param.PSet) string {
+	return filepath.Join(h.bashCompDir, ps.ProgBaseName())
+}
+
+// bashCompFileNotify writes a notification message informing the user that
+// the completion file has been successfully created.
+func bashCompFileNotify(h *StdHelp, twc *twrap.TWConf, filename string) {
+	if h.completionsQuiet {
+		return
+	}
+
+	twc.Wrap(
+		"the bash completion function has been written to "+filename+"."+
+			" If this is not a directory searched by the bash-completion"+
+			" package you will need to source this file from your"+
+			" .bashrc file and restart your bash shell"+
+			" for this to take effect.",
+		0)
+}
+
+// bashWriteDynamicCompFunc writes a bash completion function for the
+// current executable which calls the program to find the completions. Bash
+// splits words at any '=' so these are rejoined before the program is
+// called and the text up to the '=' is removed from the candidates.
+func bashWriteDynamicCompFunc(ps *param.PSet, w io.Writer) {
+	funcName := bashFuncName(ps)
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", ps.ProgBaseName())
+	fmt.Fprintf(w, "%s() {\n", funcName)
+	fmt.Fprintln(w, "\tlocal words=() i wd cands")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tfor (( i=1; i <= COMP_CWORD; i++ )); do")
+	fmt.Fprintln(w, "\t\twd=\"${COMP_WORDS[i]}\"")
+	fmt.Fprintln(w, "\t\tif [ ${#words[@]} -gt 0 ] &&")
+	fmt.Fprintln(w, "\t\t\t{ [ \"$wd\" = \"=\" ] ||"+
+		" [ \"${words[-1]: -1}\" = \"=\" ]; }; then")
+	fmt.Fprintln(w, "\t\t\twords[-1]+=\"$wd\"")
+	fmt.Fprintln(w, "\t\telse")
+	fmt.Fprintln(w, "\t\t\twords+=(\"$wd\")")
+	fmt.Fprintln(w, "\t\tfi")
+	fmt.Fprintln(w, "\tdone")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w,
+		"\tcands=$(\"${COMP_WORDS[0]}\" %s \"${words[@]}\" 2>/dev/null)\n",
+		compQueryCmd(ps))
+	fmt.Fprintln(w, "\t[ -n \"$cands\" ] || return 0")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tmapfile -t COMPREPLY <<< \"$cands\"")
+	fmt.Fprintln(w, "\tif [[ \"${words[-1]}\" == *=* ]]; then")
+	fmt.Fprintln(w, "\t\tCOMPREPLY=( \"${COMPREPLY[@]#*=}\" )")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -F %s %s\n", funcName, ps.ProgBaseName())
+}
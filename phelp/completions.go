@@ -0,0 +1,242 @@
+package phelp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+const (
+	compActionNone = "none"
+	compActionRepl = "replace"
+	compActionNew  = "new"
+	compActionShow = "show"
+)
+
+const (
+	completionFilePerms = 0o555 // r-x r-x r-x
+)
+
+// compFuncWriter is the type of a function which writes a shell completion
+// function for the program to the writer
+type compFuncWriter func(ps *param.PSet, w io.Writer)
+
+// compAllowedVals returns the allowed values for a completions-...-make
+// parameter for the named shell. The dirNote is appended to the
+// descriptions of those actions which write a file.
+func compAllowedVals(shell, dirNote string) ptypes.AllowedVals[string] {
+	return ptypes.AllowedVals[string]{
+		compActionRepl: "any existing " + shell + " completions" +
+			" file for the program will be overwritten or a" +
+			" new file will be generated." +
+			dirNote,
+		compActionNew: "only generate the " + shell + " completions file" +
+			" if it doesn't already exist. Any pre-existing" +
+			" file is protected and an error will be reported." +
+			dirNote,
+		compActionShow: "don't generate the " + shell + " completions file." +
+			" The file that would have been generated is" +
+			" instead printed to standard output.",
+		compActionNone: "do nothing.",
+	}
+}
+
+// compHasAction returns true if any of the StdHelp completion actions is
+// not None, false otherwise.
+func compHasAction(h *StdHelp) bool {
+	return zshCompHasAction(h) ||
+		bashCompHasAction(h) ||
+		fishCompHasAction(h)
+}
+
+// chooseCompFuncWriter returns the dynamic completion function writer if
+// dynamic completions have been requested and the static one otherwise
+func (h *StdHelp) chooseCompFuncWriter(static, dynamic compFuncWriter,
+) compFuncWriter {
+	if h.completionsDynamic {
+		return dynamic
+	}
+
+	return static
+}
+
+// compQueryCmd returns the arguments to be given to the program to query
+// the completion candidates. Placeholder values are given for any
+// positional parameters so that the query parameter is recognised. The
+// words being completed should follow these arguments.
+func compQueryCmd(ps *param.PSet) string {
+	return strings.Repeat(`"" `, ps.CountByPosParams()) +
+		ps.ShortestPrefix() + paramNameCompletionsQuery
+}
+
+// compAllowedValsSpec returns a completion spec offering any allowed values
+// or aliases that the setter has, in sorted order. The allowed values are
+// described by their help text and the aliases by the values they stand
+// for.
+func compAllowedValsSpec(s param.Setter) ptypes.CompletionSpec {
+	spec := ptypes.CompletionSpec{
+		Kind:      ptypes.CompleteWords,
+		WordDescs: map[string]string{},
+	}
+
+	if getter, ok := s.(ptypes.AllowedValuesMapper); ok {
+		for k, desc := range getter.AllowedValuesMap() {
+			spec.Words = append(spec.Words, k)
+			spec.WordDescs[k] = desc
+		}
+	}
+
+	if getter, ok := s.(ptypes.AllowedValuesAliasMapper); ok {
+		for k, vals := range getter.AllowedValuesAliasMap() {
+			spec.Words = append(spec.Words, k)
+			spec.WordDescs[k] = "an alias for: " + strings.Join(vals, ", ")
+		}
+	}
+
+	sort.Strings(spec.Words)
+
+	return spec
+}
+
+// compSafeWord returns true if the word can be safely given in the list of
+// values offered as completions. The shell may expand the words in the list
+// so any word containing characters which might be expanded or which would
+// split the word is not safe.
+func compSafeWord(w string) bool {
+	if w == "" {
+		return false
+	}
+
+	for _, r := range w {
+		switch {
+		case r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9',
+			strings.ContainsRune("-_.,:/@+%=^", r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// compSpec returns the completion spec for the setter. If the setter
+// satisfies the ptypes.Completer interface the spec is taken from that,
+// otherwise any allowed values and aliases are offered. If neither gives
+// any values but the setter satisfies the ptypes.ValueCompleter interface
+// it is called with an empty prefix and the results offered as the Words.
+// This is not a callback, the values are fixed when the completions are
+// generated; the ValueCompleter is only called as each value is completed
+// if the dynamic completions are used (the completions-dynamic parameter).
+func compSpec(s param.Setter) ptypes.CompletionSpec {
+	var spec ptypes.CompletionSpec
+
+	if c, ok := s.(ptypes.Completer); ok {
+		spec = c.CompletionHints()
+	} else {
+		spec = compAllowedValsSpec(s)
+	}
+
+	if vc, ok := s.(ptypes.ValueCompleter); ok &&
+		spec.Kind == ptypes.CompleteWords && len(spec.Words) == 0 {
+		spec.Words = vc.CompleteValue("")
+		sort.Strings(spec.Words)
+	}
+
+	if spec.Kind == ptypes.CompleteWords && len(spec.Words) == 0 {
+		spec.Kind = ptypes.CompleteNothing
+	}
+
+	return spec
+}
+
+// compHandleErr will test the error, if it is non-nil it will add the error
+// to the param.PSet and return a suggested exit status of
+// ExitStatusCompletionGenFailure. Otherwise it returns ExitStatusOK
+func compHandleErr(err error, ps *param.PSet, shell string) int {
+	if err == nil {
+		return ExitStatusOK
+	}
+
+	ps.AddErr(shell+" completions", err)
+
+	return ExitStatusCompletionGenFailure
+}
+
+// compUnknownActionErr returns an error reporting an unknown completion
+// action
+func compUnknownActionErr(shell, action string) error {
+	return fmt.Errorf("unknown %s completion action: %q", shell, action)
+}
+
+// makeNewCompFile will construct the named file (which must not already
+// exist) using the completion function writer. It will return any errors
+// found.
+func makeNewCompFile(filename string, ps *param.PSet, cfw compFuncWriter,
+) error {
+	err := filecheck.IsNew().StatusCheck(filename)
+	if err != nil {
+		return err
+	}
+
+	w, err := os.OpenFile( //nolint:gosec
+		filename,
+		os.O_WRONLY|os.O_CREATE,
+		completionFilePerms)
+	if err != nil {
+		return err
+	}
+
+	defer w.Close()
+
+	cfw(ps, w)
+
+	return nil
+}
+
+// replaceCompFile will construct the named file (which may already exist)
+// using the completion function writer. It will return any errors found.
+func replaceCompFile(filename string, ps *param.PSet, cfw compFuncWriter,
+) error {
+	const userWritePerm = 0o200
+
+	_ = os.Chmod(filename, completionFilePerms|userWritePerm)
+
+	w, err := os.OpenFile( //nolint:gosec
+		filename,
+		os.O_WRONLY|os.O_TRUNC|os.O_CREATE,
+		completionFilePerms)
+	if err != nil {
+		return err
+	}
+
+	_ = os.Chmod(filename, completionFilePerms)
+
+	defer w.Close()
+
+	cfw(ps, w)
+
+	return nil
+}
+
+// showCompletionCandidates writes the completion candidates for the
+// parameters following the completions-query parameter, one per line. Any
+// errors are suppressed and the program will exit after the parameters are
+// processed.
+func (h *StdHelp) showCompletionCandidates(ps *param.PSet) {
+	for _, c := range ps.CompletionCandidates(ps.TrailingParams()) {
+		fmt.Fprintln(h.StdW(), c)
+	}
+
+	h.reportErrors = false
+	h.exitOnErrors = false
+
+	ps.SetExitStatus(ExitStatusOK)
+}
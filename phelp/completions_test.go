@@ -0,0 +1,138 @@
+//go:build linux || darwin
+
+package phelp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/phelp"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+var gfcCompletions = testhelper.GoldenFileCfg{
+	DirNames:               []string{testDataDir, "completions"},
+	Sfx:                    "txt",
+	UpdFlagName:            "upd-completions-files",
+	KeepBadResultsFlagName: "keep-bad-completions",
+}
+
+func init() {
+	gfcCompletions.AddUpdateFlag()
+	gfcCompletions.AddKeepBadResultsFlag()
+}
+
+func TestCompletions(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		params     []string
+		paramAdder []param.PSetOptFunc
+	}{
+		{
+			ID:         testhelper.MkID("zsh"),
+			params:     []string{"-completions-zsh-make", "show"},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:         testhelper.MkID("bash"),
+			params:     []string{"-completions-bash-make", "show"},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:     testhelper.MkID("bash-positional"),
+			params: []string{"1", "2", "-completions-bash-make", "show"},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addByPosParams,
+			},
+		},
+		{
+			ID:     testhelper.MkID("fish"),
+			params: []string{"-completions-fish-make", "show"},
+			paramAdder: []param.PSetOptFunc{
+				param.SetShortNamesAllowed,
+				addShortNameParams,
+			},
+		},
+		{
+			ID: testhelper.MkID("bash-dynamic"),
+			params: []string{
+				"1", "2",
+				"-completions-bash-make", "show", "-completions-dynamic",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addByPosParams,
+			},
+		},
+		{
+			ID: testhelper.MkID("zsh-dynamic"),
+			params: []string{
+				"-completions-zsh-make", "show", "-completions-dynamic",
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID: testhelper.MkID("fish-dynamic"),
+			params: []string{
+				"-completions-fish-make", "show", "-completions-dynamic",
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:         testhelper.MkID("query-names"),
+			params:     []string{"-completions-query", "-param"},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:         testhelper.MkID("query-value"),
+			params:     []string{"-completions-query", "-param5", ""},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:         testhelper.MkID("query-value-after-equals"),
+			params:     []string{"-completions-query", "-param4=t"},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID: testhelper.MkID("query-positional"),
+			params: []string{
+				"", "", "-completions-query", "1", "2", "-help-s",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addByPosParams,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setInitialValues()
+
+			var stdoutBuf, stderrBuf bytes.Buffer
+
+			helper := phelp.NewStdHelp(
+				phelp.SetStdWriter(&stdoutBuf),
+				phelp.SetErrWriter(&stderrBuf),
+			)
+
+			ps := param.NewSet(helper)
+
+			for _, psof := range tc.paramAdder {
+				if err := psof(ps); err != nil {
+					t.Log(tc.IDStr())
+					t.Fatal("\t: Unexpected failure to add parameters:",
+						err)
+				}
+			}
+
+			ps.ParamParse(location.New("test"), tc.params)
+			helper.ProcessArgs(ps)
+
+			gfcCompletions.Check(t, tc.IDStr(), tc.Name, stdoutBuf.Bytes())
+		})
+	}
+}
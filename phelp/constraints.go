@@ -0,0 +1,41 @@
+package phelp
+
+import (
+	"github.com/nickwells/english.mod/english"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/twrap.mod/twrap"
+)
+
+// showParamConstraints shows the constraints on the ByName parameter (if
+// any)
+func showParamConstraints(twc *twrap.TWConf, p *param.ByName) {
+	constraints := p.PSet().ConstraintsOnParam(p.Name())
+	if len(constraints) == 0 {
+		return
+	}
+
+	prompt := english.Plural("Constraint", len(constraints)) + ": "
+	indent := descriptionIndent + len(prompt)
+
+	twc.WrapPrefixed(prompt, constraints[0].String(), descriptionIndent)
+
+	for _, c := range constraints[1:] {
+		twc.Wrap(c.String(), indent)
+	}
+}
+
+// showConstraints prints the constraints on the parameters
+func showConstraints(h StdHelp, ps *param.PSet) bool {
+	constraints := ps.Constraints()
+	if len(constraints) == 0 {
+		return false
+	}
+
+	h.twc.Print("Parameter Constraints\n\n")
+
+	for _, c := range constraints {
+		h.twc.Wrap(c.String(), paramIndent)
+	}
+
+	return true
+}
@@ -0,0 +1,34 @@
+package phelp
+
+import (
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// showDeprecated prints the deprecated parameter names, why they have been
+// deprecated and the names, if any, of the parameters to use instead
+func showDeprecated(h StdHelp, ps *param.PSet) bool {
+	if !ps.HasDeprecatedParams() {
+		return false
+	}
+
+	h.twc.Print("Deprecated Parameters\n\n")
+
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			for _, d := range p.Deprecations() {
+				h.twc.Wrap("-"+d.Name, paramIndent)
+
+				if d.Msg != "" {
+					h.twc.Wrap(d.Msg, descriptionIndent)
+				}
+
+				if d.Replacement != "" {
+					h.twc.Wrap("use -"+d.Replacement+" instead",
+						descriptionIndent)
+				}
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,20 @@
+package phelp
+
+import (
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// showConfig will print the values of the parameters in the format of a
+// configuration file
+func showConfig(h StdHelp, ps *param.PSet) bool {
+	var opts []param.WriteConfigOptFunc
+	if h.paramsDumpConfigAll {
+		opts = append(opts, param.WriteAllParams)
+	}
+
+	if err := ps.WriteConfig(h.twc.W, opts...); err != nil {
+		h.twc.Println("Cannot write the configuration:", err)
+	}
+
+	return true
+}
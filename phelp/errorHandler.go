@@ -12,7 +12,8 @@ import (
 // arguments as added by the StdHelp AddParams method, see the standard help
 // message for details. It is typically called from the Parse(...) method
 // being passed the PSet error writer, the program name and the PSet
-// error map
+// error map. The exit status is chosen according to the errors found, see
+// DfltExitStatus and SetExitStatusFunc.
 func (h StdHelp) ErrorHandler(ps *param.PSet) {
 	errMap := ps.Errors()
 
@@ -26,7 +27,7 @@ func (h StdHelp) ErrorHandler(ps *param.PSet) {
 	}
 
 	if h.exitOnErrors {
-		ps.SetExitStatus(exitStatusErrorsFound)
+		ps.SetExitStatus(h.exitStatusFunc(errMap))
 	}
 }
 
@@ -1,10 +1,107 @@
 package phelp
 
-// the exit statuses are used by StdHelp. Note that they are not exported and
-// so should not be relied upon as they can change without notice from
-// release to release but they could be used to diagnose immediate problems.
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/nickwells/errutil.mod/errutil"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// These are the exit statuses used by StdHelp. The statuses used when
+// errors are found while parsing the parameters follow the conventions of
+// the BSD sysexits.h header. They will not change from release to release.
 const (
-	exitStatusOK = iota
-	exitStatusErrorsFound
-	exitStatusCompletionGenFailure
+	// ExitStatusOK is used when there are no errors
+	ExitStatusOK = 0
+	// ExitStatusErrorsFound is used by the NoHelp helper when any errors are
+	// found
+	ExitStatusErrorsFound = 1
+	// ExitStatusCompletionGenFailure is used when the shell completion
+	// files cannot be generated
+	ExitStatusCompletionGenFailure = 2
+	// ExitStatusUsage (EX_USAGE) is used when the parameters are used
+	// incorrectly, for instance an unknown parameter is given on the
+	// command line, a value is invalid or a constraint or final check fails
+	ExitStatusUsage = 64
+	// ExitStatusNoInput (EX_NOINPUT) is used when a file (such as a config
+	// file which must exist) does not exist or cannot be read
+	ExitStatusNoInput = 66
+	// ExitStatusConfig (EX_CONFIG) is used when there is an error in a
+	// parameter given in a config file, an env file or the environment
+	ExitStatusConfig = 78
 )
+
+// ExitStatusFunc is the type of a function which chooses the exit status
+// from the errors found while parsing the parameters. It is only called if
+// there are errors. See the DfltExitStatus function and the
+// SetExitStatusFunc option.
+type ExitStatusFunc func(errutil.ErrMap) int
+
+// errSource returns the source of the error (see the param.Src... values)
+// and true if it can be found or false otherwise. Only the command line
+// parser is certain to set the source of the location, so an error having
+// a location with no source is taken to have come from a configuration
+// file.
+func errSource(err error) (string, bool) {
+	var d interface{ Details() param.ParamErrDetails }
+	if errors.As(err, &d) {
+		if src := d.Details().Source; src != "" {
+			return src, true
+		}
+
+		return param.SrcConfigFilePfx, true
+	}
+
+	var le location.Err
+	if errors.As(err, &le) {
+		if src := le.Loc.Note(); src != "" {
+			return src, true
+		}
+
+		return param.SrcConfigFilePfx, true
+	}
+
+	return "", false
+}
+
+// ErrExitStatus returns the exit status for the error. This will be
+// ExitStatusNoInput if the error is because a file does not exist or
+// cannot be read, ExitStatusConfig if the error is for a parameter given
+// somewhere other than the command line and ExitStatusUsage otherwise.
+func ErrExitStatus(err error) int {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return ExitStatusNoInput
+	}
+
+	if src, ok := errSource(err); ok && src != param.SrcCommandLine {
+		return ExitStatusConfig
+	}
+
+	return ExitStatusUsage
+}
+
+// DfltExitStatus is the default ExitStatusFunc. It finds the exit status for
+// each of the errors (see ErrExitStatus) and returns the most important.
+// Missing or unreadable files are taken to be the most important, then
+// errors in configuration and lastly errors in usage. It returns
+// ExitStatusOK if there are no errors.
+func DfltExitStatus(errMap errutil.ErrMap) int {
+	priority := map[int]int{
+		ExitStatusUsage:   1,
+		ExitStatusConfig:  2,
+		ExitStatusNoInput: 3,
+	}
+	status := ExitStatusOK
+
+	for _, errs := range errMap {
+		for _, err := range errs {
+			if s := ErrExitStatus(err); priority[s] > priority[status] {
+				status = s
+			}
+		}
+	}
+
+	return status
+}
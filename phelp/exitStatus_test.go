@@ -0,0 +1,171 @@
+package phelp_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/nickwells/errutil.mod/errutil"
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/phelp"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// mkLoc returns a location with the note set to the source
+func mkLoc(src string) *location.L {
+	loc := location.New("test")
+	loc.SetNote(src)
+
+	return loc
+}
+
+// parseErrs parses the args, having first added the config file if it is
+// not empty, and returns the errors found
+func parseErrs(cfgFile string, args ...string) errutil.ErrMap {
+	var i int64
+
+	ps := paramset.NewNoHelpNoExitNoErrRpt()
+	ps.Add("i", psetter.Int[int64]{Value: &i}, "desc")
+
+	if cfgFile != "" {
+		ps.AddConfigFile(cfgFile, filecheck.MustExist)
+	}
+
+	ps.Parse(args)
+
+	return ps.Errors()
+}
+
+func TestDfltExitStatus(t *testing.T) {
+	cmdLineErr := &param.UnknownParamError{
+		ParamErrDetails: param.ParamErrDetails{
+			Name:   "p",
+			Loc:    *mkLoc(param.SrcCommandLine),
+			Source: param.SrcCommandLine,
+		},
+	}
+	cfgFileErr := &param.BadValueError{
+		ParamErrDetails: param.ParamErrDetails{
+			Name:   "p",
+			Loc:    *mkLoc(param.SrcConfigFilePfx),
+			Source: param.SrcConfigFilePfx,
+		},
+		Err: errors.New("bad value"),
+	}
+	envErr := mkLoc(param.SrcEnvironment).Error("bad value")
+	noSrcErr := location.New("file").Error("bad value")
+	noFileErr := fmt.Errorf("config file: %w",
+		&os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist})
+
+	testCases := []struct {
+		testhelper.ID
+		errMap    errutil.ErrMap
+		expStatus int
+	}{
+		{
+			ID:        testhelper.MkID("no errors"),
+			errMap:    errutil.ErrMap{},
+			expStatus: phelp.ExitStatusOK,
+		},
+		{
+			ID: testhelper.MkID("command line error"),
+			errMap: errutil.ErrMap{
+				"p": {cmdLineErr},
+			},
+			expStatus: phelp.ExitStatusUsage,
+		},
+		{
+			ID: testhelper.MkID("final check error"),
+			errMap: errutil.ErrMap{
+				"Final Checks": {errors.New("bad")},
+			},
+			expStatus: phelp.ExitStatusUsage,
+		},
+		{
+			ID: testhelper.MkID("config file error"),
+			errMap: errutil.ErrMap{
+				"p": {cmdLineErr, cfgFileErr},
+			},
+			expStatus: phelp.ExitStatusConfig,
+		},
+		{
+			ID: testhelper.MkID("environment error"),
+			errMap: errutil.ErrMap{
+				"p": {envErr},
+			},
+			expStatus: phelp.ExitStatusConfig,
+		},
+		{
+			ID: testhelper.MkID("missing file"),
+			errMap: errutil.ErrMap{
+				"p":           {cfgFileErr},
+				"config file": {noFileErr},
+			},
+			expStatus: phelp.ExitStatusNoInput,
+		},
+		{
+			ID: testhelper.MkID("error with no source"),
+			errMap: errutil.ErrMap{
+				"p": {cmdLineErr, noSrcErr},
+			},
+			expStatus: phelp.ExitStatusConfig,
+		},
+		{
+			ID:        testhelper.MkID("parsed: unknown parameter"),
+			errMap:    parseErrs("", "-nonesuch"),
+			expStatus: phelp.ExitStatusUsage,
+		},
+		{
+			ID:        testhelper.MkID("parsed: bad value in config file"),
+			errMap:    parseErrs("testdata/configFiles/bad-value.cfg"),
+			expStatus: phelp.ExitStatusConfig,
+		},
+		{
+			ID:        testhelper.MkID("parsed: missing config file"),
+			errMap:    parseErrs("testdata/configFiles/nonesuch.cfg"),
+			expStatus: phelp.ExitStatusNoInput,
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffInt(t, tc.IDStr(), "exit status",
+			phelp.DfltExitStatus(tc.errMap), tc.expStatus)
+	}
+}
+
+func TestSetExitStatusFunc(t *testing.T) {
+	const customStatus = 99
+
+	h := phelp.NewStdHelp(
+		phelp.SetErrWriter(io.Discard),
+		phelp.SetExitStatusFunc(func(errutil.ErrMap) int {
+			return customStatus
+		}))
+	ps := param.NewSet(h)
+	ps.AddErr("p", errors.New("bad"))
+
+	h.ErrorHandler(ps)
+
+	testhelper.DiffInt(t, "custom exit status", "exit status",
+		ps.ExitStatus(), customStatus)
+
+	tc := struct {
+		testhelper.ID
+		testhelper.ExpPanic
+	}{
+		ID: testhelper.MkID("nil exit status func"),
+		ExpPanic: testhelper.MkExpPanic(
+			"phelp.SetExitStatusFunc cannot take a nil value"),
+	}
+
+	panicked, panicVal := testhelper.PanicSafe(func() {
+		phelp.NewStdHelp(phelp.SetExitStatusFunc(nil))
+	})
+	testhelper.CheckExpPanicError(t, panicked, panicVal, tc)
+}
@@ -0,0 +1,327 @@
+package phelp
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// ExportedConfigFile describes a configuration file
+type ExportedConfigFile struct {
+	Name            string `json:"name"`
+	MustExist       bool   `json:"mustExist"`
+	ParamsMustExist bool   `json:"paramsMustExist"`
+	Format          string `json:"format"`
+}
+
+// ExportedConfigDir describes a configuration directory
+type ExportedConfigDir struct {
+	Name            string `json:"name"`
+	Pattern         string `json:"pattern"`
+	MustExist       bool   `json:"mustExist"`
+	ParamsMustExist bool   `json:"paramsMustExist"`
+}
+
+// ExportedParam describes a named or positional parameter
+type ExportedParam struct {
+	Name          string              `json:"name"`
+	AltNames      []string            `json:"altNames,omitempty"`
+	ShortName     string              `json:"shortName,omitempty"`
+	NegatedNames  []string            `json:"negatedNames,omitempty"`
+	EnvVars       []string            `json:"envVars,omitempty"`
+	Description   string              `json:"description"`
+	ValueReq      string              `json:"valueReq"`
+	ValueName     string              `json:"valueName,omitempty"`
+	ValueDesc     string              `json:"valueDesc,omitempty"`
+	AllowedValues string              `json:"allowedValues,omitempty"`
+	AllowedVals   map[string]string   `json:"allowedVals,omitempty"`
+	Aliases       map[string][]string `json:"aliases,omitempty"`
+	InitialValue  string              `json:"initialValue"`
+	Attributes    []string            `json:"attributes,omitempty"`
+	IsTerminal    bool                `json:"isTerminal,omitempty"`
+	SeeAlso       []string            `json:"seeAlso,omitempty"`
+	SeeNotes      []string            `json:"seeNotes,omitempty"`
+	Constraints   []string            `json:"constraints,omitempty"`
+}
+
+// ExportedGroup describes a parameter group
+type ExportedGroup struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	ConfigFiles []ExportedConfigFile `json:"configFiles,omitempty"`
+	Params      []ExportedParam      `json:"params"`
+}
+
+// ExportedNote describes a note
+type ExportedNote struct {
+	Headline   string   `json:"headline"`
+	Text       string   `json:"text"`
+	Attributes []string `json:"attributes,omitempty"`
+	SeeNotes   []string `json:"seeNotes,omitempty"`
+	SeeParams  []string `json:"seeParams,omitempty"`
+}
+
+// ExportedExample describes an example
+type ExportedExample struct {
+	Example     string `json:"example"`
+	Description string `json:"description"`
+}
+
+// ExportedReference describes a reference
+type ExportedReference struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ExportedSubCommand describes a sub-command
+type ExportedSubCommand struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	PSet        ExportedPSet `json:"pset"`
+}
+
+// ExportedPSet describes a parameter set. It is intended to be converted
+// into JSON for use by other programs.
+type ExportedPSet struct {
+	ProgName               string               `json:"progName"`
+	ProgBaseName           string               `json:"progBaseName"`
+	Description            string               `json:"description,omitempty"`
+	ParamPrefixes          []string             `json:"paramPrefixes"`
+	Groups                 []ExportedGroup      `json:"groups"`
+	PositionalParams       []ExportedParam      `json:"positionalParams,omitempty"`
+	TrailingParamsExpected bool                 `json:"trailingParamsExpected"`
+	TrailingParamsName     string               `json:"trailingParamsName,omitempty"`
+	TerminalParam          string               `json:"terminalParam,omitempty"`
+	Notes                  []ExportedNote       `json:"notes,omitempty"`
+	Examples               []ExportedExample    `json:"examples,omitempty"`
+	References             []ExportedReference  `json:"references,omitempty"`
+	ConfigFiles            []ExportedConfigFile `json:"configFiles,omitempty"`
+	ConfigDirs             []ExportedConfigDir  `json:"configDirs,omitempty"`
+	EnvFiles               []ExportedConfigFile `json:"envFiles,omitempty"`
+	FinalConfigFiles       []ExportedConfigFile `json:"finalConfigFiles,omitempty"`
+	SourceOrder            []string             `json:"sourceOrder"`
+	EnvPrefixes            []string             `json:"envPrefixes,omitempty"`
+	Constraints            []string             `json:"constraints,omitempty"`
+	SubCommands            []ExportedSubCommand `json:"subCommands,omitempty"`
+}
+
+// paramAttrNames maps the parameter attributes to the names by which they
+// are exported
+var paramAttrNames = []struct {
+	attr param.Attributes
+	name string
+}{
+	{param.CommandLineOnly, "CommandLineOnly"},
+	{param.MustBeSet, "MustBeSet"},
+	{param.SetOnlyOnce, "SetOnlyOnce"},
+	{param.DontShowInStdUsage, "DontShowInStdUsage"},
+	{param.IsTerminalParam, "IsTerminalParam"},
+	{param.Reloadable, "Reloadable"},
+}
+
+// exportSourceOrder returns the names of the sources of parameter values in
+// the order in which they are processed
+func exportSourceOrder(ps *param.PSet) []string {
+	var names []string
+
+	for _, st := range ps.SourceOrder() {
+		names = append(names, st.String())
+	}
+
+	return names
+}
+
+// exportConfigDirs converts the config directory details for export
+func exportConfigDirs(cdds []param.ConfigDirDetails) []ExportedConfigDir {
+	ecds := make([]ExportedConfigDir, 0, len(cdds))
+
+	for _, cdd := range cdds {
+		ecds = append(ecds, ExportedConfigDir{
+			Name:            cdd.Name,
+			Pattern:         cdd.Pattern,
+			MustExist:       cdd.CfConstraint == filecheck.MustExist,
+			ParamsMustExist: cdd.ParamsMustExist(),
+		})
+	}
+
+	return ecds
+}
+
+// exportConfigFiles converts the config file details for export
+func exportConfigFiles(cfds []param.ConfigFileDetails) []ExportedConfigFile {
+	ecfs := make([]ExportedConfigFile, 0, len(cfds))
+
+	for _, cfd := range cfds {
+		ecfs = append(ecfs, ExportedConfigFile{
+			Name:            cfd.Name,
+			MustExist:       cfd.CfConstraint == filecheck.MustExist,
+			ParamsMustExist: cfd.ParamsMustExist(),
+			Format:          cfd.Format.String(),
+		})
+	}
+
+	return ecfs
+}
+
+// exportBaseParam returns the parts of the exported parameter common to
+// both named and positional parameters
+func exportBaseParam(p param.BaseParam) ExportedParam {
+	s := p.Setter()
+	ep := ExportedParam{
+		Name:          p.Name(),
+		Description:   p.Description(),
+		ValueReq:      s.ValueReq().String(),
+		ValueName:     p.ValueName(),
+		AllowedValues: s.AllowedValues(),
+		InitialValue:  p.InitialValue(),
+		SeeAlso:       p.SeeAlso(),
+		SeeNotes:      p.SeeNotes(),
+	}
+
+	if vd, ok := s.(ptypes.ValDescriber); ok {
+		ep.ValueDesc = vd.ValDescribe()
+	}
+
+	if avm, ok := s.(ptypes.AllowedValuesMapper); ok {
+		ep.AllowedVals = avm.AllowedValuesMap()
+	}
+
+	if aam, ok := s.(ptypes.AllowedValuesAliasMapper); ok {
+		ep.Aliases = aam.AllowedValuesAliasMap()
+	}
+
+	return ep
+}
+
+// exportByName converts the named parameter for export
+func exportByName(ps *param.PSet, p *param.ByName) ExportedParam {
+	ep := exportBaseParam(p.BaseParam)
+
+	ep.AltNames = slices.DeleteFunc(p.AltNames(),
+		func(n string) bool { return n == p.Name() })
+	ep.NegatedNames = p.NegatedNames()
+	ep.EnvVars = p.EnvVars()
+
+	if sn := p.ShortName(); sn != 0 {
+		ep.ShortName = string(sn)
+	}
+
+	for _, a := range paramAttrNames {
+		if p.AttrIsSet(a.attr) {
+			ep.Attributes = append(ep.Attributes, a.name)
+		}
+	}
+
+	for _, c := range ps.ConstraintsOnParam(p.Name()) {
+		ep.Constraints = append(ep.Constraints, c.String())
+	}
+
+	return ep
+}
+
+// exportNotes converts the notes for export, sorted by headline
+func exportNotes(ps *param.PSet) []ExportedNote {
+	notes := ps.Notes()
+	ens := make([]ExportedNote, 0, len(notes))
+
+	for _, h := range slices.Sorted(maps.Keys(notes)) {
+		n := notes[h]
+		en := ExportedNote{
+			Headline:  n.Headline(),
+			Text:      n.Text(),
+			SeeNotes:  n.SeeNotes(),
+			SeeParams: n.SeeParams(),
+		}
+
+		if n.AttrIsSet(param.DontShowNoteInStdUsage) {
+			en.Attributes = append(en.Attributes, "DontShowNoteInStdUsage")
+		}
+
+		ens = append(ens, en)
+	}
+
+	return ens
+}
+
+// Export returns a description of the parameter set suitable for converting
+// into JSON or some other machine-readable format. It includes every
+// parameter group and every named and positional parameter together with
+// the notes, examples, references, configuration files, environment
+// prefixes, constraints and sub-commands.
+func Export(ps *param.PSet) ExportedPSet {
+	eps := ExportedPSet{
+		ProgName:               ps.ProgName(),
+		ProgBaseName:           ps.ProgBaseName(),
+		Description:            ps.ProgDesc(),
+		ParamPrefixes:          ps.ParamPrefixes(),
+		TrailingParamsExpected: ps.TrailingParamsExpected(),
+		TrailingParamsName:     ps.TrailingParamsName(),
+		TerminalParam:          ps.TerminalParam(),
+		Notes:                  exportNotes(ps),
+		ConfigFiles:            exportConfigFiles(ps.ConfigFiles()),
+		ConfigDirs:             exportConfigDirs(ps.ConfigDirs()),
+		EnvFiles:               exportConfigFiles(ps.EnvFiles()),
+		FinalConfigFiles:       exportConfigFiles(ps.FinalConfigFiles()),
+		SourceOrder:            exportSourceOrder(ps),
+		EnvPrefixes:            ps.EnvPrefixes(),
+	}
+
+	for _, g := range ps.GetGroups() {
+		eg := ExportedGroup{
+			Name:        g.Name(),
+			Description: g.Desc(),
+			ConfigFiles: exportConfigFiles(g.ConfigFiles()),
+		}
+
+		for _, p := range g.Params() {
+			eg.Params = append(eg.Params, exportByName(ps, p))
+		}
+
+		eps.Groups = append(eps.Groups, eg)
+	}
+
+	for i := range ps.CountByPosParams() {
+		bp, err := ps.GetParamByPos(i)
+		if err != nil {
+			continue
+		}
+
+		ep := exportBaseParam(bp.BaseParam)
+		ep.IsTerminal = bp.IsTerminal()
+		eps.PositionalParams = append(eps.PositionalParams, ep)
+	}
+
+	for _, ex := range ps.Examples() {
+		eps.Examples = append(eps.Examples,
+			ExportedExample{Example: ex.Ex(), Description: ex.Desc()})
+	}
+
+	for _, r := range ps.References() {
+		eps.References = append(eps.References,
+			ExportedReference{Name: r.Name(), Description: r.Desc()})
+	}
+
+	for _, c := range ps.Constraints() {
+		eps.Constraints = append(eps.Constraints, c.String())
+	}
+
+	for _, sc := range ps.SubCommands() {
+		eps.SubCommands = append(eps.SubCommands, ExportedSubCommand{
+			Name:        sc.Name(),
+			Description: sc.Desc(),
+			PSet:        Export(sc.PSet()),
+		})
+	}
+
+	return eps
+}
+
+// ExportJSON returns a JSON description of the parameter set. See Export
+// for details of what is included.
+func ExportJSON(ps *param.PSet) ([]byte, error) {
+	return json.MarshalIndent(Export(ps), "", "  ")
+}
@@ -0,0 +1,226 @@
+package phelp
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"github.com/nickwells/twrap.mod/twrap"
+)
+
+// fishCompHasAction returns true if the StdHelp fish Completion Action is
+// not None, false otherwise.
+func fishCompHasAction(h *StdHelp) bool {
+	return h.fishCompAction != compActionNone
+}
+
+// fishQuote returns the string as a fish single-quoted string
+func fishQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+	return "'" + r.Replace(s) + "'"
+}
+
+// fishShortDesc returns the first sentence of the description with any
+// white space collapsed so that it can be shown alongside the completion.
+func fishShortDesc(desc string) string {
+	desc, _, _ = strings.Cut(desc, "\n")
+	if first, _, found := strings.Cut(desc, ". "); found {
+		desc = first
+	}
+
+	return strings.TrimSuffix(strings.Join(strings.Fields(desc), " "), ".")
+}
+
+// fishArgDesc returns the description made safe to appear as the
+// description of an argument. The argument list is evaluated by fish and so
+// any characters which might be expanded are replaced with spaces.
+func fishArgDesc(desc string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`'"\$`, r) {
+			return ' '
+		}
+
+		return r
+	}, fishShortDesc(desc))
+}
+
+// fishArgs returns the value of the argument list to be offered as
+// completions for a parameter with the given setter. Any words are given
+// together with their descriptions. It returns the empty string if no
+// arguments can be offered.
+func fishArgs(s param.Setter) string {
+	spec := compSpec(s)
+
+	switch spec.Kind {
+	case ptypes.CompleteFiles:
+		return fishQuote("(__fish_complete_path (commandline -ct))")
+	case ptypes.CompleteDirs:
+		return fishQuote("(__fish_complete_directories (commandline -ct))")
+	case ptypes.CompleteHostnames:
+		return fishQuote("(__fish_print_hostnames)")
+	case ptypes.CompleteWords:
+	default:
+		return ""
+	}
+
+	args := make([]string, 0, len(spec.Words))
+
+	for _, w := range spec.Words {
+		if !compSafeWord(w) {
+			continue
+		}
+
+		if desc, ok := spec.WordDescs[w]; ok {
+			w += `\t"` + fishArgDesc(desc) + `"`
+		}
+
+		args = append(args, w)
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	return "'" + strings.Join(args, " ") + "'"
+}
+
+// fishOptNames returns the fish options giving the names of the parameter
+func fishOptNames(names []string) string {
+	var opts strings.Builder
+
+	for _, name := range names {
+		if len([]rune(name)) == 1 {
+			opts.WriteString(" -s " + fishQuote(name))
+		} else {
+			opts.WriteString(" -o " + fishQuote(name))
+		}
+	}
+
+	return opts.String()
+}
+
+// fishParamCompletions returns the fish complete commands for the
+// parameter. The parameter names are given as old-style options (a single
+// leading dash). A parameter which must take a value has its value
+// completed from any allowed values.
+func fishParamCompletions(prog string, p *param.ByName) []string {
+	names := p.AltNames()
+	if sn := p.ShortName(); sn != 0 {
+		names = append(names, string(sn))
+	}
+
+	cmd := "complete -c " + prog +
+		fishOptNames(names) +
+		" -d " + fishQuote(fishShortDesc(p.Description()))
+
+	if p.Setter().ValueReq() == param.Mandatory {
+		cmd += " -x"
+		if args := fishArgs(p.Setter()); args != "" {
+			cmd += " -a " + args
+		}
+	}
+
+	cmds := []string{cmd}
+
+	if negNames := p.NegatedNames(); len(negNames) > 0 {
+		cmds = append(cmds, "complete -c "+prog+
+			fishOptNames(negNames)+
+			" -d "+fishQuote("the negated form of -"+p.Name()))
+	}
+
+	return cmds
+}
+
+// fishWriteCompFunc writes the fish completions for the current executable
+func fishWriteCompFunc(ps *param.PSet, w io.Writer) {
+	prog := fishQuote(ps.ProgBaseName())
+
+	fmt.Fprintf(w, "# fish completions for %s\n\n", ps.ProgBaseName())
+
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			for _, cmd := range fishParamCompletions(prog, p) {
+				fmt.Fprintln(w, cmd)
+			}
+		}
+	}
+}
+
+// fishCompletionHandler performs the appropriate action according to the
+// setting of the StdHelp fishCompAction member. It returns a suggested
+// exit status.
+func fishCompletionHandler(h *StdHelp, twc *twrap.TWConf, ps *param.PSet) int {
+	cfw := h.chooseCompFuncWriter(fishWriteCompFunc, fishWriteDynamicCompFunc)
+
+	switch h.fishCompAction {
+	case compActionNone:
+		return 0
+	case compActionShow:
+		cfw(ps, twc.W)
+		return 0
+	case compActionNew:
+		filename := fishCompFileName(h, ps)
+
+		err := makeNewCompFile(filename, ps, cfw)
+		if err == nil {
+			fishCompFileNotify(h, twc, filename)
+		}
+
+		return compHandleErr(err, ps, "fish")
+	case compActionRepl:
+		filename := fishCompFileName(h, ps)
+
+		err := replaceCompFile(filename, ps, cfw)
+		if err == nil {
+			fishCompFileNotify(h, twc, filename)
+		}
+
+		return compHandleErr(err, ps, "fish")
+	}
+
+	return compHandleErr(compUnknownActionErr("fish", h.fishCompAction), ps,
+		"fish")
+}
+
+// fishCompFileName returns the name of the completions file. This is the
+// name that fish expects when loading completions for the program.
+func fishCompFileName(h *StdHelp, ps *param.PSet) string {
+	return filepath.Join(h.fishCompDir, ps.ProgBaseName()+".fish")
+}
+
+// fishCompFileNotify writes a notification message informing the user that
+// the completion file has been successfully created.
+func fishCompFileNotify(h *StdHelp, twc *twrap.TWConf, filename string) {
+	if h.completionsQuiet {
+		return
+	}
+
+	twc.Wrap(
+		"the fish completions have been written to "+filename+"."+
+			" They will be loaded the next time you complete the"+
+			" program name if the directory is in the"+
+			" fish_complete_path variable."+
+			" Please see the fish manual for more details.",
+		0)
+}
+
+// fishWriteDynamicCompFunc writes fish completions for the current
+// executable which call the program to find the completions
+func fishWriteDynamicCompFunc(ps *param.PSet, w io.Writer) {
+	prog := fishQuote(ps.ProgBaseName())
+	funcName := "_" + bashFuncName(ps) + "_complete"
+
+	fmt.Fprintf(w, "# fish completions for %s\n\n", ps.ProgBaseName())
+	fmt.Fprintf(w, "function %s\n", funcName)
+	fmt.Fprintln(w, "\tset -l tokens (commandline -opc) (commandline -ct)")
+	fmt.Fprintf(w, "\t$tokens[1] %s $tokens[2..-1] 2>/dev/null\n",
+		compQueryCmd(ps))
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -c %s -f -a %s\n",
+		prog, fishQuote("("+funcName+")"))
+}
@@ -35,6 +35,17 @@ func (h StdHelp) printHelpMessages(messages ...string) {
 // standard writer (stdout) and os.Exit will be called with an exit status of
 // 1 to indicate an error.
 func (h StdHelp) Help(ps *param.PSet, messages ...string) {
+	switch h.helpFormat {
+	case helpFmtTypeJSON:
+		h.helpJSON(ps, messages...)
+
+		return
+	case helpFmtTypeMan:
+		h.helpMan(ps, messages...)
+
+		return
+	}
+
 	if h.pageOutput {
 		p := pager.Start(&h)
 		defer p.Done()
@@ -60,14 +71,49 @@ func (h StdHelp) Help(ps *param.PSet, messages ...string) {
 		sep = majorSectionSeparator
 	}
 
+	cmdPS := chosenSubCommandPSet(ps)
+
 	for _, sec := range helpSectionsInOrder {
 		if h.sectionsChosen[sec.name] {
 			h.twc.Print(sep)
 
+			secPS := ps
+			if sec.subCmdSpecific {
+				secPS = cmdPS
+			}
+
 			sep = majorSectionSeparator
-			if !sec.displayFunc(h, ps) {
+			if !sec.displayFunc(h, secPS) {
 				sep = ""
 			}
 		}
 	}
 }
+
+// helpJSON writes any messages to the error writer and then writes the JSON
+// description of the parameter set to the standard writer
+func (h StdHelp) helpJSON(ps *param.PSet, messages ...string) {
+	for _, message := range messages {
+		fmt.Fprintln(h.ErrW(), message)
+	}
+
+	j, err := ExportJSON(ps)
+	if err != nil {
+		fmt.Fprintln(h.ErrW(), "Couldn't make the JSON help message:", err)
+
+		return
+	}
+
+	fmt.Fprintln(h.StdW(), string(j))
+}
+
+// chosenSubCommandPSet returns the PSet of the most deeply nested
+// sub-command that has been chosen. If no sub-command has been chosen it
+// returns the PSet passed.
+func chosenSubCommandPSet(ps *param.PSet) *param.PSet {
+	for sc := ps.SubCommandChosen(); sc != nil; sc = ps.SubCommandChosen() {
+		ps = sc.PSet()
+	}
+
+	return ps
+}
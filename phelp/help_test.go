@@ -52,6 +52,22 @@ var (
 	str6 = "v2"
 )
 
+var (
+	verbosity7 int
+	colourVal8 = true
+)
+
+var editor9 = "vi"
+
+var reloadable10 int64
+
+var newName11, oldName12 string
+
+var (
+	target13 string
+	force14  bool
+)
+
 // setInitialValues sets the parameters to their initial values - resetting
 // any values overwritten by previous tests
 func setInitialValues() {
@@ -64,6 +80,14 @@ func setInitialValues() {
 	boolVal4 = false
 	str5 = "v1"
 	str6 = "v2"
+	verbosity7 = 0
+	colourVal8 = true
+	editor9 = "vi"
+	reloadable10 = 0
+	newName11 = ""
+	oldName12 = ""
+	target13 = ""
+	force14 = false
 }
 
 // addByPosParams will add positional parameters to the passed ParamSet
@@ -134,6 +158,146 @@ func addByNameParams(ps *param.PSet) error {
 	return nil
 }
 
+// addShortNameParams will add named parameters with short names and
+// negatable parameters to the passed ParamSet. Note that the param set must
+// allow short names.
+func addShortNameParams(ps *param.PSet) error {
+	ps.AddGroup(paramGroupName, "test parameters.")
+
+	ps.Add("verbose", psetter.Counter[int]{Value: &verbosity7},
+		"help text for verbose",
+		param.GroupName(paramGroupName),
+		param.ShortName('v'),
+	)
+
+	ps.Add("colour", psetter.Bool{Value: &colourVal8},
+		"help text for colour",
+		param.GroupName(paramGroupName),
+		param.AltNames("color"),
+		param.ShortName('c'),
+		param.Negatable,
+	)
+
+	return nil
+}
+
+// addSubCommands will add sub-commands, each with its own parameters, to
+// the passed ParamSet
+func addSubCommands(ps *param.PSet) error {
+	ps.AddSubCommand("build", "build the thing", func(ps *param.PSet) {
+		ps.Add("target", psetter.String[string]{Value: &target13},
+			"help text for the target to build")
+	})
+	ps.AddSubCommand("clean", "clean up after a build", func(ps *param.PSet) {
+		ps.Add("force", psetter.Bool{Value: &force14},
+			"help text for force")
+	})
+
+	return nil
+}
+
+// addConstraints will add constraints on the named parameters added by
+// addByNameParams to the passed ParamSet
+func addConstraints(ps *param.PSet) error {
+	ps.AddConstraint(
+		param.MutuallyExclusive("param5", "param6"),
+		param.Requires("param1", "param3"),
+	)
+
+	return nil
+}
+
+// addManPageExtras will add a note, an example, a reference and an
+// environment prefix to the passed ParamSet
+func addManPageExtras(ps *param.PSet) error {
+	ps.AddNote("a note", "the text of the note.\n\nA second paragraph")
+	ps.AddExample("prog -param2 42", "set param2 to 42")
+	ps.AddReference("other-prog", "another program")
+	ps.SetEnvPrefix("PROG_")
+
+	return nil
+}
+
+// addEnvVarParams will add a parameter bound to environment variables and
+// an env file to the passed ParamSet
+func addEnvVarParams(ps *param.PSet) error {
+	ps.Add("editor", psetter.String[string]{Value: &editor9},
+		"the editor to use",
+		param.GroupName(paramGroupName),
+		param.EnvVar("PHELP_TEST_VISUAL", "PHELP_TEST_EDITOR"),
+	)
+
+	ps.AddEnvFile("testdata/no-such-file.env", filecheck.Optional)
+
+	return nil
+}
+
+// addSourceOrder will add a final config file to the passed ParamSet and
+// change the order in which the sources are processed
+func addSourceOrder(ps *param.PSet) error {
+	ps.AddFinalConfigFile("testdata/no-such-final.cfg", filecheck.Optional)
+
+	return param.SetSourceOrder(
+		param.EnvironmentSource,
+		param.ConfigFileSource,
+		param.CommandLineSource,
+		param.FinalConfigFileSource,
+	)(ps)
+}
+
+// addSourceOrderCfgLast will add a config file and a final config file to
+// the passed ParamSet and change the order in which the sources are
+// processed so that the config files are processed after the command line
+// and the final config files before it
+func addSourceOrderCfgLast(ps *param.PSet) error {
+	ps.AddConfigFile("testdata/no-such-config.cfg", filecheck.Optional)
+	ps.AddFinalConfigFile("testdata/no-such-final.cfg", filecheck.Optional)
+
+	return param.SetSourceOrder(
+		param.FinalConfigFileSource,
+		param.EnvFileSource,
+		param.CommandLineSource,
+		param.ConfigFileSource,
+	)(ps)
+}
+
+// addReloadableParam will add a Reloadable parameter to the passed ParamSet
+func addReloadableParam(ps *param.PSet) error {
+	ps.Add("reloadable", psetter.Int[int64]{Value: &reloadable10},
+		"help text for a reloadable parameter",
+		param.GroupName(paramGroupName),
+		param.Attrs(param.Reloadable),
+	)
+
+	return nil
+}
+
+// addDeprecatedParams will add a deprecated parameter and a parameter with
+// deprecated alternative names to the passed ParamSet
+func addDeprecatedParams(ps *param.PSet) error {
+	ps.Add("new-name", psetter.String[string]{Value: &newName11},
+		"help text for a renamed parameter",
+		param.GroupName(paramGroupName),
+		param.DeprecatedAltNames("renamed for consistency",
+			"new-nm", "newname"),
+	)
+	ps.Add("old-name", psetter.String[string]{Value: &oldName12},
+		"help text for a deprecated parameter",
+		param.GroupName(paramGroupName),
+		param.Deprecated("this will be removed soon", "new-name"),
+	)
+
+	return nil
+}
+
+// addConfigDirs will add config directories to the passed ParamSet
+func addConfigDirs(ps *param.PSet) error {
+	ps.AddConfigDir("testdata/conf.d", "*.conf", filecheck.Optional)
+	ps.AddConfigDirStrict("testdata/no-such-dir", "", filecheck.Optional)
+
+	return nil
+}
+
 // configFileDetails records details about the type of config file to be set
 // up for the param set
 type configFileDetails struct {
@@ -327,6 +491,63 @@ func TestHelp(t *testing.T) {
 			errsExpected: true,
 			paramAdder:   []param.PSetOptFunc{addByNameParams},
 		},
+		{
+			ID:         testhelper.MkID("help-format-json"),
+			progDesc:   progDesc,
+			params:     []string{"-help-format", "json", "-param2=99"},
+			paramAdder: []param.PSetOptFunc{addByNameParams, addConstraints},
+		},
+		{
+			ID:       testhelper.MkID("help-format-man"),
+			progDesc: progDesc,
+			params:   []string{"-help-format", "man", "-param2=99"},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addManPageExtras,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-env-vars"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-params", "editor",
+				"-help-show", "params-grouped,sources",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addEnvVarParams,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-env-vars-man"),
+			progDesc: progDesc,
+			params:   []string{"-help-format", "man", "-param2=99"},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addEnvVarParams,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-format-man-ordered"),
+			progDesc: progDesc,
+			params:   []string{"-help-format", "man", "-param2=99"},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addEnvVarParams,
+				addSourceOrderCfgLast,
+			},
+		},
+		{
+			ID:       testhelper.MkID("params-dump-config"),
+			progDesc: progDesc,
+			params: []string{
+				"-param2", "3",
+				"-param4",
+				"-params-dump-config",
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
 		{
 			ID:       testhelper.MkID("help-show-sources"),
 			progDesc: progDesc,
@@ -336,6 +557,81 @@ func TestHelp(t *testing.T) {
 			},
 			paramAdder: []param.PSetOptFunc{addByNameParams},
 		},
+		{
+			ID:       testhelper.MkID("help-show-sources-ordered"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-show=sources",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addEnvVarParams,
+				addSourceOrder,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-params-reloadable"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-params", "reloadable",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addReloadableParam,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-show-deprecated"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-show=params-grouped,deprecated",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addDeprecatedParams,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-params-deprecated"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-params", "old-name",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addDeprecatedParams,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-show-sources-profiles"),
+			progDesc: progDesc,
+			params: []string{
+				"-params-profile", "dev,prod",
+				"-help-show=sources",
+				"-param2=99",
+			},
+			configFiles: []configFileDetails{
+				{
+					name: filepath.Join(testDataDir, cfgFileDir,
+						"profiles.cfg"),
+					mustExist: true,
+				},
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams},
+		},
+		{
+			ID:       testhelper.MkID("help-show-sources-dirs"),
+			progDesc: progDesc,
+			params: []string{
+				"-help-show=sources",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams, addConfigDirs},
+		},
 		{
 			ID:       testhelper.MkID("params-file-cmdline-param"),
 			progDesc: progDesc,
@@ -449,6 +745,47 @@ func TestHelp(t *testing.T) {
 			params:     []string{"123", "456", "-help", "-param2=99"},
 			paramAdder: []param.PSetOptFunc{addByNameParams, addByPosParams},
 		},
+		{
+			ID:       testhelper.MkID("help-constraints"),
+			progDesc: progDesc + " (help-constraints)",
+			params: []string{
+				"-help-show", "constraints,params-grouped",
+				"-param2=99",
+			},
+			paramAdder: []param.PSetOptFunc{addByNameParams, addConstraints},
+		},
+		{
+			ID:       testhelper.MkID("help-short-names"),
+			progDesc: progDesc + " (help-short-names)",
+			params: []string{
+				"-help-groups", paramGroupName,
+				"-vv", "-no-color",
+			},
+			paramAdder: []param.PSetOptFunc{
+				param.SetShortNamesAllowed,
+				addShortNameParams,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-sub-commands"),
+			progDesc: progDesc + " (help-sub-commands)",
+			params:   []string{"-help", "-param2=99"},
+			// no sub-command is given but the error is not shown
+			errsExpected: true,
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addSubCommands,
+			},
+		},
+		{
+			ID:       testhelper.MkID("help-sub-command-build"),
+			progDesc: progDesc + " (help-sub-command-build)",
+			params:   []string{"-param2=99", "build", "-help"},
+			paramAdder: []param.PSetOptFunc{
+				addByNameParams,
+				addSubCommands,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
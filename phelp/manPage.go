@@ -0,0 +1,412 @@
+package phelp
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/phelputils"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// manWriter holds the state needed while writing a man page
+type manWriter struct {
+	w io.Writer
+}
+
+// manEscape returns the text with any characters which have a special
+// meaning to roff escaped
+func manEscape(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\e`,
+		`-`, `\-`,
+	)
+
+	s = r.Replace(s)
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// macro writes a roff macro with its (unescaped) arguments
+func (mw manWriter) macro(name string, args ...string) {
+	fmt.Fprint(mw.w, "."+name)
+
+	for _, a := range args {
+		fmt.Fprint(mw.w, " ", a)
+	}
+
+	fmt.Fprintln(mw.w)
+}
+
+// section writes a section heading
+func (mw manWriter) section(name string) {
+	mw.macro("SH", name)
+}
+
+// text writes the text as a sequence of paragraphs. Each blank line in the
+// text starts a new paragraph and single newlines force a line break.
+func (mw manWriter) text(s string) {
+	for i, para := range strings.Split(s, "\n\n") {
+		if i > 0 {
+			mw.macro("PP")
+		}
+
+		lines := strings.Split(para, "\n")
+		for j, l := range lines {
+			if j > 0 {
+				mw.macro("br")
+			}
+
+			fmt.Fprintln(mw.w, manEscape(l))
+		}
+	}
+}
+
+// tagged writes a tagged paragraph: the tag (which should already be in
+// roff format) followed by the indented text
+func (mw manWriter) tagged(tag, text string) {
+	mw.macro("TP")
+	fmt.Fprintln(mw.w, tag)
+	mw.text(text)
+}
+
+// manProgName returns the program name as it should appear in the man page
+func manProgName(ps *param.PSet) string {
+	return manEscape(ps.ProgBaseName())
+}
+
+// manNameDesc returns the first sentence of the program description for use
+// in the NAME section
+func manNameDesc(ps *param.PSet) string {
+	desc, _, _ := strings.Cut(ps.ProgDesc(), "\n")
+	if first, _, found := strings.Cut(desc, ". "); found {
+		desc = first
+	}
+
+	return strings.TrimSuffix(desc, ".")
+}
+
+// writeHeader writes the title line and the NAME section
+func (mw manWriter) writeHeader(ps *param.PSet) {
+	mw.macro("TH", `"`+strings.ToUpper(manProgName(ps))+`"`, "1")
+
+	mw.section("NAME")
+
+	name := manProgName(ps)
+	if desc := manNameDesc(ps); desc != "" {
+		name += ` \- ` + manEscape(desc)
+	}
+
+	fmt.Fprintln(mw.w, name)
+}
+
+// writeSynopsis writes the SYNOPSIS section
+func (mw manWriter) writeSynopsis(ps *param.PSet) {
+	mw.section("SYNOPSIS")
+	mw.macro("B", manProgName(ps))
+
+	for i := range ps.CountByPosParams() {
+		bp, _ := ps.GetParamByPos(i)
+		mw.macro("I", manEscape(bp.Name()))
+	}
+
+	hasOptionalParams := false
+
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			if p.AttrIsSet(param.MustBeSet) {
+				mw.macro("B", manEscape(phelputils.ParamShortSummary(*p)))
+			} else {
+				hasOptionalParams = true
+			}
+		}
+	}
+
+	if hasOptionalParams {
+		mw.macro("RI", `[ options ]`)
+	}
+
+	if ps.HasSubCommands() {
+		mw.macro("I", "sub\\-command ...")
+	}
+
+	if ps.TrailingParamsExpected() {
+		mw.macro("RB", `[ `+manEscape(ps.TerminalParam()),
+			`\fI`+manEscape(ps.TrailingParamsName())+`\fR ... ]`)
+	}
+}
+
+// paramText returns the description of the parameter together with its
+// allowed values and initial value
+func paramText(p param.BaseParam) string {
+	text := p.Description()
+
+	if p.Setter().ValueReq() != param.None {
+		text += "\n\nAllowed values: " + p.Setter().AllowedValues()
+
+		if avm, ok := p.Setter().(ptypes.AllowedValuesMapper); ok {
+			av := avm.AllowedValuesMap()
+			for _, k := range slices.Sorted(maps.Keys(av)) {
+				text += "\n" + k + ": " + av[k]
+			}
+		}
+
+		if iv := p.InitialValue(); iv != "" {
+			text += "\n\nInitial value: " + iv
+		}
+	}
+
+	return text
+}
+
+// writeOptions writes the OPTIONS section with a sub-section for each
+// parameter group
+func (mw manWriter) writeOptions(ps *param.PSet) {
+	mw.section("OPTIONS")
+
+	for _, g := range ps.GetGroups() {
+		mw.macro("SS", `"`+manEscape(g.Name())+`"`)
+		mw.text(g.Desc())
+
+		for _, p := range g.Params() {
+			mw.tagged(`\fB`+manEscape(phelputils.ParamSummary(*p))+`\fR`,
+				paramText(p.BaseParam))
+		}
+	}
+}
+
+// writeArguments writes the ARGUMENTS section describing the positional
+// parameters, if there are any
+func (mw manWriter) writeArguments(ps *param.PSet) {
+	if ps.CountByPosParams() == 0 && !ps.HasSubCommands() {
+		return
+	}
+
+	mw.section("ARGUMENTS")
+
+	for i := range ps.CountByPosParams() {
+		bp, _ := ps.GetParamByPos(i)
+		mw.tagged(`\fI`+manEscape(bp.Name())+`\fR`, paramText(bp.BaseParam))
+	}
+
+	for _, sc := range ps.SubCommands() {
+		mw.tagged(`\fB`+manEscape(sc.Name())+`\fR`, sc.Desc())
+	}
+}
+
+// writeEnvironment writes the ENVIRONMENT section, if there are any
+// environment prefixes or environment variables bound to parameters
+func (mw manWriter) writeEnvironment(ps *param.PSet) {
+	ep := ps.EnvPrefixes()
+	pev := getParamEnvVars(ps)
+
+	if len(ep) == 0 && len(pev) == 0 {
+		return
+	}
+
+	mw.section("ENVIRONMENT")
+
+	if len(ep) > 0 {
+		mw.text("Parameters can also be set through environment variables." +
+			" The environment variable name is the parameter name with any" +
+			" dashes replaced by underscores and preceded by" +
+			" one of these prefixes:")
+
+		for _, e := range ep {
+			mw.macro("IP", `\(bu`, "2")
+			fmt.Fprintln(mw.w, `\fB`+manEscape(e)+`\fR`)
+		}
+	}
+
+	for _, p := range pev {
+		for _, ev := range p.envVars {
+			mw.tagged(`\fB`+manEscape(ev)+`\fR`,
+				"sets the value of the "+
+					ps.ShortestPrefix()+p.paramName+" parameter.")
+		}
+	}
+}
+
+// writeFiles writes the FILES section, if there are any configuration files
+// or env files. The files are given in the order in which the sources are
+// processed and those from sources which are disabled are not shown.
+func (mw manWriter) writeFiles(ps *param.PSet) {
+	cf := ps.ConfigFiles()
+	cd := ps.ConfigDirs()
+	gf := getGroupConfigFiles(ps)
+	ef := ps.EnvFiles()
+	ff := ps.FinalConfigFiles()
+
+	order := ps.SourceOrder()
+	hasFiles := map[param.SourceType]bool{
+		param.GroupConfigFileSource: len(gf) > 0,
+		param.ConfigFileSource:      len(cf) > 0 || len(cd) > 0,
+		param.EnvFileSource:         len(ef) > 0,
+		param.FinalConfigFileSource: len(ff) > 0,
+	}
+
+	if !slices.ContainsFunc(order,
+		func(st param.SourceType) bool { return hasFiles[st] }) {
+		return
+	}
+
+	mw.section("FILES")
+
+	afterCmdLine := false
+
+	for _, st := range order {
+		switch st {
+		case param.GroupConfigFileSource:
+			for _, f := range gf {
+				mw.tagged(`\fI`+manEscape(f.cf.String())+`\fR`,
+					"a configuration file for the parameters in the "+
+						f.groupName+" group."+
+						manCmdLineOverride(afterCmdLine, "this file"))
+			}
+		case param.ConfigFileSource:
+			mw.writeConfigFiles(cf, cd, afterCmdLine)
+		case param.EnvFileSource:
+			for _, f := range ef {
+				mw.tagged(`\fI`+manEscape(f.String())+`\fR`,
+					"a file of environment variable settings for the"+
+						" program. These are used as if they had been set"+
+						" in the environment."+
+						manCmdLineOverride(afterCmdLine, "this file"))
+			}
+		case param.CommandLineSource:
+			afterCmdLine = true
+		case param.FinalConfigFileSource:
+			for _, f := range ff {
+				mw.tagged(`\fI`+manEscape(f.String())+`\fR`,
+					"a final configuration file for the program."+
+						manCmdLineOverride(afterCmdLine, "this file"))
+			}
+		}
+	}
+}
+
+// writeConfigFiles writes the FILES entries for the configuration files
+// and directories of the program
+func (mw manWriter) writeConfigFiles(cf []param.ConfigFileDetails,
+	cd []param.ConfigDirDetails, afterCmdLine bool,
+) {
+	for _, f := range cf {
+		desc := "a configuration file for the program."
+		if f.ParamsMustExist() {
+			desc += " Parameters given in this file must be" +
+				" valid parameters of the program."
+		}
+
+		mw.tagged(`\fI`+manEscape(f.String())+`\fR`,
+			desc+manCmdLineOverride(afterCmdLine, "this file"))
+	}
+
+	for _, d := range cd {
+		desc := "a directory of configuration files for the program." +
+			" The matching files are read in lexical order."
+		if d.ParamsMustExist() {
+			desc += " Parameters given in these files must be" +
+				" valid parameters of the program."
+		}
+
+		mw.tagged(`\fI`+manEscape(d.String())+`\fR`,
+			desc+manCmdLineOverride(afterCmdLine, "these files"))
+	}
+}
+
+// manCmdLineOverride returns a sentence saying that the values given in
+// the files replace those given on the command line if the source is
+// processed after the command line and the empty string otherwise.
+func manCmdLineOverride(afterCmdLine bool, files string) string {
+	if !afterCmdLine {
+		return ""
+	}
+
+	return " The values given in " + files +
+		" replace those given on the command line."
+}
+
+// writeExamples writes the EXAMPLES section, if there are any examples
+func (mw manWriter) writeExamples(ps *param.PSet) {
+	ex := ps.Examples()
+	if len(ex) == 0 {
+		return
+	}
+
+	mw.section("EXAMPLES")
+
+	for _, e := range ex {
+		mw.tagged(`\fB`+manEscape(e.Ex())+`\fR`, e.Desc())
+	}
+}
+
+// writeNotes writes the NOTES section, if there are any notes
+func (mw manWriter) writeNotes(ps *param.PSet) {
+	notes := ps.Notes()
+	if len(notes) == 0 {
+		return
+	}
+
+	mw.section("NOTES")
+
+	for _, h := range slices.Sorted(maps.Keys(notes)) {
+		mw.macro("SS", `"`+manEscape(h)+`"`)
+		mw.text(notes[h].Text())
+	}
+}
+
+// writeSeeAlso writes the SEE ALSO section, if there are any references
+func (mw manWriter) writeSeeAlso(ps *param.PSet) {
+	refs := ps.References()
+	if len(refs) == 0 {
+		return
+	}
+
+	mw.section(`"SEE ALSO"`)
+
+	for _, r := range refs {
+		mw.tagged(`\fB`+manEscape(r.Name())+`\fR`, r.Desc())
+	}
+}
+
+// WriteManPage writes a section 1 man page, in roff format, describing the
+// program and its parameters
+func WriteManPage(w io.Writer, ps *param.PSet) {
+	mw := manWriter{w: w}
+
+	mw.writeHeader(ps)
+	mw.writeSynopsis(ps)
+
+	if desc := ps.ProgDesc(); desc != "" {
+		mw.section("DESCRIPTION")
+		mw.text(desc)
+	}
+
+	mw.writeOptions(ps)
+	mw.writeArguments(ps)
+	mw.writeEnvironment(ps)
+	mw.writeFiles(ps)
+	mw.writeExamples(ps)
+	mw.writeNotes(ps)
+	mw.writeSeeAlso(ps)
+}
+
+// helpMan writes any messages to the error writer and then writes the man
+// page for the program to the standard writer
+func (h StdHelp) helpMan(ps *param.PSet, messages ...string) {
+	for _, message := range messages {
+		fmt.Fprintln(h.ErrW(), message)
+	}
+
+	WriteManPage(h.StdW(), ps)
+}
@@ -29,6 +29,6 @@ func (nh NoHelp) ErrorHandler(ps *param.PSet) {
 	errMap := ps.Errors()
 	if len(errMap) != 0 {
 		errMap.Report(os.Stderr, ps.ProgName())
-		ps.SetExitStatus(1)
+		ps.SetExitStatus(ExitStatusErrorsFound)
 	}
 }
@@ -170,6 +170,7 @@ func (h StdHelp) printParamUsage(p *param.ByName) {
 
 	h.twc.Wrap(p.Description(), descriptionIndent)
 	printParamAttributes(h.twc, p)
+	showParamConstraints(h.twc, p)
 
 	showSeeAlso(h.twc, &p.BaseParam)
 	showSeeNotes(h.twc, &p.BaseParam)
@@ -271,6 +272,12 @@ func paramCanBeShown(h StdHelp, p *param.ByName) bool {
 		}
 	}
 
+	for _, name := range p.NegatedNames() {
+		if h.paramsChosen[name] {
+			return true
+		}
+	}
+
 	return h.paramsChosen[p.Name()]
 }
 
@@ -327,6 +334,13 @@ func printParamAttributes(twc *twrap.TWConf, p *param.ByName) {
 			descriptionIndent)
 	}
 
+	if p.IsNegatable() {
+		twc.Wrap(
+			"\nThis parameter may be negated by giving it with a \""+
+				param.NegationPrefix+"\" prefix",
+			descriptionIndent)
+	}
+
 	if p.AttrIsSet(param.IsTerminalParam) {
 		twc.Wrap(
 			"\nNo more command-line parameters will be handled after this"+
@@ -334,6 +348,23 @@ func printParamAttributes(twc *twrap.TWConf, p *param.ByName) {
 			descriptionIndent)
 	}
 
+	if p.AttrIsSet(param.Reloadable) {
+		twc.Wrap(
+			"\nThis parameter may be given a new value while the program"+
+				" is running if the configuration files or the environment"+
+				" are changed and the parameters are reloaded.",
+			descriptionIndent)
+	}
+
+	if p.IsDeprecated() {
+		twc.Wrap(
+			"\nThis parameter is deprecated and may be removed in a"+
+				" future release. See the deprecated help section.",
+			descriptionIndent)
+	}
+
+	printParamEnvVars(twc, p)
+
 	if p.AttrIsSet(param.CommandLineOnly) && p.PSet().HasAltSources() {
 		var sourcesNotAllowed []string
 
@@ -361,6 +392,27 @@ func printParamAttributes(twc *twrap.TWConf, p *param.ByName) {
 	}
 }
 
+// printParamEnvVars prints the names of any environment variables bound to
+// the parameter
+func printParamEnvVars(twc *twrap.TWConf, p *param.ByName) {
+	ev := p.EnvVars()
+
+	switch len(ev) {
+	case 0:
+	case 1:
+		twc.Wrap(
+			"\nThis parameter may also be set through the"+
+				" environment variable: "+ev[0],
+			descriptionIndent)
+	default:
+		twc.Wrap(
+			"\nThis parameter may also be set through the"+
+				" environment variables: "+strings.Join(ev, ", ")+
+				" (the first of these which is set is used)",
+			descriptionIndent)
+	}
+}
+
 func printGroupConfigFile(twc *twrap.TWConf, g *param.Group) {
 	if len(g.ConfigFiles()) > 0 {
 		twc.Wrap(
@@ -1,6 +1,11 @@
 package phelp
 
 import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/nickwells/param.mod/v7/param"
 )
 
@@ -9,6 +14,11 @@ type groupCF struct {
 	cf        param.ConfigFileDetails
 }
 
+type paramEnvVars struct {
+	paramName string
+	envVars   []string
+}
+
 // showConfigFiles prints the config files that can be used to configure the
 // behaviour of the program
 func showConfigFiles(h StdHelp, cf []param.ConfigFileDetails) {
@@ -55,6 +65,73 @@ func showConfigFiles(h StdHelp, cf []param.ConfigFileDetails) {
 	}
 }
 
+// showConfigDirs prints the config directories that can be used to
+// configure the behaviour of the program together with the files found in
+// them
+func showConfigDirs(h StdHelp, cd []param.ConfigDirDetails) {
+	if len(cd) == 0 {
+		return
+	}
+
+	if h.showSummary {
+		for _, d := range cd {
+			if d.ParamsMustExist() {
+				h.twc.Println("config-dir::" + d.String())
+			} else {
+				h.twc.Println("multi-program-config-dir::" + d.String())
+			}
+		}
+
+		return
+	}
+
+	h.twc.Print("\n  Configuration Directories\n\n")
+
+	var hasNonStrictDirs bool
+
+	for _, d := range cd {
+		prefix := " "
+
+		if !d.ParamsMustExist() {
+			hasNonStrictDirs = true
+			prefix = "*"
+		}
+
+		h.twc.Printf("    %s %s\n", prefix, d.String())
+
+		const fileIndent = 2 * textIndent
+
+		files, err := d.Files()
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			h.twc.Wrap("the directory does not exist", fileIndent)
+		case err != nil:
+			h.twc.Wrap("cannot read the directory: "+err.Error(), fileIndent)
+		case len(files) == 0:
+			h.twc.Wrap("no matching files", fileIndent)
+		default:
+			for _, f := range files {
+				h.twc.Wrap(f, fileIndent)
+			}
+		}
+	}
+
+	h.twc.Println()
+	h.twc.WrapPrefixed("Note: ",
+		"the matching files in each directory are read in"+
+			" lexical order after the common configuration files.",
+		textIndent)
+
+	if hasNonStrictDirs {
+		h.twc.Println()
+		h.twc.WrapPrefixed("Note: ",
+			"the files in the directories marked with a '*' are allowed"+
+				" to contain parameters not valid for this program."+
+				" Any such parameters will be silently ignored.",
+			textIndent)
+	}
+}
+
 // showGroupConfigFiles prints the config files specific to particular groups
 // of parameters that can be used to configure the behaviour of the program
 func showGroupConfigFiles(h StdHelp, gf []groupCF) {
@@ -116,6 +193,88 @@ func showEnvPrefixes(h StdHelp, ep []string) {
 		textIndent)
 }
 
+// showEnvFiles prints the env files that can be used to configure the
+// behaviour of the program. The envLater flag should be set if the
+// environment is processed after the env files.
+func showEnvFiles(h StdHelp, ef []param.ConfigFileDetails, envLater bool) {
+	if len(ef) == 0 {
+		return
+	}
+
+	if h.showSummary {
+		for _, f := range ef {
+			h.twc.Println("env-file::" + f.String())
+		}
+
+		return
+	}
+
+	h.twc.Print("\n  Env Files\n\n")
+
+	for _, f := range ef {
+		h.twc.Println("    " + f.String())
+	}
+
+	note := "the settings in these files are used as if they were" +
+		" environment variables."
+	if envLater {
+		note += " Any setting in the environment itself will take precedence."
+	}
+
+	h.twc.Println()
+	h.twc.WrapPrefixed("Note: ", note, textIndent)
+}
+
+// showParamEnvVars prints the environment variables bound to particular
+// parameters that can be used to configure the behaviour of the program
+func showParamEnvVars(h StdHelp, pev []paramEnvVars) {
+	if len(pev) == 0 {
+		return
+	}
+
+	if h.showSummary {
+		for _, p := range pev {
+			for _, ev := range p.envVars {
+				h.twc.Println("env-var:" + p.paramName + ":" + ev)
+			}
+		}
+
+		return
+	}
+
+	h.twc.Print("\n  Parameter Environment Variables\n\n")
+
+	for _, p := range pev {
+		h.twc.Println("    "+p.paramName+": ", strings.Join(p.envVars, ", "))
+	}
+
+	h.twc.Println()
+	h.twc.WrapPrefixed("Note: ",
+		"where a parameter has more than one environment variable"+
+			" the first of them which is set is used."+
+			" Environment variables set to the empty string are ignored.",
+		textIndent)
+}
+
+// getParamEnvVars returns the environment variables bound to each of the
+// parameters which have any
+func getParamEnvVars(ps *param.PSet) []paramEnvVars {
+	pev := []paramEnvVars{}
+
+	for _, g := range ps.GetGroups() {
+		for _, p := range g.Params() {
+			if ev := p.EnvVars(); len(ev) > 0 {
+				pev = append(pev, paramEnvVars{
+					paramName: p.Name(),
+					envVars:   ev,
+				})
+			}
+		}
+	}
+
+	return pev
+}
+
 // getGroupConfigFiles this returns the collection of group config files
 func getGroupConfigFiles(ps *param.PSet) []groupCF {
 	gf := []groupCF{}
@@ -133,16 +292,168 @@ func getGroupConfigFiles(ps *param.PSet) []groupCF {
 	return gf
 }
 
+// showFinalConfigFiles prints the config files that are read after the
+// command line and whose settings cannot be overridden
+func showFinalConfigFiles(h StdHelp, cf []param.ConfigFileDetails) {
+	if len(cf) == 0 {
+		return
+	}
+
+	if h.showSummary {
+		for _, f := range cf {
+			h.twc.Println("final-config-file::" + f.String())
+		}
+
+		return
+	}
+
+	h.twc.Print("\n  Final Configuration Files\n\n")
+
+	for _, f := range cf {
+		h.twc.Println("    " + f.String())
+	}
+}
+
+// showProfiles prints the config file profiles that have been selected if
+// the program supports profiles and there are any config files which might
+// have profile sections
+func showProfiles(h StdHelp, ps *param.PSet, as altSources) {
+	if !ps.HasProfileParam() {
+		return
+	}
+
+	if len(as.gf) == 0 && len(as.cf) == 0 && len(as.cd) == 0 &&
+		len(as.ff) == 0 {
+		return
+	}
+
+	profiles := ps.Profiles()
+
+	if h.showSummary {
+		for _, p := range profiles {
+			h.twc.Println("profile::" + p)
+		}
+
+		return
+	}
+
+	h.twc.Print("\n  Configuration File Profiles\n\n")
+
+	if len(profiles) == 0 {
+		h.twc.Wrap("No profiles have been selected", textIndent)
+	} else {
+		h.twc.Wrap("Selected: "+strings.Join(profiles, ", "), textIndent)
+	}
+
+	h.twc.Println()
+	h.twc.WrapPrefixed("Note: ",
+		"the configuration files may be divided into profile sections."+
+			" Only the sections for the profiles selected through the '"+
+			paramNameProfile+"' parameter are used, together with the '"+
+			param.DfltProfile+"' section and any parameters before the"+
+			" first section.",
+		textIndent)
+}
+
+// altSources records the alternative sources which are enabled for the
+// parameter set
+type altSources struct {
+	gf  []groupCF
+	cf  []param.ConfigFileDetails
+	cd  []param.ConfigDirDetails
+	ef  []param.ConfigFileDetails
+	pev []paramEnvVars
+	ep  []string
+	ff  []param.ConfigFileDetails
+}
+
+// getAltSources returns the alternative sources for the parameter set. Any
+// sources which are not enabled are left empty.
+func getAltSources(ps *param.PSet) altSources {
+	var as altSources
+
+	if ps.SourceIsEnabled(param.GroupConfigFileSource) {
+		as.gf = getGroupConfigFiles(ps)
+	}
+
+	if ps.SourceIsEnabled(param.ConfigFileSource) {
+		as.cf = ps.ConfigFiles()
+		as.cd = ps.ConfigDirs()
+	}
+
+	if ps.SourceIsEnabled(param.EnvFileSource) {
+		as.ef = ps.EnvFiles()
+	}
+
+	if ps.SourceIsEnabled(param.EnvironmentSource) {
+		as.pev = getParamEnvVars(ps)
+		as.ep = ps.EnvPrefixes()
+	}
+
+	if ps.SourceIsEnabled(param.FinalConfigFileSource) {
+		as.ff = ps.FinalConfigFiles()
+	}
+
+	return as
+}
+
+// isEmpty returns true if there are no alternative sources
+func (as altSources) isEmpty() bool {
+	return len(as.gf) == 0 && len(as.cf) == 0 && len(as.cd) == 0 &&
+		len(as.ef) == 0 && len(as.pev) == 0 && len(as.ep) == 0 &&
+		len(as.ff) == 0
+}
+
+// hasSource returns true if there is anything to show for the source
+func (as altSources) hasSource(st param.SourceType) bool {
+	switch st {
+	case param.GroupConfigFileSource:
+		return len(as.gf) > 0
+	case param.ConfigFileSource:
+		return len(as.cf) > 0 || len(as.cd) > 0
+	case param.EnvFileSource:
+		return len(as.ef) > 0
+	case param.EnvironmentSource:
+		return len(as.pev) > 0 || len(as.ep) > 0
+	case param.FinalConfigFileSource:
+		return len(as.ff) > 0
+	}
+
+	return false
+}
+
+// showCommandLineSource prints a note if any of the sources following the
+// command line have anything to show. The settings in these sources will
+// override those given on the command line.
+func showCommandLineSource(h StdHelp, as altSources,
+	later []param.SourceType,
+) {
+	if !slices.ContainsFunc(later, as.hasSource) {
+		return
+	}
+
+	if h.showSummary {
+		h.twc.Println("command-line::")
+		return
+	}
+
+	h.twc.Print("\n  Command Line\n\n")
+	h.twc.WrapPrefixed("Note: ",
+		"the sources shown below are processed after the command line."+
+			" Any parameters set there will replace the values given"+
+			" on the command line.",
+		textIndent)
+}
+
 // showAltSources will print a usage message showing the alternative sources
 // that can be used to set parameters: environment variables or configuration
 // files. If there were no alternative sources it will not print saying that
-// there are no alternative sources.
+// there are no alternative sources. The sources are shown in the order in
+// which they are processed.
 func showAltSources(h StdHelp, ps *param.PSet) bool {
-	gf := getGroupConfigFiles(ps)
-	cf := ps.ConfigFiles()
-	ep := ps.EnvPrefixes()
+	as := getAltSources(ps)
 
-	if len(gf) == 0 && len(cf) == 0 && len(ep) == 0 {
+	if as.isEmpty() {
 		if h.showSummary {
 			h.twc.Wrap("none", textIndent)
 		} else {
@@ -157,13 +468,34 @@ func showAltSources(h StdHelp, ps *param.PSet) bool {
 	if !h.showSummary {
 		h.twc.Print("Alternative Sources\n\n")
 		h.twc.Wrap("Program parameters may be set through the command line"+
-			" but also through these additional sources.",
+			" but also through these additional sources."+
+			" They are shown in the order in which they are processed,"+
+			" values set by later sources replace earlier ones.",
 			0)
 	}
 
-	showGroupConfigFiles(h, gf)
-	showConfigFiles(h, cf)
-	showEnvPrefixes(h, ep)
+	order := ps.SourceOrder()
+	for i, st := range order {
+		switch st {
+		case param.GroupConfigFileSource:
+			showGroupConfigFiles(h, as.gf)
+		case param.ConfigFileSource:
+			showConfigFiles(h, as.cf)
+			showConfigDirs(h, as.cd)
+		case param.EnvFileSource:
+			showEnvFiles(h, as.ef,
+				slices.Contains(order[i+1:], param.EnvironmentSource))
+		case param.EnvironmentSource:
+			showParamEnvVars(h, as.pev)
+			showEnvPrefixes(h, as.ep)
+		case param.CommandLineSource:
+			showCommandLineSource(h, as, order[i+1:])
+		case param.FinalConfigFileSource:
+			showFinalConfigFiles(h, as.ff)
+		}
+	}
+
+	showProfiles(h, ps, as)
 
 	h.twc.Print("\n")
 
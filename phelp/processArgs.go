@@ -9,10 +9,19 @@ import (
 // where any StdHelp parameters (as added by the StdHelp AddParams method)
 // will be processed.
 func (h *StdHelp) ProcessArgs(ps *param.PSet) {
-	if zshCompHasAction(h) {
-		twc := twrap.NewTWConfOrPanic()
+	if h.completionsQuery {
+		h.showCompletionCandidates(ps)
 
-		completionErrStatus := zshCompletionHandler(h, twc, ps)
+		return
+	}
+
+	if compHasAction(h) {
+		twc := twrap.NewTWConfOrPanic(twrap.SetWriter(h.StdW()))
+
+		completionErrStatus := max(
+			zshCompletionHandler(h, twc, ps),
+			bashCompletionHandler(h, twc, ps),
+			fishCompletionHandler(h, twc, ps))
 		if completionErrStatus == 0 {
 			h.reportErrors = false
 		}
@@ -29,6 +38,10 @@ func (h *StdHelp) ProcessArgs(ps *param.PSet) {
 		h.sectionsChosen[unusedParamsHelpSectionName] = true
 	}
 
+	if h.paramsDumpConfig {
+		h.sectionsChosen[dumpConfigHelpSectionName] = true
+	}
+
 	if h.exitAfterParsing {
 		ps.ShouldExit()
 	}
@@ -14,10 +14,15 @@ import (
 // helpSection records the information about a particular section of the help
 // message. The displayFunc prints the appropriate section of the help
 // message and returns false if there was nothing displayed, true otherwise
+//
+// If the subCmdSpecific flag is set and a sub-command has been chosen then
+// the section is shown for the sub-command's parameter set rather than for
+// the top-level parameter set.
 type helpSection struct {
-	name        string
-	desc        string
-	displayFunc func(StdHelp, *param.PSet) bool
+	name           string
+	desc           string
+	displayFunc    func(StdHelp, *param.PSet) bool
+	subCmdSpecific bool
 }
 
 const (
@@ -25,14 +30,18 @@ const (
 	usageHelpSectionName         = "usage"
 	groupsHelpSectionName        = "groups"
 	posParamsHelpSectionName     = "params-pos"
+	subCmdsHelpSectionName       = "sub-commands"
 	namedParamsHelpSectionName   = "params-named"
 	groupedParamsHelpSectionName = "params-grouped"
+	constraintsHelpSectionName   = "constraints"
+	deprecatedHelpSectionName    = "deprecated"
 	notesHelpSectionName         = "notes"
 	sourcesHelpSectionName       = "sources"
 	examplesHelpSectionName      = "examples"
 	refsHelpSectionName          = "refs"
 	whereSetHelpSectionName      = "where-set"
 	unusedParamsHelpSectionName  = "unused-params"
+	dumpConfigHelpSectionName    = "dump-config"
 )
 
 var helpSectionsInOrder = []helpSection{
@@ -40,34 +49,61 @@ var helpSectionsInOrder = []helpSection{
 		name: introHelpSectionName,
 		desc: "the program name and" +
 			" optionally the program description",
-		displayFunc: showIntro,
+		displayFunc:    showIntro,
+		subCmdSpecific: true,
 	},
 	{
 		name: usageHelpSectionName,
 		desc: "the program name, a parameter summary," +
 			" and any trailing parameters",
-		displayFunc: showUsageSummary,
+		displayFunc:    showUsageSummary,
+		subCmdSpecific: true,
 	},
 	{
 		name: posParamsHelpSectionName,
 		desc: "the positional parameters coming just after the" +
 			" program name",
-		displayFunc: showByPosParams,
+		displayFunc:    showByPosParams,
+		subCmdSpecific: true,
+	},
+	{
+		name: subCmdsHelpSectionName,
+		desc: "the sub-commands which select the mode of" +
+			" operation of the program",
+		displayFunc:    showSubCommands,
+		subCmdSpecific: true,
+	},
+	{
+		name:           groupsHelpSectionName,
+		desc:           "the parameter groups",
+		displayFunc:    showGroups,
+		subCmdSpecific: true,
 	},
 	{
-		name:        groupsHelpSectionName,
-		desc:        "the parameter groups",
-		displayFunc: showGroups,
+		name:           namedParamsHelpSectionName,
+		desc:           "the named parameters (flags)",
+		displayFunc:    showParamsByName,
+		subCmdSpecific: true,
 	},
 	{
-		name:        namedParamsHelpSectionName,
-		desc:        "the named parameters (flags)",
-		displayFunc: showParamsByName,
+		name:           groupedParamsHelpSectionName,
+		desc:           "the named parameters by group name",
+		displayFunc:    showParamsByGroupName,
+		subCmdSpecific: true,
 	},
 	{
-		name:        groupedParamsHelpSectionName,
-		desc:        "the named parameters by group name",
-		displayFunc: showParamsByGroupName,
+		name: constraintsHelpSectionName,
+		desc: "the constraints on the combinations of parameters" +
+			" that may be given",
+		displayFunc:    showConstraints,
+		subCmdSpecific: true,
+	},
+	{
+		name: deprecatedHelpSectionName,
+		desc: "the deprecated parameter names and the" +
+			" parameters to use instead",
+		displayFunc:    showDeprecated,
+		subCmdSpecific: true,
 	},
 	{
 		name:        notesHelpSectionName,
@@ -86,13 +122,15 @@ var helpSectionsInOrder = []helpSection{
 		desc: "examples of correct program use" +
 			" and suggestions of ways to use the" +
 			" program",
-		displayFunc: showExamples,
+		displayFunc:    showExamples,
+		subCmdSpecific: true,
 	},
 	{
 		name: refsHelpSectionName,
 		desc: "references to other programs or" +
 			" further sources of information",
-		displayFunc: showReferences,
+		displayFunc:    showReferences,
+		subCmdSpecific: true,
 	},
 	{
 		name:        whereSetHelpSectionName,
@@ -104,6 +142,11 @@ var helpSectionsInOrder = []helpSection{
 		desc:        "report any unused parameters",
 		displayFunc: showUnusedParams,
 	},
+	{
+		name:        dumpConfigHelpSectionName,
+		desc:        "show the parameter values as a config file",
+		displayFunc: showConfig,
+	},
 }
 
 // makeSectionAllowedVals constructs an AllowedVals map from the
@@ -137,6 +180,7 @@ const (
 	seeAlsoHelpSectionAlias = "see-also"
 
 	posParamsHelpSectionAlias     = "pos-params"
+	subCmdsHelpSectionAlias       = "subcmds"
 	namedParamsHelpSectionAlias   = "named-params"
 	groupedParamsHelpSectionAlias = "grouped-params"
 )
@@ -147,12 +191,14 @@ var sectionAliases = ptypes.Aliases[string]{
 	},
 	standardHelpSectionAlias: []string{
 		introHelpSectionName, usageHelpSectionName,
-		posParamsHelpSectionName, groupedParamsHelpSectionName,
+		posParamsHelpSectionName, subCmdsHelpSectionName,
+		groupedParamsHelpSectionName,
 	},
 	allHelpSectionAlias: []string{
 		introHelpSectionName, usageHelpSectionName,
-		posParamsHelpSectionName, groupedParamsHelpSectionName,
-		notesHelpSectionName, sourcesHelpSectionName,
+		posParamsHelpSectionName, subCmdsHelpSectionName,
+		groupedParamsHelpSectionName, constraintsHelpSectionName,
+		deprecatedHelpSectionName, notesHelpSectionName, sourcesHelpSectionName,
 		examplesHelpSectionName, refsHelpSectionName,
 	},
 
@@ -166,6 +212,7 @@ var sectionAliases = ptypes.Aliases[string]{
 	seeAlsoHelpSectionAlias: []string{refsHelpSectionName},
 
 	posParamsHelpSectionAlias:     []string{posParamsHelpSectionName},
+	subCmdsHelpSectionAlias:       []string{subCmdsHelpSectionName},
 	namedParamsHelpSectionAlias:   []string{namedParamsHelpSectionName},
 	groupedParamsHelpSectionAlias: []string{groupedParamsHelpSectionName},
 }
@@ -218,19 +265,29 @@ type StdHelp struct {
 	avalShownAlready ptypes.AValCache
 
 	// params-... values
-	paramsShowWhereSet bool
-	paramsSetFormat    string
-	paramsShowUnused   bool
-	reportErrors       bool
-	exitOnErrors       bool
-	exitAfterParsing   bool
+	paramsShowWhereSet  bool
+	paramsSetFormat     string
+	paramsShowUnused    bool
+	paramsDumpConfig    bool
+	paramsDumpConfigAll bool
+	paramsProfiles      []string
+	reportErrors        bool
+	exitOnErrors        bool
+	exitStatusFunc      ExitStatusFunc
+	exitAfterParsing    bool
 
 	exitAfterHelp bool // this can only be set in test code
 
 	// completions-... values
-	completionsQuiet bool
-	zshCompDir       string
-	zshCompAction    string
+	completionsQuiet   bool
+	completionsQuery   bool
+	completionsDynamic bool
+	zshCompDir         string
+	zshCompAction      string
+	bashCompDir        string
+	bashCompAction     string
+	fishCompDir        string
+	fishCompAction     string
 
 	twc *twrap.TWConf
 }
@@ -268,6 +325,21 @@ func SetErrWriter(w io.Writer) StdHelpOptFunc {
 	}
 }
 
+// SetExitStatusFunc returns a StdHelpOptFunc that will set the function
+// used to choose the exit status when errors are found. The default is
+// DfltExitStatus.
+func SetExitStatusFunc(f ExitStatusFunc) StdHelpOptFunc {
+	return func(h *StdHelp) error {
+		if f == nil {
+			return fmt.Errorf("phelp.SetExitStatusFunc cannot take a nil value")
+		}
+
+		h.exitStatusFunc = f
+
+		return nil
+	}
+}
+
 // NewStdHelp returns a pointer to a well-constructed instance of the
 // standard help type ready to be used as the helper for a new param.PSet
 // (the standard paramset.New() function will use this)
@@ -286,7 +358,11 @@ func NewStdHelp(hof ...StdHelpOptFunc) *StdHelp {
 		exitOnErrors:  true,
 		exitAfterHelp: true,
 
-		zshCompAction: zshCompActionNone,
+		exitStatusFunc: DfltExitStatus,
+
+		zshCompAction:  compActionNone,
+		bashCompAction: compActionNone,
+		fishCompAction: compActionNone,
 
 		helpLineLen: twrap.DfltTargetLineLen,
 		helpFormat:  helpFmtTypeStd,
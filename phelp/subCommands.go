@@ -0,0 +1,40 @@
+package phelp
+
+import (
+	"github.com/nickwells/param.mod/v7/param"
+)
+
+// showSubCommands prints the sub-commands and their descriptions
+func showSubCommands(h StdHelp, ps *param.PSet) bool {
+	scs := ps.SubCommands()
+	if len(scs) == 0 {
+		return false
+	}
+
+	h.twc.Print("Sub-Commands\n\n")
+
+	for _, sc := range scs {
+		h.twc.Wrap(sc.Name(), paramIndent)
+
+		if h.showSummary {
+			continue
+		}
+
+		h.twc.Wrap(sc.Desc(), descriptionIndent)
+	}
+
+	if h.showSummary {
+		return true
+	}
+
+	h.twc.Println()
+	h.twc.WrapPrefixed("Note: ",
+		"for help on a particular sub-command give the"+
+			" sub-command name followed by "+
+			makeParamStr(ps, helpArgName, "")+"."+
+			" Any of the parameters of "+ps.ProgName()+
+			" may also be given after the sub-command name.",
+		textIndent)
+
+	return true
+}
@@ -42,6 +42,10 @@ func showUsageSummary(h StdHelp, ps *param.PSet) bool {
 			h.twc.Print(" ...")
 		}
 
+		if ps.HasSubCommands() {
+			h.twc.Print(" <sub-command> ...")
+		}
+
 		if ps.TrailingParamsExpected() {
 			h.twc.Print(" " + ps.TerminalParam())
 		}
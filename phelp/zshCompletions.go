@@ -3,33 +3,20 @@ package phelp
 import (
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"unicode"
 
-	"github.com/nickwells/filecheck.mod/filecheck"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/ptypes"
 	"github.com/nickwells/twrap.mod/twrap"
 )
 
-const (
-	zshCompActionNone = "none"
-	zshCompActionRepl = "replace"
-	zshCompActionNew  = "new"
-	zshCompActionShow = "show"
-)
-
-const (
-	completionFilePerms = 0o555 // r-x r-x r-x
-)
-
 // zshCompHasAction returns true if the StdHelp zsh Completion Action is not
 // None, false otherwise.
 func zshCompHasAction(h *StdHelp) bool {
-	return h.zshCompAction != zshCompActionNone
+	return h.zshCompAction != compActionNone
 }
 
 // zshSafeStr returns an edited version of the string with any characters which
@@ -91,8 +78,8 @@ func zshNameSuffix(p *param.ByName) string {
 
 // zshMsgAction creates the message and action parts of the option spec
 // depending on whether or not the parameter must or can take a following
-// argument. The action part is specialised according to the type of the
-// setter. The setter name is used as the message part.
+// argument. The action part is specialised according to the completion
+// spec of the setter. The setter name is used as the message part.
 func zshMsgAction(p *param.ByName) string {
 	valueReq := p.Setter().ValueReq()
 	if valueReq == param.None {
@@ -104,45 +91,27 @@ func zshMsgAction(p *param.ByName) string {
 		msgAction += ":"
 	}
 
-	sType := fmt.Sprintf("%T", p.Setter())
-	msgAction += sType + ":"
+	msgAction += fmt.Sprintf("%T", p.Setter()) + ":"
 
-	switch sType {
-	case "psetter.Bool":
-		msgAction += "(true false)"
-	case "psetter.Pathname":
-		msgAction += "_files"
-	default:
-		msgAction += zshMsgActionGetAllowedVals(p)
-	}
-
-	return msgAction
+	return msgAction + zshAction(compSpec(p.Setter()))
 }
 
-// zshMsgActionGetAllowedVals constructs a msgAction string from any allowed
-// values
-func zshMsgActionGetAllowedVals(p *param.ByName) string {
-	var avals []string
-
-	if getter, ok := p.Setter().(ptypes.AllowedValuesMapper); ok {
-		m := getter.AllowedValuesMap()
-		if m != nil {
-			keys, _ := m.Keys()
-			avals = append(avals, keys...)
+// zshAction returns the zsh action offering the completions given by the
+// spec
+func zshAction(spec ptypes.CompletionSpec) string {
+	switch spec.Kind {
+	case ptypes.CompleteWords:
+		return "(" + strings.Join(spec.Words, " ") + ")"
+	case ptypes.CompleteFiles:
+		if spec.Glob != "" {
+			return "_files -g '" + spec.Glob + "'"
 		}
-	}
-
-	if getter, ok := p.Setter().(ptypes.AllowedValuesAliasMapper); ok {
-		m := getter.AllowedValuesAliasMap()
-		if m != nil {
-			keys, _ := m.Keys()
-			avals = append(avals, keys...)
-		}
-	}
 
-	if len(avals) > 0 {
-		sort.Strings(avals)
-		return "(" + strings.Join(avals, " ") + ")"
+		return "_files"
+	case ptypes.CompleteDirs:
+		return "_files -/"
+	case ptypes.CompleteHostnames:
+		return "_hosts"
 	}
 
 	return ""
@@ -156,8 +125,15 @@ func zshOptSpec(p *param.ByName) []string {
 	explanation := "[" + zshSafeStr(p.Description()) + "]"
 
 	names := p.AltNames()
+	if sn := p.ShortName(); sn != 0 {
+		names = append(names, string(sn))
+	}
+
+	negNames := p.NegatedNames()
+	allNames := append(slices.Clone(names), negNames...)
+
 	for _, name := range names {
-		altNames := zshMakeAltNames(name, names)
+		altNames := zshMakeAltNames(name, allNames)
 		specs = append(specs,
 			`"`+
 				altNames+
@@ -168,6 +144,16 @@ func zshOptSpec(p *param.ByName) []string {
 				`"`)
 	}
 
+	for _, name := range negNames {
+		altNames := zshMakeAltNames(name, allNames)
+		specs = append(specs,
+			`"`+
+				altNames+
+				"-"+name+
+				explanation+
+				`"`)
+	}
+
 	return specs
 }
 
@@ -194,48 +180,36 @@ func zshWriteCompFunc(ps *param.PSet, w io.Writer) {
 // setting of the StdHelp zshCompletionAction member. It returns a suggested
 // exit status.
 func zshCompletionHandler(h *StdHelp, twc *twrap.TWConf, ps *param.PSet) int {
+	cfw := h.chooseCompFuncWriter(zshWriteCompFunc, zshWriteDynamicCompFunc)
+
 	switch h.zshCompAction {
-	case zshCompActionNone:
+	case compActionNone:
 		return 0
-	case zshCompActionShow:
-		zshWriteCompFunc(ps, twc.W)
+	case compActionShow:
+		cfw(ps, twc.W)
 		return 0
-	case zshCompActionNew:
+	case compActionNew:
 		filename := zshCompFileName(h, ps)
 
-		err := zshMakeNewCompFile(filename, ps)
+		err := makeNewCompFile(filename, ps, cfw)
 		if err == nil {
 			zshCompFileNotify(h, twc, filename)
 		}
 
-		return zshHandleErr(err, ps)
-	case zshCompActionRepl:
+		return compHandleErr(err, ps, "zsh")
+	case compActionRepl:
 		filename := zshCompFileName(h, ps)
 
-		err := zshReplaceCompFile(filename, ps)
+		err := replaceCompFile(filename, ps, cfw)
 		if err == nil {
 			zshCompFileNotify(h, twc, filename)
 		}
 
-		return zshHandleErr(err, ps)
+		return compHandleErr(err, ps, "zsh")
 	}
 
-	return zshHandleErr(
-		fmt.Errorf("unknown zsh completion action: %q", h.zshCompAction),
-		ps)
-}
-
-// zshHandleErr will test the error, if it is non-nil it will add the error
-// to the param.PSet and return a suggested exit status of 1. Otherwise it
-// returns 0
-func zshHandleErr(err error, ps *param.PSet) int {
-	if err == nil {
-		return 0
-	}
-
-	ps.AddErr("zsh completions", err)
-
-	return 1
+	return compHandleErr(compUnknownActionErr("zsh", h.zshCompAction), ps,
+		"zsh")
 }
 
 // zshCompFileName returns the name of the completions file
@@ -243,53 +217,6 @@ func zshCompFileName(h *StdHelp, ps *param.PSet) string {
 	return filepath.Join(h.zshCompDir, "_"+ps.ProgBaseName())
 }
 
-// zshMakeNewCompFile will construct the named file (which must not already
-// exist). It will return any errors found.
-func zshMakeNewCompFile(filename string, ps *param.PSet) error {
-	err := filecheck.IsNew().StatusCheck(filename)
-	if err != nil {
-		return err
-	}
-
-	w, err := os.OpenFile( //nolint:gosec
-		filename,
-		os.O_WRONLY|os.O_CREATE,
-		completionFilePerms)
-	if err != nil {
-		return err
-	}
-
-	defer w.Close()
-
-	zshWriteCompFunc(ps, w)
-
-	return nil
-}
-
-// zshReplaceCompFile will construct the named file (which may already
-// exist). It will return any errors found.
-func zshReplaceCompFile(filename string, ps *param.PSet) error {
-	const userWritePerm = 0o200
-
-	_ = os.Chmod(filename, completionFilePerms|userWritePerm)
-
-	w, err := os.OpenFile( //nolint:gosec
-		filename,
-		os.O_WRONLY|os.O_TRUNC|os.O_CREATE,
-		completionFilePerms)
-	if err != nil {
-		return err
-	}
-
-	_ = os.Chmod(filename, completionFilePerms)
-
-	defer w.Close()
-
-	zshWriteCompFunc(ps, w)
-
-	return nil
-}
-
 // zshCompFileNotify writes a notification message informing the user that
 // the completion file has been successfully created.
 func zshCompFileNotify(h *StdHelp, twc *twrap.TWConf, filename string) {
@@ -304,3 +231,18 @@ func zshCompFileNotify(h *StdHelp, twc *twrap.TWConf, filename string) {
 			" Please see the zsh manual for more details.",
 		0)
 }
+
+// zshWriteDynamicCompFunc writes a zsh completion function for the current
+// executable which calls the program to find the completions
+func zshWriteDynamicCompFunc(ps *param.PSet, w io.Writer) {
+	fmt.Fprintf(w, "#compdef %s\n\n", ps.ProgBaseName())
+	fmt.Fprintf(w, "function _%s {\n", ps.ProgBaseName())
+	fmt.Fprintln(w, "\tlocal -a cands")
+	fmt.Fprintf(w,
+		"\tcands=(\"${(@f)$(${words[1]} %s \"${(@)words[2,CURRENT]}\""+
+			" 2>/dev/null)}\")\n",
+		compQueryCmd(ps))
+	fmt.Fprintln(w, "\tcands=(${cands:#})")
+	fmt.Fprintln(w, "\tcompadd -a cands")
+	fmt.Fprintln(w, "}")
+}
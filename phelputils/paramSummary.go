@@ -41,6 +41,17 @@ func valueNeededStr(p param.ByName) string {
 	}
 }
 
+// negatedNameStr returns the negated form of the name, preceded by the
+// separator, if the parameter is negatable and the empty string
+// otherwise. No value is shown as a negated parameter cannot take one.
+func negatedNameStr(p param.ByName, name, sep string) string {
+	if p.IsNegatable() {
+		return sep + p.PSet().ShortestPrefix() + param.NegationPrefix + name
+	}
+
+	return ""
+}
+
 // optionalWrapper wraps the string in "[" and "]" if the parameter is optional.
 func optionalWrapper(s string, p param.ByName) string {
 	if p.AttrIsSet(param.MustBeSet) {
@@ -51,13 +62,24 @@ func optionalWrapper(s string, p param.ByName) string {
 }
 
 // ParamSummary returns a string summarising the usage of the parameter. The
-// parameter name and all it's alternatives will be given and an indication
-// of whether a following value is required. The value returned will be
-// bracketted by '[' and ']' if it is not mandatory.
+// parameter's short name (if any), its name and all it's alternatives will
+// be given and an indication of whether a following value is required. If
+// the parameter is negatable each name is followed by its negated form,
+// with the "no-" prefix and without any value. The value returned will be bracketted by '[' and ']' if it is not
+// mandatory.
 func ParamSummary(p param.ByName) string {
 	var s strings.Builder
 
 	sep := ""
+
+	if sn := p.ShortName(); sn != 0 {
+		s.WriteString(param.ShortNamePrefix)
+		s.WriteRune(sn)
+		s.WriteString(valueNeededStr(p))
+
+		sep = ", "
+	}
+
 	for _, altName := range p.AltNames() {
 		s.WriteString(sep)
 		sep = ", "
@@ -65,6 +87,7 @@ func ParamSummary(p param.ByName) string {
 		s.WriteString(p.PSet().ShortestPrefix())
 		s.WriteString(altName)
 		s.WriteString(valueNeededStr(p))
+		s.WriteString(negatedNameStr(p, altName, sep))
 	}
 
 	return optionalWrapper(s.String(), p)
@@ -72,7 +95,8 @@ func ParamSummary(p param.ByName) string {
 
 // ParamShortSummary returns a string summarising the usage of the
 // parameter. Only the parameter name is shown (none of the alternatives will
-// be given) and an indication of whether a following value is required. The
+// be given) and an indication of whether a following value is required. If
+// the parameter is negatable the name is followed by its negated form. The
 // value returned will be bracketted by '[' and ']' if it is not mandatory.
 func ParamShortSummary(p param.ByName) string {
 	var s strings.Builder
@@ -80,6 +104,7 @@ func ParamShortSummary(p param.ByName) string {
 	s.WriteString(p.PSet().ShortestPrefix())
 	s.WriteString(p.Name())
 	s.WriteString(valueNeededStr(p))
+	s.WriteString(negatedNameStr(p, p.Name(), " | "))
 
 	return optionalWrapper(s.String(), p)
 }
@@ -31,6 +31,8 @@ func init() {
 func TestAllowedValues(t *testing.T) {
 	var b bool
 
+	var count int
+
 	var dur time.Duration
 
 	var emptyStrList []string
@@ -62,6 +64,10 @@ func TestAllowedValues(t *testing.T) {
 			ID: testhelper.MkID("Bool"),
 			s:  &psetter.Bool{Value: &b},
 		},
+		{
+			ID: testhelper.MkID("Counter"),
+			s:  &psetter.Counter[int]{Value: &count},
+		},
 		{
 			ID: testhelper.MkID("Duration"),
 			s:  &psetter.Duration{Value: &dur},
@@ -0,0 +1,602 @@
+package psetter
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"golang.org/x/exp/constraints"
+)
+
+// AtomicVal holds a value which can be safely read and replaced by
+// different goroutines at the same time. The zero value holds the zero
+// value of the type.
+//
+// The value is not copied when it is stored or loaded so if it is a slice
+// or a map (or contains one) its contents must not be changed once it has
+// been stored.
+type AtomicVal[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewAtomicVal returns a new AtomicVal holding the value
+func NewAtomicVal[T any](v T) *AtomicVal[T] {
+	av := &AtomicVal[T]{}
+	av.Store(v)
+
+	return av
+}
+
+// Load returns the value
+func (av *AtomicVal[T]) Load() T {
+	if p := av.p.Load(); p != nil {
+		return *p
+	}
+
+	var zero T
+
+	return zero
+}
+
+// Store replaces the value
+func (av *AtomicVal[T]) Store(v T) {
+	av.p.Store(&v)
+}
+
+// Atomic wraps an existing setter so that the value it sets can be safely
+// read while the parameter is being set, for instance when the parameters
+// are reloaded (see param.Reloadable). The wrapped setter is given a copy of
+// the current value to set and, only if it is set successfully, the
+// AtomicVal is updated with the new value.
+//
+// The wrapped setter must replace the value it is given rather than change
+// it in place so setters of maps cannot be wrapped.
+//
+// The optional interfaces of the wrapped setter are passed on: if it is a
+// ptypes.Negator the parameter can be made negatable (see param.Negatable)
+// and any completions it offers (see ptypes.Completer and
+// ptypes.ValueCompleter) are offered for the parameter.
+type Atomic[T any] struct {
+	// You must set a Value, the program will panic if not. This holds the
+	// value that the setter is setting.
+	Value *AtomicVal[T]
+	// You must set a Setter, the program will panic if not. This should
+	// return the setter being wrapped, setting the variable it is passed.
+	Setter func(v *T) param.Setter
+}
+
+// wrapped returns the wrapped setter and the variable it sets, initialised
+// to the current value
+func (s Atomic[T]) wrapped() (param.Setter, *T) {
+	v := s.Value.Load()
+	return s.Setter(&v), &v
+}
+
+// Set (called when there is no following value) calls the Set method of
+// the wrapped setter and, if there is no error, stores the new value.
+func (s Atomic[T]) Set(paramName string) error {
+	ws, v := s.wrapped()
+	if err := ws.Set(paramName); err != nil {
+		return err
+	}
+
+	s.Value.Store(*v)
+
+	return nil
+}
+
+// SetWithVal (called when a value follows the parameter) calls the
+// SetWithVal method of the wrapped setter and, if there is no error, stores
+// the new value.
+func (s Atomic[T]) SetWithVal(paramName, paramVal string) error {
+	ws, v := s.wrapped()
+	if err := ws.SetWithVal(paramName, paramVal); err != nil {
+		return err
+	}
+
+	s.Value.Store(*v)
+
+	return nil
+}
+
+// ValueReq returns the ValueReq of the wrapped setter
+func (s Atomic[T]) ValueReq() param.ValueReq {
+	ws, _ := s.wrapped()
+	return ws.ValueReq()
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Atomic[T]) AllowedValues() string {
+	ws, _ := s.wrapped()
+	return ws.AllowedValues()
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Atomic[T]) CurrentValue() string {
+	ws, _ := s.wrapped()
+	return ws.CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value or the Setter is nil or if the wrapped setter is not properly
+// created.
+func (s Atomic[T]) CheckSetter(name string) {
+	if s.Value == nil {
+		panic(NilValueMessage(name, fmt.Sprintf("%T", s)))
+	}
+
+	if s.Setter == nil {
+		panic(BadSetterMessage(name, fmt.Sprintf("%T", s),
+			"the Setter func is nil"))
+	}
+
+	ws, _ := s.wrapped()
+	if ws == nil {
+		panic(BadSetterMessage(name, fmt.Sprintf("%T", s),
+			"the Setter func returned a nil setter"))
+	}
+
+	ws.CheckSetter(name)
+}
+
+// ValDescribe returns a name describing the values allowed. This is taken
+// from the wrapped setter if it provides one, otherwise the type name of the
+// wrapped setter is used.
+func (s Atomic[T]) ValDescribe() string {
+	ws, _ := s.wrapped()
+	if vd, ok := ws.(ptypes.ValDescriber); ok {
+		return vd.ValDescribe()
+	}
+
+	valType := fmt.Sprintf("%T", ws)
+
+	parts := strings.Split(valType, ".")
+	valType = parts[len(parts)-1]
+	valType = strings.TrimRight(valType, "0123456789")
+
+	return valType
+}
+
+// CountChecks returns the number of check functions the wrapped setter has
+func (s Atomic[T]) CountChecks() int {
+	ws, _ := s.wrapped()
+	if cc, ok := ws.(CheckCounter); ok {
+		return cc.CountChecks()
+	}
+
+	return 0
+}
+
+// SavedValue returns the current value. This and RestoreValue allow the
+// parameter to be Reloadable.
+func (s Atomic[T]) SavedValue() any {
+	return s.Value.Load()
+}
+
+// RestoreValue stores the value which should have been returned by
+// SavedValue
+func (s Atomic[T]) RestoreValue(v any) {
+	s.Value.Store(v.(T))
+}
+
+// SetNegated (called when the parameter is given with the negation prefix)
+// calls the SetNegated method of the wrapped setter and, if there is no
+// error, stores the new value. It returns an error if the wrapped setter is
+// not a ptypes.Negator.
+func (s Atomic[T]) SetNegated(paramName string) error {
+	ws, v := s.wrapped()
+
+	n, ok := ws.(ptypes.Negator)
+	if !ok {
+		return fmt.Errorf("the wrapped setter (%T) cannot be negated", ws)
+	}
+
+	if err := n.SetNegated(paramName); err != nil {
+		return err
+	}
+
+	s.Value.Store(*v)
+
+	return nil
+}
+
+// CanBeNegated returns true if the wrapped setter is a ptypes.Negator. This
+// and SetNegated allow the parameter to be made negatable.
+func (s Atomic[T]) CanBeNegated() bool {
+	ws, _ := s.wrapped()
+	_, ok := ws.(ptypes.Negator)
+
+	return ok
+}
+
+// CompletionHints returns the completion hints of the wrapped setter if it
+// is a ptypes.Completer, otherwise any allowed values and aliases it has
+// are offered.
+func (s Atomic[T]) CompletionHints() ptypes.CompletionSpec {
+	ws, _ := s.wrapped()
+	if c, ok := ws.(ptypes.Completer); ok {
+		return c.CompletionHints()
+	}
+
+	var (
+		av      ptypes.AllowedVals[string]
+		aliases ptypes.Aliases[string]
+	)
+
+	if avm, ok := ws.(ptypes.AllowedValuesMapper); ok {
+		av = avm.AllowedValuesMap()
+	}
+
+	if aam, ok := ws.(ptypes.AllowedValuesAliasMapper); ok {
+		aliases = aam.AllowedValuesAliasMap()
+	}
+
+	return allowedValsCompletionSpec(av, aliases)
+}
+
+// CompleteValue returns the values offered by the wrapped setter if it is a
+// ptypes.ValueCompleter, otherwise the values from the CompletionHints
+// which start with the prefix.
+func (s Atomic[T]) CompleteValue(prefix string) []string {
+	ws, _ := s.wrapped()
+	if vc, ok := ws.(ptypes.ValueCompleter); ok {
+		return vc.CompleteValue(prefix)
+	}
+
+	return s.CompletionHints().Candidates(prefix)
+}
+
+// atomicWrapper describes how a typed atomic setter makes the setter it
+// wraps
+type atomicWrapper[T any] interface {
+	// wrap returns the setter to be wrapped, setting the variable and
+	// applying the checks
+	wrap(v *T, checks []check.ValCk[T]) param.Setter
+	// name returns the name of the typed atomic setter
+	name() string
+}
+
+// atomicTyped holds the fields and methods shared by the typed atomic
+// setters (AtomicInt, AtomicString and AtomicDuration) which are defined
+// with the same fields so that they can be converted to it. The W type
+// parameter makes the setter being wrapped.
+type atomicTyped[T any, W atomicWrapper[T]] struct {
+	ValueReqMandatory
+
+	// You must set a Value, the program will panic if not. This holds the
+	// value that the setter is setting.
+	Value *AtomicVal[T]
+	// The Checks, if any, are applied to the new value and the Value will
+	// only be updated if they all return a nil error.
+	Checks []check.ValCk[T]
+	// The Editor, if present, is applied to the parameter value before it
+	// is interpreted and allows the programmer to modify the value supplied
+	// before using it to set the Value.
+	Editor Editor
+}
+
+// atomic returns the equivalent Atomic setter
+func (s atomicTyped[T, W]) atomic() Atomic[T] {
+	var w W
+
+	return Atomic[T]{
+		Value: s.Value,
+		Setter: func(v *T) param.Setter {
+			return w.wrap(v, s.Checks)
+		},
+	}
+}
+
+// CountChecks returns the number of check functions this setter has
+func (s atomicTyped[T, W]) CountChecks() int {
+	return len(s.Checks)
+}
+
+// SetWithVal (called when a value follows the parameter) applies any
+// Editor to the value and then behaves as for the wrapped setter but
+// stores the value atomically
+func (s atomicTyped[T, W]) SetWithVal(paramName, paramVal string) error {
+	if s.Editor != nil {
+		var err error
+
+		paramVal, err = s.Editor.Edit(paramName, paramVal)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.atomic().SetWithVal(paramName, paramVal)
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s atomicTyped[T, W]) AllowedValues() string {
+	return s.atomic().AllowedValues()
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s atomicTyped[T, W]) CurrentValue() string {
+	return s.atomic().CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or if it has nil Checks.
+func (s atomicTyped[T, W]) CheckSetter(name string) {
+	if s.Value == nil {
+		var w W
+
+		panic(NilValueMessage(name, w.name()))
+	}
+
+	s.atomic().CheckSetter(name)
+}
+
+// ValDescribe returns a name describing the values allowed
+func (s atomicTyped[T, W]) ValDescribe() string {
+	return s.atomic().ValDescribe()
+}
+
+// SavedValue returns the current value
+func (s atomicTyped[T, W]) SavedValue() any {
+	return s.atomic().SavedValue()
+}
+
+// RestoreValue stores the value returned by SavedValue
+func (s atomicTyped[T, W]) RestoreValue(v any) {
+	s.atomic().RestoreValue(v)
+}
+
+// atomicInt makes the Int setter wrapped by an AtomicInt
+type atomicInt[T constraints.Signed] struct{}
+
+func (atomicInt[T]) wrap(v *T, checks []check.ValCk[T]) param.Setter {
+	return Int[T]{Value: v, Checks: checks}
+}
+
+func (atomicInt[T]) name() string {
+	var v T
+	return fmt.Sprintf("psetter.AtomicInt[%T]", v)
+}
+
+// AtomicInt allows you to give a parameter that can be used to set a signed
+// integer value which can be safely read while the parameter is being set.
+// See Int and Atomic.
+//
+// You must set the Value, the program will panic if not. The Checks, if
+// any, are applied to the supplied parameter value and the Value will only
+// be updated if they all return a nil error. The Editor, if present, is
+// applied to the parameter value before it is interpreted.
+type AtomicInt[T constraints.Signed] struct {
+	ValueReqMandatory
+
+	// You must set a Value, the program will panic if not. This holds the
+	// value that the setter is setting.
+	Value *AtomicVal[T]
+	// The Checks, if any, are applied to the new value and the Value will
+	// only be updated if they all return a nil error.
+	Checks []check.ValCk[T]
+	// The Editor, if present, is applied to the parameter value before it
+	// is interpreted and allows the programmer to modify the value supplied
+	// before using it to set the Value.
+	Editor Editor
+}
+
+// typed returns the equivalent atomicTyped value
+func (s AtomicInt[T]) typed() atomicTyped[T, atomicInt[T]] {
+	return atomicTyped[T, atomicInt[T]](s)
+}
+
+// CountChecks returns the number of check functions this setter has
+func (s AtomicInt[T]) CountChecks() int {
+	return s.typed().CountChecks()
+}
+
+// SetWithVal (called when a value follows the parameter) applies any
+// Editor to the value and then behaves as for the wrapped setter but
+// stores the value atomically
+func (s AtomicInt[T]) SetWithVal(paramName, paramVal string) error {
+	return s.typed().SetWithVal(paramName, paramVal)
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s AtomicInt[T]) AllowedValues() string {
+	return s.typed().AllowedValues()
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s AtomicInt[T]) CurrentValue() string {
+	return s.typed().CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or if it has nil Checks.
+func (s AtomicInt[T]) CheckSetter(name string) {
+	s.typed().CheckSetter(name)
+}
+
+// ValDescribe returns a name describing the values allowed
+func (s AtomicInt[T]) ValDescribe() string {
+	return s.typed().ValDescribe()
+}
+
+// SavedValue returns the current value. This and RestoreValue allow the
+// parameter to be Reloadable.
+func (s AtomicInt[T]) SavedValue() any {
+	return s.typed().SavedValue()
+}
+
+// RestoreValue stores the value returned by SavedValue
+func (s AtomicInt[T]) RestoreValue(v any) {
+	s.typed().RestoreValue(v)
+}
+
+// atomicString makes the String setter wrapped by an AtomicString
+type atomicString[T ~string] struct{}
+
+func (atomicString[T]) wrap(v *T, checks []check.ValCk[T]) param.Setter {
+	return String[T]{Value: v, Checks: checks}
+}
+
+func (atomicString[T]) name() string {
+	var v T
+	return fmt.Sprintf("psetter.AtomicString[%T]", v)
+}
+
+// AtomicString allows you to give a parameter that can be used to set a
+// string value which can be safely read while the parameter is being set.
+// See String and Atomic.
+//
+// You must set the Value, the program will panic if not. The Editor, if
+// present, is applied to the parameter value and then the Checks, if any,
+// are applied to the result; the Value will only be updated if they all
+// return a nil error.
+type AtomicString[T ~string] struct {
+	ValueReqMandatory
+
+	// You must set a Value, the program will panic if not. This holds the
+	// value that the setter is setting.
+	Value *AtomicVal[T]
+	// The Checks, if any, are applied to the new value and the Value will
+	// only be updated if they all return a nil error.
+	Checks []check.ValCk[T]
+	// The Editor, if present, is applied to the parameter value before it
+	// is interpreted and allows the programmer to modify the value supplied
+	// before using it to set the Value.
+	Editor Editor
+}
+
+// typed returns the equivalent atomicTyped value
+func (s AtomicString[T]) typed() atomicTyped[T, atomicString[T]] {
+	return atomicTyped[T, atomicString[T]](s)
+}
+
+// CountChecks returns the number of check functions this setter has
+func (s AtomicString[T]) CountChecks() int {
+	return s.typed().CountChecks()
+}
+
+// SetWithVal (called when a value follows the parameter) applies any
+// Editor to the value and then behaves as for the wrapped setter but
+// stores the value atomically
+func (s AtomicString[T]) SetWithVal(paramName, paramVal string) error {
+	return s.typed().SetWithVal(paramName, paramVal)
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s AtomicString[T]) AllowedValues() string {
+	return s.typed().AllowedValues()
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s AtomicString[T]) CurrentValue() string {
+	return s.typed().CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or if it has nil Checks.
+func (s AtomicString[T]) CheckSetter(name string) {
+	s.typed().CheckSetter(name)
+}
+
+// ValDescribe returns a name describing the values allowed
+func (s AtomicString[T]) ValDescribe() string {
+	return s.typed().ValDescribe()
+}
+
+// SavedValue returns the current value. This and RestoreValue allow the
+// parameter to be Reloadable.
+func (s AtomicString[T]) SavedValue() any {
+	return s.typed().SavedValue()
+}
+
+// RestoreValue stores the value returned by SavedValue
+func (s AtomicString[T]) RestoreValue(v any) {
+	s.typed().RestoreValue(v)
+}
+
+// atomicDuration makes the Duration setter wrapped by an AtomicDuration
+type atomicDuration struct{}
+
+func (atomicDuration) wrap(v *time.Duration, checks []check.Duration,
+) param.Setter {
+	return Duration{Value: v, Checks: checks}
+}
+
+func (atomicDuration) name() string {
+	return "psetter.AtomicDuration"
+}
+
+// AtomicDuration allows you to give a parameter that can be used to set a
+// time.Duration value which can be safely read while the parameter is being
+// set. See Duration and Atomic.
+//
+// You must set the Value, the program will panic if not. The Checks, if
+// any, are applied to the new Duration and the Value will only be updated
+// if they all return a nil error. The Editor, if present, is applied to the
+// parameter value before it is interpreted.
+type AtomicDuration struct {
+	ValueReqMandatory
+
+	// You must set a Value, the program will panic if not. This holds the
+	// value that the setter is setting.
+	Value *AtomicVal[time.Duration]
+	// The Checks, if any, are applied to the new value and the Value will
+	// only be updated if they all return a nil error.
+	Checks []check.ValCk[time.Duration]
+	// The Editor, if present, is applied to the parameter value before it
+	// is interpreted and allows the programmer to modify the value supplied
+	// before using it to set the Value.
+	Editor Editor
+}
+
+// typed returns the equivalent atomicTyped value
+func (s AtomicDuration) typed() atomicTyped[time.Duration, atomicDuration] {
+	return atomicTyped[time.Duration, atomicDuration](s)
+}
+
+// CountChecks returns the number of check functions this setter has
+func (s AtomicDuration) CountChecks() int {
+	return s.typed().CountChecks()
+}
+
+// SetWithVal (called when a value follows the parameter) applies any
+// Editor to the value and then behaves as for the wrapped setter but
+// stores the value atomically
+func (s AtomicDuration) SetWithVal(paramName, paramVal string) error {
+	return s.typed().SetWithVal(paramName, paramVal)
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s AtomicDuration) AllowedValues() string {
+	return s.typed().AllowedValues()
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s AtomicDuration) CurrentValue() string {
+	return s.typed().CurrentValue()
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil or if it has nil Checks.
+func (s AtomicDuration) CheckSetter(name string) {
+	s.typed().CheckSetter(name)
+}
+
+// ValDescribe returns a name describing the values allowed
+func (s AtomicDuration) ValDescribe() string {
+	return s.typed().ValDescribe()
+}
+
+// SavedValue returns the current value. This and RestoreValue allow the
+// parameter to be Reloadable.
+func (s AtomicDuration) SavedValue() any {
+	return s.typed().SavedValue()
+}
+
+// RestoreValue stores the value returned by SavedValue
+func (s AtomicDuration) RestoreValue(v any) {
+	s.typed().RestoreValue(v)
+}
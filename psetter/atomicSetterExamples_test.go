@@ -0,0 +1,68 @@
+package psetter_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// ExampleAtomicInt_basic demonstrates the use of an AtomicInt setter.
+func ExampleAtomicInt_basic() {
+	ps := paramset.NewNoHelpNoExitNoErrRpt() // use paramset.New()
+
+	v := psetter.NewAtomicVal[int64](1)
+
+	ps.Add("my-int", psetter.AtomicInt[int64]{Value: v}, "help text",
+		param.Attrs(param.Reloadable))
+
+	fmt.Printf("Before parsing    v: %d\n", v.Load())
+	ps.Parse([]string{"-my-int", "42"})
+	fmt.Printf("After  parsing    v: %d\n", v.Load())
+	// Output:
+	// Before parsing    v: 1
+	// After  parsing    v: 42
+}
+
+// ExampleAtomicDuration_basic demonstrates the use of an AtomicDuration
+// setter.
+func ExampleAtomicDuration_basic() {
+	ps := paramset.NewNoHelpNoExitNoErrRpt() // use paramset.New()
+
+	v := &psetter.AtomicVal[time.Duration]{}
+
+	ps.Add("how-long", psetter.AtomicDuration{Value: v}, "help text")
+
+	fmt.Printf("Before parsing    v: %v\n", v.Load())
+	ps.Parse([]string{"-how-long", "1h"})
+	fmt.Printf("After  parsing    v: %v\n", v.Load())
+	// Output:
+	// Before parsing    v: 0s
+	// After  parsing    v: 1h0m0s
+}
+
+// ExampleAtomic_basic demonstrates the use of an Atomic setter wrapping an
+// existing setter.
+func ExampleAtomic_basic() {
+	ps := paramset.NewNoHelpNoExitNoErrRpt() // use paramset.New()
+
+	v := psetter.NewAtomicVal([]string{"a"})
+
+	ps.Add("my-list",
+		psetter.Atomic[[]string]{
+			Value: v,
+			Setter: func(v *[]string) param.Setter {
+				return psetter.StrList[string]{Value: v}
+			},
+		},
+		"help text")
+
+	fmt.Printf("Before parsing    v: %v\n", v.Load())
+	ps.Parse([]string{"-my-list", "x,y"})
+	fmt.Printf("After  parsing    v: %v\n", v.Load())
+	// Output:
+	// Before parsing    v: [a]
+	// After  parsing    v: [x y]
+}
@@ -0,0 +1,174 @@
+package psetter_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestAtomicSetter(t *testing.T) {
+	const paramName = "atomic-test"
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		val    string
+		expVal int64
+		expCV  string
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			val:    "5",
+			expVal: 5,
+			expCV:  "5",
+		},
+		{
+			ID:     testhelper.MkID("bad"),
+			val:    "x",
+			expVal: 1,
+			expCV:  "1",
+			ExpErr: testhelper.MkExpErr(`could not interpret "x"`),
+		},
+		{
+			ID:     testhelper.MkID("fails check"),
+			val:    "20",
+			expVal: 1,
+			expCV:  "1",
+			ExpErr: testhelper.MkExpErr("the value (20) must be less than 10"),
+		},
+	}
+
+	for _, tc := range testCases {
+		v := psetter.NewAtomicVal[int64](1)
+		s := psetter.AtomicInt[int64]{
+			Value:  v,
+			Checks: []check.ValCk[int64]{check.ValLT[int64](10)},
+		}
+
+		err := s.SetWithVal(paramName, tc.val)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffInt(t, tc.IDStr(), "value", v.Load(), tc.expVal)
+		testhelper.DiffString(t, tc.IDStr(), "current value",
+			s.CurrentValue(), tc.expCV)
+	}
+}
+
+func TestAtomicSetterConcurrent(t *testing.T) {
+	v := psetter.NewAtomicVal("start")
+	s := psetter.AtomicString[string]{Value: v}
+
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		for range 100 {
+			if err := s.SetWithVal("p", "new value"); err != nil {
+				t.Error("unexpected error:", err)
+			}
+		}
+	})
+	wg.Go(func() {
+		for range 100 {
+			if val := v.Load(); val != "start" && val != "new value" {
+				t.Error("unexpected value:", val)
+			}
+		}
+	})
+	wg.Wait()
+}
+
+func TestAtomicCheckSetter(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpPanic
+		s param.Setter
+	}{
+		{
+			ID: testhelper.MkID("good"),
+			s: psetter.Atomic[int64]{
+				Value: &psetter.AtomicVal[int64]{},
+				Setter: func(v *int64) param.Setter {
+					return psetter.Int[int64]{Value: v}
+				},
+			},
+		},
+		{
+			ID: testhelper.MkID("nil Value"),
+			s: psetter.Atomic[int64]{
+				Setter: func(v *int64) param.Setter {
+					return psetter.Int[int64]{Value: v}
+				},
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				"test: psetter.Atomic[int64] Check failed:" +
+					" the Value to be set is nil"),
+		},
+		{
+			ID: testhelper.MkID("nil Setter"),
+			s: psetter.Atomic[int64]{
+				Value: &psetter.AtomicVal[int64]{},
+			},
+			ExpPanic: testhelper.MkExpPanic(
+				"test: psetter.Atomic[int64] Check failed:",
+				"the Setter func is nil"),
+		},
+		{
+			ID: testhelper.MkID("nil AtomicInt Value"),
+			s:  psetter.AtomicInt[int64]{},
+			ExpPanic: testhelper.MkExpPanic(
+				"test: psetter.AtomicInt[int64] Check failed:" +
+					" the Value to be set is nil"),
+		},
+	}
+
+	for _, tc := range testCases {
+		panicked, panicVal := testhelper.PanicSafe(func() {
+			tc.s.CheckSetter("test")
+		})
+		testhelper.CheckExpPanic(t, panicked, panicVal, tc)
+	}
+}
+
+func TestAtomicForwarding(t *testing.T) {
+	b := psetter.NewAtomicVal(true)
+	bs := psetter.Atomic[bool]{
+		Value: b,
+		Setter: func(v *bool) param.Setter {
+			return psetter.Bool{Value: v}
+		},
+	}
+	is := psetter.Atomic[int64]{
+		Value: &psetter.AtomicVal[int64]{},
+		Setter: func(v *int64) param.Setter {
+			return psetter.Int[int64]{Value: v}
+		},
+	}
+
+	testhelper.DiffBool(t, "Bool", "CanBeNegated", bs.CanBeNegated(), true)
+	testhelper.DiffBool(t, "Int", "CanBeNegated", is.CanBeNegated(), false)
+
+	if err := bs.SetNegated("p"); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	testhelper.DiffBool(t, "Bool", "value", b.Load(), false)
+
+	err := is.SetNegated("p")
+	testhelper.DiffErr(t, "Int", "SetNegated", err,
+		errors.New("the wrapped setter (psetter.Int[int64]) cannot be negated"))
+
+	spec := bs.CompletionHints()
+	testhelper.DiffInt(t, "Bool", "CompletionHints: Kind", spec.Kind,
+		ptypes.CompleteWords)
+	testhelper.DiffStringSlice(t, "Bool", "CompletionHints: Words",
+		spec.Words, []string{"true", "false"})
+	testhelper.DiffStringSlice(t, "Bool", "CompleteValue",
+		bs.CompleteValue("t"), []string{"true"})
+	testhelper.DiffStringSlice(t, "Int", "CompleteValue",
+		is.CompleteValue(""), nil)
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
 )
 
 // Bool is used to set boolean flags
@@ -46,6 +49,15 @@ func (s Bool) Set(_ string) error {
 	return nil
 }
 
+// SetNegated sets the parameter value to false, or to true if the setter is
+// Inverted. This is called when the parameter is negatable (see
+// param.Negatable) and is given with the negation prefix.
+func (s Bool) SetNegated(_ string) error {
+	*s.Value = s.Invert
+
+	return nil
+}
+
 // SetWithVal should be called when a value is given for the parameter
 func (s Bool) SetWithVal(_, val string) error {
 	b, err := strconv.ParseBool(val)
@@ -82,6 +94,29 @@ func (s Bool) CurrentValue() string {
 	return fmt.Sprintf("%v", *s.Value)
 }
 
+// CompletionHints returns the values "true" and "false" as the completion
+// words. This satisfies the ptypes.Completer interface.
+func (s Bool) CompletionHints() ptypes.CompletionSpec {
+	return ptypes.CompletionSpec{
+		Kind:  ptypes.CompleteWords,
+		Words: []string{"true", "false"},
+	}
+}
+
+// CompleteValue returns the values of "true" and "false" which start with
+// the prefix. This satisfies the ptypes.ValueCompleter interface.
+func (s Bool) CompleteValue(prefix string) []string {
+	var vals []string
+
+	for _, v := range []string{"false", "true"} {
+		if strings.HasPrefix(v, prefix) {
+			vals = append(vals, v)
+		}
+	}
+
+	return vals
+}
+
 // CheckSetter panics if the setter has not been properly created - if the
 // Value is nil
 func (s Bool) CheckSetter(name string) {
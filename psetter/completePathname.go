@@ -0,0 +1,53 @@
+package psetter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// completePathname returns the pathnames which start with the prefix. Any
+// directories are given with a trailing separator so that they can be
+// completed further. Hidden files are only returned if the last part of the
+// prefix starts with a '.'. If the kind is CompleteDirs only directories are
+// returned.
+func completePathname(prefix string, kind ptypes.CompletionKind) []string {
+	dir, base := filepath.Split(prefix)
+
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), base) {
+			continue
+		}
+
+		if strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		if kind == ptypes.CompleteDirs && !e.IsDir() {
+			continue
+		}
+
+		name := dir + e.Name()
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
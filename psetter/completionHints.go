@@ -0,0 +1,86 @@
+package psetter
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/ptypes"
+)
+
+// allowedValsCompletionSpec returns a CompletionSpec offering the allowed
+// values and any aliases as the completion words, in sorted order. The
+// allowed values are described by their help text and the aliases by the
+// values they stand for.
+func allowedValsCompletionSpec(
+	av ptypes.AllowedVals[string], aliases ptypes.Aliases[string],
+) ptypes.CompletionSpec {
+	spec := ptypes.CompletionSpec{
+		Kind:      ptypes.CompleteWords,
+		WordDescs: make(map[string]string, len(av)+len(aliases)),
+	}
+
+	for k, desc := range av {
+		spec.Words = append(spec.Words, k)
+		spec.WordDescs[k] = desc
+	}
+
+	for k, vals := range aliases {
+		spec.Words = append(spec.Words, k)
+		spec.WordDescs[k] = "an alias for: " + strings.Join(vals, ", ")
+	}
+
+	slices.Sort(spec.Words)
+
+	return spec
+}
+
+// pathnameFileInfo is an in-memory fs.FileInfo describing a filesystem
+// object of the given mode. It is used to find which kinds of object the
+// checks in an Expectation will accept without looking at the filesystem.
+type pathnameFileInfo struct {
+	mode fs.FileMode
+}
+
+func (fi pathnameFileInfo) Name() string       { return "pathname" }
+func (fi pathnameFileInfo) Size() int64        { return 1 }
+func (fi pathnameFileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi pathnameFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi pathnameFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi pathnameFileInfo) Sys() any           { return nil }
+
+// pathnameChecksPass returns true if all the checks accept a filesystem
+// object of the given mode
+func pathnameChecksPass(checks []check.FileInfo, mode fs.FileMode) bool {
+	fi := pathnameFileInfo{mode: mode}
+
+	for _, c := range checks {
+		if c(fi) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathnameCompletionKind returns the kind of completion to be offered for a
+// pathname having the given expectations. If the pathname must exist and
+// the checks will accept a directory but not a regular file then only
+// directories are offered. The result depends only on the expectations, not
+// on the contents of the filesystem.
+func pathnameCompletionKind(expectation filecheck.Provisos,
+) ptypes.CompletionKind {
+	if expectation.Existence == filecheck.MustNotExist {
+		return ptypes.CompleteFiles
+	}
+
+	if pathnameChecksPass(expectation.Checks, fs.ModeDir) &&
+		!pathnameChecksPass(expectation.Checks, 0) {
+		return ptypes.CompleteDirs
+	}
+
+	return ptypes.CompleteFiles
+}
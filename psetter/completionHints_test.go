@@ -0,0 +1,158 @@
+package psetter_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/param.mod/v7/psetter"
+	"github.com/nickwells/param.mod/v7/ptypes"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestCompletionHints(t *testing.T) {
+	avals := ptypes.AllowedVals[string]{
+		"red":   "the colour red",
+		"green": "the colour green",
+	}
+	aliases := ptypes.Aliases[string]{
+		"grass": []string{"green"},
+	}
+	colourSpec := ptypes.CompletionSpec{
+		Kind:  ptypes.CompleteWords,
+		Words: []string{"grass", "green", "red"},
+		WordDescs: map[string]string{
+			"grass": "an alias for: green",
+			"green": "the colour green",
+			"red":   "the colour red",
+		},
+	}
+	colourListSpec := colourSpec
+	colourListSpec.ListSep = ","
+
+	var (
+		b        bool
+		colour   = "red"
+		colours  []string
+		colourOK = map[string]bool{}
+		pathname string
+		re       *regexp.Regexp
+		loc      *time.Location
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		c       ptypes.Completer
+		expSpec ptypes.CompletionSpec
+	}{
+		{
+			ID: testhelper.MkID("Bool"),
+			c:  psetter.Bool{Value: &b},
+			expSpec: ptypes.CompletionSpec{
+				Kind:  ptypes.CompleteWords,
+				Words: []string{"true", "false"},
+			},
+		},
+		{
+			ID: testhelper.MkID("Enum"),
+			c: psetter.Enum[string]{
+				Value:       &colour,
+				AllowedVals: avals,
+				Aliases:     aliases,
+			},
+			expSpec: colourSpec,
+		},
+		{
+			ID: testhelper.MkID("EnumList"),
+			c: psetter.EnumList[string]{
+				Value:       &colours,
+				AllowedVals: avals,
+				Aliases:     aliases,
+			},
+			expSpec: colourListSpec,
+		},
+		{
+			ID: testhelper.MkID("EnumMap"),
+			c: psetter.EnumMap[string]{
+				Value:       &colourOK,
+				AllowedVals: avals,
+				Aliases:     aliases,
+			},
+			expSpec: colourListSpec,
+		},
+		{
+			ID:      testhelper.MkID("Pathname"),
+			c:       psetter.Pathname{Value: &pathname},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteFiles},
+		},
+		{
+			ID: testhelper.MkID("Pathname - must be a file"),
+			c: psetter.Pathname{
+				Value:       &pathname,
+				Expectation: filecheck.FileExists(),
+			},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteFiles},
+		},
+		{
+			ID: testhelper.MkID("Pathname - must be a dir"),
+			c: psetter.Pathname{
+				Value:       &pathname,
+				Expectation: filecheck.DirExists(),
+			},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteDirs},
+		},
+		{
+			ID: testhelper.MkID("Pathname - must be a non-empty file"),
+			c: psetter.Pathname{
+				Value:       &pathname,
+				Expectation: filecheck.FileNonEmpty(),
+			},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteFiles},
+		},
+		{
+			ID: testhelper.MkID("Pathname - must be new"),
+			c: psetter.Pathname{
+				Value:       &pathname,
+				Expectation: filecheck.IsNew(),
+			},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteFiles},
+		},
+		{
+			ID:      testhelper.MkID("Regexp"),
+			c:       psetter.Regexp{Value: &re},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteNothing},
+		},
+		{
+			ID:      testhelper.MkID("TimeLocation - no Locations"),
+			c:       psetter.TimeLocation{Value: &loc},
+			expSpec: ptypes.CompletionSpec{Kind: ptypes.CompleteNothing},
+		},
+		{
+			ID: testhelper.MkID("TimeLocation - with Locations"),
+			c: psetter.TimeLocation{
+				Value:     &loc,
+				Locations: []string{"Europe/London", "UTC"},
+			},
+			expSpec: ptypes.CompletionSpec{
+				Kind:  ptypes.CompleteWords,
+				Words: []string{"Europe/London", "UTC"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		spec := tc.c.CompletionHints()
+		testhelper.DiffInt(t, tc.IDStr(), "Kind", spec.Kind, tc.expSpec.Kind)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "Words",
+			spec.Words, tc.expSpec.Words)
+		testhelper.DiffString(t, tc.IDStr(), "ListSep",
+			spec.ListSep, tc.expSpec.ListSep)
+
+		err := testhelper.DiffVals(spec.WordDescs, tc.expSpec.WordDescs)
+		if err != nil {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: WordDescs differ: %s", err)
+		}
+	}
+}
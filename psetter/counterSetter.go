@@ -0,0 +1,47 @@
+package psetter
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Counter allows you to count the number of times a parameter is given. It
+// is typically used for a parameter such as 'verbose' where repeating the
+// parameter increases the level of detail. Each time the parameter is seen
+// the Value is incremented by one. It is particularly useful with short
+// parameter names (see param.ShortName) where the parameter can be repeated
+// in a bundle of short names such as "-vvv".
+type Counter[T constraints.Integer] struct {
+	ValueReqNone
+
+	// You must set a Value, the program will panic if not. This is a pointer
+	// to the integer value that the setter is incrementing.
+	Value *T
+}
+
+// Set increments the Value
+func (s Counter[T]) Set(_ string) error {
+	*s.Value++
+
+	return nil
+}
+
+// AllowedValues returns a string describing the allowed values
+func (s Counter[T]) AllowedValues() string {
+	return "none. The value is increased by one" +
+		" each time the parameter is given"
+}
+
+// CurrentValue returns the current setting of the parameter value
+func (s Counter[T]) CurrentValue() string {
+	return fmt.Sprintf("%v", *s.Value)
+}
+
+// CheckSetter panics if the setter has not been properly created - if the
+// Value is nil.
+func (s Counter[T]) CheckSetter(name string) {
+	if s.Value == nil {
+		panic(NilValueMessage(name, fmt.Sprintf("%T", s)))
+	}
+}
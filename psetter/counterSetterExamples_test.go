@@ -0,0 +1,38 @@
+package psetter_test
+
+import (
+	"fmt"
+
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/param.mod/v7/paramset"
+	"github.com/nickwells/param.mod/v7/psetter"
+)
+
+// ExampleCounter_standard demonstrates the use of a Counter setter. Note
+// that the parameter is given a short name and short names are allowed for
+// the parameter set so that the parameter can be repeated in a bundle of
+// short names.
+func ExampleCounter_standard() {
+	ps := paramset.NewNoHelpNoExitNoErrRpt(
+		param.SetShortNamesAllowed) // use paramset.New()
+
+	var verbosity int
+
+	ps.Add("verbose",
+		psetter.Counter[int]{
+			Value: &verbosity,
+		}, "help text",
+		param.ShortName('v'),
+	)
+
+	fmt.Println("Before parsing")
+	fmt.Printf("\tverbosity = %d\n", verbosity)
+	ps.Parse([]string{"-vvv", "-verbose"})
+	fmt.Println("After  parsing")
+	fmt.Printf("\tverbosity = %d\n", verbosity)
+	// Output:
+	// Before parsing
+	//	verbosity = 0
+	// After  parsing
+	//	verbosity = 4
+}
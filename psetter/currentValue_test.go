@@ -15,6 +15,9 @@ func TestCurrentValue(t *testing.T) {
 	setterBool := psetter.Bool{Value: &vBool}
 	setterBoolInverted := psetter.Bool{Value: &vBool, Invert: true}
 
+	vCounter := 3
+	setterCounter := psetter.Counter[int]{Value: &vCounter}
+
 	vDuration := 1 * time.Millisecond
 	setterDuration := psetter.Duration{Value: &vDuration}
 
@@ -109,6 +112,11 @@ func TestCurrentValue(t *testing.T) {
 			s:             setterInt64List,
 			expectedValue: "1,2",
 		},
+		{
+			ID:            testhelper.MkID("Counter - 3"),
+			s:             setterCounter,
+			expectedValue: "3",
+		},
 		{
 			ID:            testhelper.MkID("Int64 - 42"),
 			s:             setterInt64,
@@ -98,6 +98,16 @@ func (s EnumList[T]) AllowedValues() string {
 		HasChecks(s) + "."
 }
 
+// CompletionHints returns the allowed values and aliases as the
+// completion words. This satisfies the ptypes.Completer interface.
+func (s EnumList[T]) CompletionHints() ptypes.CompletionSpec {
+	spec := allowedValsCompletionSpec(
+		s.AllowedValuesMap(), s.AllowedValuesAliasMap())
+	spec.ListSep = s.GetSeparator()
+
+	return spec
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s EnumList[T]) CurrentValue() string {
 	var str strings.Builder
@@ -140,6 +140,16 @@ func (s EnumMap[T]) AllowedValues() string {
 		" with '=false'; by default the value will be set to true."
 }
 
+// CompletionHints returns the allowed values and aliases as the
+// completion words. This satisfies the ptypes.Completer interface.
+func (s EnumMap[T]) CompletionHints() ptypes.CompletionSpec {
+	spec := allowedValsCompletionSpec(
+		s.AllowedValuesMap(), s.AllowedValuesAliasMap())
+	spec.ListSep = s.GetSeparator()
+
+	return spec
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s EnumMap[T]) CurrentValue() string {
 	var cv strings.Builder
@@ -71,6 +71,13 @@ func (s Enum[T]) AllowedValues() string {
 	return "a string"
 }
 
+// CompletionHints returns the allowed values and aliases as the
+// completion words. This satisfies the ptypes.Completer interface.
+func (s Enum[T]) CompletionHints() ptypes.CompletionSpec {
+	return allowedValsCompletionSpec(
+		s.AllowedValuesMap(), s.AllowedValuesAliasMap())
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s Enum[T]) CurrentValue() string {
 	return fmt.Sprintf("%v", *s.Value)
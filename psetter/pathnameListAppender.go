@@ -8,6 +8,7 @@ import (
 	"github.com/nickwells/check.mod/v2/check"
 	"github.com/nickwells/filecheck.mod/filecheck"
 	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/param.mod/v7/ptypes"
 )
 
 // PathnameListAppender allows you to specify a parameter that can be used to
@@ -116,6 +117,20 @@ func (s PathnameListAppender) CurrentValue() string {
 	return cv.String()
 }
 
+// CompletionHints returns a CompletionSpec for pathnames. Only directories
+// are offered if the Expectation can only be satisfied by a directory. This
+// satisfies the ptypes.Completer interface.
+func (s PathnameListAppender) CompletionHints() ptypes.CompletionSpec {
+	return ptypes.CompletionSpec{Kind: pathnameCompletionKind(s.Expectation)}
+}
+
+// CompleteValue returns the pathnames which start with the prefix. Only
+// directories are returned if the CompletionHints say so. This satisfies
+// the ptypes.ValueCompleter interface.
+func (s PathnameListAppender) CompleteValue(prefix string) []string {
+	return completePathname(prefix, s.CompletionHints().Kind)
+}
+
 // CheckSetter panics if the setter has not been properly created - if the
 // Value is nil or if it has nil Checks.
 func (s PathnameListAppender) CheckSetter(name string) {
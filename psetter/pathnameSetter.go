@@ -11,6 +11,7 @@ import (
 	"github.com/nickwells/check.mod/v2/check"
 	"github.com/nickwells/filecheck.mod/filecheck"
 	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/param.mod/v7/ptypes"
 	"github.com/nickwells/strdist.mod/v2/strdist"
 )
 
@@ -163,6 +164,20 @@ func (s Pathname) CurrentValue() string {
 	return fmt.Sprintf("%v", *s.Value)
 }
 
+// CompletionHints returns a CompletionSpec for pathnames. Only directories
+// are offered if the Expectation can only be satisfied by a directory. This
+// satisfies the ptypes.Completer interface.
+func (s Pathname) CompletionHints() ptypes.CompletionSpec {
+	return ptypes.CompletionSpec{Kind: pathnameCompletionKind(s.Expectation)}
+}
+
+// CompleteValue returns the pathnames which start with the prefix. Only
+// directories are returned if the CompletionHints say so. This satisfies
+// the ptypes.ValueCompleter interface.
+func (s Pathname) CompleteValue(prefix string) []string {
+	return completePathname(prefix, s.CompletionHints().Kind)
+}
+
 // CheckSetter panics if the setter has not been properly created - if the
 // Value is nil or if it has nil Checks.
 func (s Pathname) CheckSetter(name string) {
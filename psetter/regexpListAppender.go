@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
 )
 
 // RegexpListAppender allows you to specify a parameter that can be used to add
@@ -48,6 +50,12 @@ func (s RegexpListAppender) AllowedValues() string {
 		" by the standard regexp package"
 }
 
+// CompletionHints returns a CompletionSpec showing that no completions can
+// be offered. This satisfies the ptypes.Completer interface.
+func (s RegexpListAppender) CompletionHints() ptypes.CompletionSpec {
+	return ptypes.CompletionSpec{Kind: ptypes.CompleteNothing}
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s RegexpListAppender) CurrentValue() string {
 	var cv strings.Builder
@@ -3,6 +3,8 @@ package psetter
 import (
 	"fmt"
 	"regexp"
+
+	"github.com/nickwells/param.mod/v7/ptypes"
 )
 
 // Regexp allows you to give a parameter that can be used to set an
@@ -37,6 +39,12 @@ func (s Regexp) AllowedValues() string {
 		" by the standard regexp package"
 }
 
+// CompletionHints returns a CompletionSpec showing that no completions can
+// be offered. This satisfies the ptypes.Completer interface.
+func (s Regexp) CompletionHints() ptypes.CompletionSpec {
+	return ptypes.CompletionSpec{Kind: ptypes.CompleteNothing}
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s Regexp) CurrentValue() string {
 	if s.Value == nil {
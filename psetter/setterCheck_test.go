@@ -19,6 +19,8 @@ func TestCheck(t *testing.T) {
 
 	var b bool
 
+	var count int
+
 	var dur time.Duration
 
 	var emptyStrList []string
@@ -93,6 +95,16 @@ func TestCheck(t *testing.T) {
 			ExpPanic: testhelper.MkExpPanic("test: psetter.Bool " +
 				nilValueMsg),
 		},
+		{
+			ID: testhelper.MkID("Counter - ok"),
+			s:  &psetter.Counter[int]{Value: &count},
+		},
+		{
+			ID: testhelper.MkID("Counter - bad"),
+			s:  &psetter.Counter[int]{},
+			ExpPanic: testhelper.MkExpPanic("test: psetter.Counter[int] " +
+				nilValueMsg),
+		},
 		{
 			ID: testhelper.MkID("Duration - ok"),
 			s:  &psetter.Duration{Value: &dur},
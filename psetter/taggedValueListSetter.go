@@ -195,6 +195,17 @@ func (s TaggedValueList[E, T]) AllowedValues() string {
 		HasChecks(s) + "."
 }
 
+// CompletionHints returns the allowed values and aliases as the
+// completion words. The tags are not offered. This satisfies the
+// ptypes.Completer interface.
+func (s TaggedValueList[E, T]) CompletionHints() ptypes.CompletionSpec {
+	spec := allowedValsCompletionSpec(
+		s.AllowedValuesMap(), s.AllowedValuesAliasMap())
+	spec.ListSep = s.GetSeparator()
+
+	return spec
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s TaggedValueList[E, T]) CurrentValue() string {
 	var str strings.Builder
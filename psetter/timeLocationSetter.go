@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/param.mod/v7/ptypes"
 	"github.com/nickwells/strdist.mod/v2/strdist"
 )
 
@@ -136,6 +137,19 @@ func (s TimeLocation) AllowedValues() string {
 		" names are also allowed such as UTC or CET."
 }
 
+// CompletionHints returns the Locations, if any, as the completion
+// words. This satisfies the ptypes.Completer interface.
+func (s TimeLocation) CompletionHints() ptypes.CompletionSpec {
+	if len(s.Locations) == 0 {
+		return ptypes.CompletionSpec{Kind: ptypes.CompleteNothing}
+	}
+
+	return ptypes.CompletionSpec{
+		Kind:  ptypes.CompleteWords,
+		Words: s.Locations,
+	}
+}
+
 // CurrentValue returns the current setting of the parameter value
 func (s TimeLocation) CurrentValue() string {
 	return (*s.Value).String()
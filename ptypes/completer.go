@@ -0,0 +1,91 @@
+package ptypes
+
+import (
+	"slices"
+	"strings"
+)
+
+// CompletionKind describes the kind of value that a parameter can take for
+// the purposes of command line completion
+type CompletionKind int
+
+const (
+	// CompleteNothing means that no completions can be offered. This should
+	// be used for values, such as regular expressions, where offering
+	// filenames (the usual default) would be unhelpful.
+	CompleteNothing CompletionKind = iota
+	// CompleteWords means that the value should be one of the Words
+	CompleteWords
+	// CompleteFiles means that the value is a pathname. If a Glob is given
+	// only files matching the pattern are offered (as well as directories)
+	CompleteFiles
+	// CompleteDirs means that the value is the name of a directory
+	CompleteDirs
+	// CompleteHostnames means that the value is the name of a host
+	CompleteHostnames
+)
+
+// CompletionSpec describes the values that can be offered as completions
+// for a parameter
+type CompletionSpec struct {
+	Kind CompletionKind
+	// Words holds the values for a Kind of CompleteWords
+	Words []string
+	// WordDescs holds optional descriptions of the Words. Not every shell
+	// is able to display them.
+	WordDescs map[string]string
+	// ListSep, if not empty, is the separator between values where the
+	// parameter takes a list of Words
+	ListSep string
+	// Glob holds an optional pattern that files must match for a Kind of
+	// CompleteFiles
+	Glob string
+}
+
+// Candidates returns the sorted Words which start with the prefix; it
+// returns nil unless the Kind is CompleteWords. If the spec has a list
+// separator only the part of the prefix after the last separator is
+// completed and the preceding part is kept on each of the values.
+func (cs CompletionSpec) Candidates(prefix string) []string {
+	if cs.Kind != CompleteWords {
+		return nil
+	}
+
+	var done string
+
+	if cs.ListSep != "" {
+		if i := strings.LastIndex(prefix, cs.ListSep); i >= 0 {
+			i += len(cs.ListSep)
+			done, prefix = prefix[:i], prefix[i:]
+		}
+	}
+
+	var vals []string
+
+	for _, w := range cs.Words {
+		if strings.HasPrefix(w, prefix) {
+			vals = append(vals, done+w)
+		}
+	}
+
+	slices.Sort(vals)
+
+	return vals
+}
+
+// Completer is the interface to be satisfied by a type (typically a Setter)
+// that can describe the values that may be offered when completing its
+// parameter on the command line. Completion generators will use this in
+// preference to any allowed values that the type has.
+//
+// There is no CompletionKind for values found by a callback. A type whose
+// values can only be found when the command line is being completed should
+// satisfy the ValueCompleter interface instead, and return a Kind of
+// CompleteWords with no Words if it also satisfies this interface. Its
+// CompleteValue method is called each time a value is completed if the
+// program is asked for the completions when completing (the phelp
+// "completions-dynamic" parameter). Completion scripts generated in
+// advance can only offer the values it returns when they are generated.
+type Completer interface {
+	CompletionHints() CompletionSpec
+}
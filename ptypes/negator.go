@@ -0,0 +1,19 @@
+package ptypes
+
+// Negator is the interface to be satisfied by a type (typically a Setter)
+// that can be negated. A parameter whose Setter satisfies this interface
+// can be made negatable (see param.Negatable) and then, if the parameter is
+// given with the negation prefix ("no-"), the SetNegated method will be
+// called rather than the Set method.
+type Negator interface {
+	SetNegated(name string) error
+}
+
+// NegationChecker is the interface to be satisfied by a type (typically a
+// Setter) which satisfies the Negator interface but can only be negated in
+// some cases, for instance because it wraps another Setter which may not
+// be a Negator. If CanBeNegated returns false the parameter cannot be made
+// negatable.
+type NegationChecker interface {
+	CanBeNegated() bool
+}
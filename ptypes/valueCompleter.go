@@ -0,0 +1,11 @@
+package ptypes
+
+// ValueCompleter is the interface to be satisfied by a type (typically a
+// Setter) that can suggest values for a parameter. It is used when a
+// partially typed command line is being completed and, as it is called at
+// that time, the values can be found at runtime, for instance by querying
+// some service. The prefix is the partial value typed so far; only values
+// starting with the prefix need be returned.
+type ValueCompleter interface {
+	CompleteValue(prefix string) []string
+}
@@ -0,0 +1,14 @@
+package ptypes
+
+// ValueSaver is the interface to be satisfied by a type (typically a Setter)
+// that can save the value it sets and later restore it. It is used when a
+// param.Reloadable parameter is reloaded. The SavedValue method should
+// return a copy of the current value and RestoreValue should set the value
+// back to one returned by SavedValue. A Setter whose value cannot be safely
+// set directly through its Value pointer field (such as the atomic setters
+// in the psetter package) should satisfy this interface if the parameter is
+// to be Reloadable.
+type ValueSaver interface {
+	SavedValue() any
+	RestoreValue(v any)
+}